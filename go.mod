@@ -4,6 +4,7 @@ go 1.25.1
 
 require (
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.27.2 // indirect
+	go.etcd.io/bbolt v1.4.3 // indirect
 	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
@@ -0,0 +1,17 @@
+// Package api bundles the generated OpenAPI document and a small explorer
+// page so the HTTP gateway can serve them without files on disk.
+package api
+
+import _ "embed"
+
+// SwaggerJSON is the OpenAPI v2 document generated from data_processor.proto
+//
+//go:embed data_processor.swagger.json
+var SwaggerJSON []byte
+
+// ExplorerHTML is a self-contained page that lists the operations in
+// SwaggerJSON and sends requests to them. It loads nothing from the network
+// other than the swagger document it is served with.
+//
+//go:embed explorer.html
+var ExplorerHTML []byte
\ No newline at end of file
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/etcproc"
+)
+
+func main() {
+	// The service logs every import; only its warnings are of interest here
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	os.Exit(etcproc.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Environ()))
+}
\ No newline at end of file
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// newAuthenticator builds the key store from the config. Health checks and
+// reflection stay reachable without a key, so probes and grpcurl keep working.
+func newAuthenticator(cfg *config.Config) (*auth.Authenticator, error) {
+	store, err := newKeyStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewAuthenticator(store,
+		"/grpc.health.v1.Health/",
+		"/grpc.reflection.",
+		pb.DataProcessorService_HealthCheck_FullMethodName,
+	), nil
+}
+
+// newKeyStore builds the key store from the config
+func newKeyStore(cfg *config.Config) (*auth.Store, error) {
+	keys := make([]auth.Key, len(cfg.Auth.Keys))
+	for i, k := range cfg.Auth.Keys {
+		keys[i] = auth.Key{Name: k.Name, Key: k.Key, KeySHA256: k.KeySHA256, ClientCert: k.ClientCert, Accounts: k.Accounts}
+	}
+	return auth.NewStore(keys)
+}
\ No newline at end of file
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+)
+
+// loadConfig layers the configuration: defaults, the config file,
+// ETC_PROCESSOR_* environment variables and then the flags given on the
+// command line, and validates the result
+func loadConfig(configFile string) (*config.Config, error) {
+	cfg, err := config.Load(configFile, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	applyFlags(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyFlags overrides the config with the flags that were set. A non-zero
+// -http-port or -metrics-port also enables the gateway or metrics.
+func applyFlags(cfg *config.Config) {
+	flag.Visit(func(f *flag.Flag) {
+		switch {
+		case f.Name == "port":
+			cfg.Port = *port
+		case f.Name == "db":
+			cfg.DBServiceAddr = *dbAddr
+		case f.Name == "http-port" && *httpPort != 0:
+			cfg.Gateway.Enabled = true
+			cfg.Gateway.Port = *httpPort
+		case f.Name == "metrics-port" && *metricsPort != 0:
+			cfg.Metrics.Enabled = true
+			cfg.Metrics.Port = *metricsPort
+		}
+	})
+}
\ No newline at end of file
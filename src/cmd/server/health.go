@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/health"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/inbox"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// newHealthMonitor creates the readiness probes: the database when a client
+// is configured, the inbox watcher as the job queue, and every directory the
+// server writes to. The service is looked up when the database is probed,
+// since the monitor is created before it.
+func newHealthMonitor(cfg *config.Config, dbClient handler.DBClient, watcher *inbox.Watcher, service func() *handler.DataProcessorService) *health.Monitor {
+	var deps []health.Dependency
+	if dbClient != nil {
+		deps = append(deps, health.Dependency{
+			Name:  "database",
+			Check: func(ctx context.Context) error { return service().PingDatabase(ctx) },
+		})
+	}
+	if watcher != nil {
+		deps = append(deps, health.Dependency{Name: "job_queue", Check: watcher.Check})
+	}
+
+	if cfg.DeadLetterDir != "" {
+		deps = append(deps, health.Dependency{Name: "storage.dead_letters", Check: health.Writable(cfg.DeadLetterDir)})
+	}
+	if watcher != nil {
+		for _, d := range watcher.Dirs() {
+			deps = append(deps,
+				health.Dependency{Name: "storage.inbox_archive:" + d.Path, Check: health.Writable(d.ArchiveDir)},
+				health.Dependency{Name: "storage.inbox_failed:" + d.Path, Check: health.Writable(d.FailedDir)},
+			)
+		}
+	}
+	if cfg.Gateway.Enabled {
+		dir := cfg.Gateway.UploadDir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		deps = append(deps, health.Dependency{Name: "storage.uploads", Check: health.Writable(dir)})
+	}
+
+	return health.NewMonitor(deps, health.Options{
+		Interval: time.Duration(cfg.Health.IntervalSeconds) * time.Second,
+		Timeout:  time.Duration(cfg.Health.TimeoutSeconds) * time.Second,
+		Services: []string{pb.DataProcessorService_ServiceDesc.ServiceName},
+	})
+}
\ No newline at end of file
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/inbox"
+)
+
+// newInboxWatcher creates the watcher used in ingest mode. Files are imported
+// through ProcessCSVFile, so they get the same sandboxing, validation and
+// duplicate handling as RPC callers. service is called at import time, which
+// lets the watcher be created before the service.
+func newInboxWatcher(cfg *config.Config, service func() *handler.DataProcessorService) (*inbox.Watcher, error) {
+	var dirs []inbox.Dir
+	for _, d := range cfg.Inbox.Dirs {
+		dirs = append(dirs, inbox.Dir{
+			Path:           d.Path,
+			AccountPattern: d.AccountPattern,
+			AccountID:      d.AccountID,
+			ArchiveDir:     d.ArchiveDir,
+			FailedDir:      d.FailedDir,
+		})
+	}
+
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		resp, err := service().ProcessCSVFile(ctx, &pb.ProcessCSVFileRequest{
+			CsvFilePath:    path,
+			AccountId:      accountID,
+			SkipDuplicates: cfg.Inbox.SkipDuplicates,
+		})
+		if err != nil {
+			return inbox.Outcome{}, err
+		}
+		return inbox.Outcome{
+			ImportID: resp.ImportId,
+			Success:  resp.Success,
+			Message:  resp.Message,
+			Stats:    statsMap(resp.Stats),
+			Errors:   resp.Errors,
+		}, nil
+	}
+
+	return inbox.New(dirs, importer, inbox.Options{
+		PollInterval: time.Duration(cfg.Inbox.PollIntervalMs) * time.Millisecond,
+		StableFor:    time.Duration(cfg.Inbox.StableSeconds) * time.Second,
+	})
+}
+
+// statsMap flattens processing stats for the JSON result sidecar
+func statsMap(stats *pb.ProcessingStats) map[string]int {
+	if stats == nil {
+		return nil
+	}
+	return map[string]int{
+		"total_records":     int(stats.TotalRecords),
+		"saved_records":     int(stats.SavedRecords),
+		"skipped_records":   int(stats.SkippedRecords),
+		"error_records":     int(stats.ErrorRecords),
+		"retried_records":   int(stats.RetriedRecords),
+		"corrected_records": int(stats.CorrectedRecords),
+		"status_changes":    int(stats.StatusChanges),
+		"updated_records":   int(stats.UpdatedRecords),
+	}
+}
\ No newline at end of file
@@ -1,115 +1,408 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/certs"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
 	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/inbox"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/localstore"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
 	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
 var (
-	port       = flag.Int("port", 50051, "The server port")
-	dbAddr     = flag.String("db", "", "Database service address")
-	configFile = flag.String("config", "", "Config file path")
+	port        = flag.Int("port", 50051, "The server port")
+	dbAddr      = flag.String("db", "", "Database service address")
+	configFile  = flag.String("config", "", "Config file path")
+	mode        = flag.String("mode", "serve", "Run mode: serve (gRPC server), ingest (watch inbox directories) or all")
+	httpPort    = flag.Int("http-port", 0, "REST gateway port; enables the gateway when set")
+	metricsPort = flag.Int("metrics-port", 0, "Prometheus metrics port; enables /metrics when set")
 )
 
 func main() {
 	flag.Parse()
 
-	// Load configuration
+	// Load configuration: defaults, file, environment, flags
 	cfg, err := loadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Override with command line flags if provided
-	if *port != 50051 {
-		cfg.Port = *port
+	// Structured, leveled logging; log.Printf output goes through it too. The
+	// level can be changed by a reload.
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
 	}
-	if *dbAddr != "" {
-		cfg.DBServiceAddr = *dbAddr
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(level)
+	logger, err := logging.NewLeveled(os.Stderr, logLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
 	}
+	slog.SetDefault(logger)
 
-	// Create listener
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
-	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+	runServer := *mode == "serve" || *mode == "all"
+	runIngest := *mode == "ingest" || *mode == "all"
+	if !runServer && !runIngest {
+		fatal("unknown mode", "mode", *mode)
+	}
+
+	// Tracing: spans for RPCs, HTTP requests and import steps
+	var tracer *tracing.Tracer
+	var traceExporter *tracing.WriterExporter
+	if cfg.Tracing.Enabled {
+		tracer, traceExporter, err = newTracer(cfg)
+		if err != nil {
+			fatal("invalid tracing configuration", "error", err)
+		}
+		slog.Info("tracing enabled", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
 	}
 
-	// Create gRPC server
-	grpcServer := grpc.NewServer()
+	// API key authentication; the service checks each key's accounts
+	var authenticator *auth.Authenticator
+	if cfg.Auth.Enabled {
+		authenticator, err = newAuthenticator(cfg)
+		if err != nil {
+			fatal("invalid auth configuration", "error", err)
+		}
+		slog.Info("API key authentication enabled", "keys", len(cfg.Auth.Keys))
+	} else {
+		slog.Warn("auth is disabled; any caller can act for any account_id")
+	}
 
-	// Create DB client (for now, nil - will be implemented later)
+	// TLS for the gRPC server; changed certificate files are reloaded
+	var serverTLS *certs.Reloader
+	if cfg.TLS.Enabled {
+		serverTLS, err = newTLS(cfg)
+		if err != nil {
+			fatal("invalid tls configuration", "error", err)
+		}
+		slog.Info("TLS enabled", "mutual", cfg.TLS.ClientCAFile != "", "min_version", cfg.TLS.MinVersion)
+	} else {
+		slog.Warn("tls is disabled; gRPC traffic is sent in plaintext")
+	}
+
+	// Create DB client: the embedded local store, or db_service
 	var dbClient handler.DBClient
-	if cfg.DBServiceAddr != "" {
-		// TODO: Initialize actual DB client
-		log.Printf("DB service configured at: %s", cfg.DBServiceAddr)
+	var localStore *localstore.Store
+	if cfg.LocalStore.Enabled {
+		localStore, err = localstore.Open(cfg.LocalStore.Path)
+		if err != nil {
+			fatal("failed to open local store", "error", err)
+		}
+		dbClient = localStore
+		slog.Info("local store enabled", "path", cfg.LocalStore.Path)
+	} else if cfg.DBServiceAddr != "" {
+		// TODO: Initialize actual DB client, dialled with
+		// grpc.WithChainUnaryInterceptor(tracer.UnaryClientInterceptor()) and
+		// implementing handler.ContextSaver, so db_service continues the trace
+		slog.Info("DB service configured", "addr", cfg.DBServiceAddr)
 	}
 
-	// Register service
-	service := handler.NewDataProcessorService(dbClient)
-	pb.RegisterDataProcessorServiceServer(grpcServer, service)
+	// Build retry policy for DB saves
+	retryPolicy, err := handler.NewRetryPolicy(
+		cfg.Retry.MaxAttempts,
+		time.Duration(cfg.Retry.InitialBackoffMs)*time.Millisecond,
+		time.Duration(cfg.Retry.MaxBackoffMs)*time.Millisecond,
+		cfg.Retry.Multiplier,
+		cfg.Retry.RetryableCodes,
+	)
+	if err != nil {
+		fatal("invalid retry configuration", "error", err)
+	}
 
-	// Register reflection service for grpcurl
-	reflection.Register(grpcServer)
+	// Dead-letter store for records that fail conversion or saving; without
+	// dead_letter_dir failed records are only reported, not kept
+	var deadLetters deadletter.Store
+	if cfg.DeadLetterDir != "" {
+		fileStore, err := deadletter.NewFileStore(cfg.DeadLetterDir)
+		if err != nil {
+			fatal("failed to open dead-letter store", "error", err)
+		}
+		deadLetters = fileStore
+		slog.Info("dead-letter store opened", "dir", cfg.DeadLetterDir)
+	}
+
+	// Duplicate-key policies, per account
+	duplicates, err := dedup.NewPolicySet(cfg.Duplicates.Strategy, cfg.Duplicates.Accounts)
+	if err != nil {
+		fatal("invalid duplicates configuration", "error", err)
+	}
+
+	// Re-issued row detection (corrections and status changes)
+	var fuzzy *dedup.FuzzyMatcher
+	if cfg.Duplicates.Fuzzy.Enabled {
+		fuzzy = dedup.NewFuzzyMatcher(time.Duration(cfg.Duplicates.Fuzzy.WindowMinutes) * time.Minute)
+	}
+
+	// Validation rules, per account
+	ruleSets, err := newRules(cfg)
+	if err != nil {
+		fatal("invalid rules file", "error", err)
+	}
+	if ruleSets != nil && cfg.Rules.File != "" {
+		slog.Info("validation rules loaded", "file", cfg.Rules.File)
+	}
 
-	// Start server in goroutine
-	go func() {
-		log.Printf("Starting gRPC server on port %d...", cfg.Port)
-		if err := grpcServer.Serve(lis); err != nil {
-			log.Fatalf("Failed to serve: %v", err)
+	// Inbox watcher for ingest mode; it creates the inbox directories
+	var service *handler.DataProcessorService
+	var watcher *inbox.Watcher
+	if runIngest {
+		watcher, err = newInboxWatcher(cfg, func() *handler.DataProcessorService { return service })
+		if err != nil {
+			fatal("invalid inbox configuration", "error", err)
 		}
-	}()
+	}
 
-	// Wait for interrupt signal to gracefully shutdown the server
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+	// Filesystem sandbox for ProcessCSVFile, on unless explicitly disabled
+	var fileSandbox *sandbox.Sandbox
+	if !cfg.Sandbox.Disabled {
+		roots := cfg.Sandbox.Roots()
+		if len(cfg.Sandbox.AllowedRoots) == 0 {
+			if err := os.MkdirAll(config.DefaultSandboxRoot, 0o755); err != nil {
+				fatal("failed to create sandbox directory", "error", err)
+			}
+		}
+		if watcher != nil {
+			// The inbox directories are always readable by the importer
+			roots = append(append([]string(nil), roots...), watcher.Paths()...)
+		}
+		fileSandbox, err = sandbox.New(roots, cfg.Sandbox.MaxFileSizeBytes, cfg.Sandbox.AllowedExtensions)
+		if err != nil {
+			fatal("invalid sandbox configuration", "error", err)
+		}
+		slog.Info("ProcessCSVFile restricted to sandbox", "roots", fileSandbox.Roots())
+	} else {
+		slog.Warn("sandbox disabled; ProcessCSVFile can read any file the server can")
+	}
 
-	log.Println("Shutting down server...")
-	grpcServer.GracefulStop()
-	log.Println("Server stopped")
-}
+	// Prometheus metrics: row outcomes, save and RPC latency, in-flight imports
+	var serverMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		serverMetrics = metrics.New()
+	}
+
+	// Per-account and global request rates, concurrent imports and daily
+	// record quotas
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimits.Enabled {
+		limiter = newLimiter(cfg)
+		slog.Info("rate limits enabled", "accounts", len(cfg.RateLimits.Accounts))
+	}
 
-func loadConfig(configFile string) (*config.Config, error) {
-	// Default configuration
-	cfg := &config.Config{
-		Port:          50051,
-		DBServiceAddr: "",
-		MaxBatchSize:  100,
-		ValidateData:  true,
+	// Liveness and readiness for grpc.health.v1 and HealthCheck
+	monitor := newHealthMonitor(cfg, dbClient, watcher, func() *handler.DataProcessorService { return service })
+
+	// Create service
+	service = handler.NewDataProcessorServiceWithOptions(dbClient,
+		handler.WithHealthMonitor(monitor),
+		handler.WithMetrics(serverMetrics),
+		handler.WithTracer(tracer),
+		handler.WithLimiter(limiter),
+		handler.WithRetryPolicy(retryPolicy),
+		handler.WithDeadLetterStore(deadLetters),
+		handler.WithDuplicatePolicies(duplicates),
+		handler.WithFuzzyMatcher(fuzzy),
+		handler.WithRules(ruleSets),
+		handler.WithSandbox(fileSandbox),
+		handler.WithBatchLimits(handler.BatchLimits{
+			MaxFiles:       cfg.Batch.MaxFiles,
+			MaxConcurrency: cfg.Batch.Concurrency,
+			SaveBatchSize:  cfg.MaxBatchSize,
+		}),
+		handler.WithArchiveLimits(archive.Limits{
+			MaxEntries:    cfg.Archive.MaxEntries,
+			MaxEntryBytes: cfg.Archive.MaxEntryBytes,
+			MaxTotalBytes: cfg.Archive.MaxTotalBytes,
+		}),
+	)
+
+	// Start ingestion in the background; the first scan picks up files left
+	// over from before a restart
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if watcher != nil {
+		go func() {
+			slog.Info("watching inbox directories", "dirs", watcher.Paths())
+			if err := watcher.Run(ctx); err != nil && err != context.Canceled {
+				slog.Error("inbox watcher stopped", "error", err)
+			}
+		}()
 	}
 
-	// If config file specified, load it
-	if configFile != "" {
-		fileCfg, err := config.LoadFromFile(configFile)
+	// Probe dependencies in the background; readiness stays NOT_SERVING until
+	// the first probe passes
+	go monitor.Run(ctx)
+
+	var grpcServer *grpc.Server
+	if runServer {
+		// Create listener
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Port))
 		if err != nil {
-			return nil, err
+			fatal("failed to listen", "error", err)
+		}
+
+		// Create gRPC server and register service
+		serverOpts := []grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(tracer.UnaryServerInterceptor(), logging.UnaryServerInterceptor(logger), serverMetrics.UnaryServerInterceptor(), authenticator.UnaryServerInterceptor(), limiter.UnaryServerInterceptor(rateLimitExempt...)),
+			grpc.ChainStreamInterceptor(tracer.StreamServerInterceptor(), logging.StreamServerInterceptor(logger), serverMetrics.StreamServerInterceptor(), authenticator.StreamServerInterceptor()),
 		}
-		cfg = fileCfg
+		if serverTLS != nil {
+			serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(serverTLS.ServerConfig())))
+		}
+		grpcServer = grpc.NewServer(serverOpts...)
+		pb.RegisterDataProcessorServiceServer(grpcServer, service)
+		monitor.Register(grpcServer)
+
+		// Register reflection service for grpcurl
+		reflection.Register(grpcServer)
+
+		// Start server in goroutine
+		go func() {
+			slog.Info("starting gRPC server", "port", cfg.Port)
+			if err := grpcServer.Serve(lis); err != nil {
+				fatal("failed to serve", "error", err)
+			}
+		}()
 	}
 
-	// Environment variables override file config
-	if port := os.Getenv("ETC_PROCESSOR_PORT"); port != "" {
-		var p int
-		fmt.Sscanf(port, "%d", &p)
-		if p > 0 {
-			cfg.Port = p
+	// REST gateway on its own port; it has its own context so a proxy
+	// connection stays open until in-flight HTTP requests have finished
+	gatewayCtx, cancelGateway := context.WithCancel(context.Background())
+	defer cancelGateway()
+	var gw *gateway.Gateway
+	if runServer && cfg.Gateway.Enabled {
+		gw, err = newGateway(gatewayCtx, cfg, service, tracer, authenticator, serverTLS, limiter)
+		if err != nil {
+			fatal("invalid gateway configuration", "error", err)
 		}
+
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Gateway.Port))
+		if err != nil {
+			fatal("failed to listen", "error", err)
+		}
+		go func() {
+			slog.Info("starting REST gateway; API explorer at /docs/", "mode", cfg.Gateway.Mode, "port", cfg.Gateway.Port)
+			if err := gw.Serve(lis); err != nil {
+				fatal("failed to serve gateway", "error", err)
+			}
+		}()
 	}
 
-	if dbAddr := os.Getenv("ETC_PROCESSOR_DB_ADDR"); dbAddr != "" {
-		cfg.DBServiceAddr = dbAddr
+	// Metrics endpoint on its own port, scraped by Prometheus
+	var metricsServer *http.Server
+	if serverMetrics != nil {
+		metricsServer = newMetricsServer(cfg, serverMetrics)
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Metrics.Port))
+		if err != nil {
+			fatal("failed to listen", "error", err)
+		}
+		go func() {
+			slog.Info("serving metrics", "port", cfg.Metrics.Port, "path", cfg.Metrics.Path)
+			if err := metricsServer.Serve(lis); err != nil && err != http.ErrServerClosed {
+				fatal("failed to serve metrics", "error", err)
+			}
+		}()
 	}
 
-	return cfg, nil
+	// Reload the runtime settings on SIGHUP; wait for an interrupt signal to
+	// gracefully shutdown the server
+	reloads := &reloader{
+		configFile:    *configFile,
+		running:       cfg,
+		logLevel:      logLevel,
+		authenticator: authenticator,
+		limiter:       limiter,
+		service:       service,
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := <-sigCh; sig == syscall.SIGHUP; sig = <-sigCh {
+		if err := reloads.reload(); err != nil {
+			slog.Error("reload failed; keeping the current settings", "error", err)
+		}
+	}
+
+	slog.Info("shutting down server")
+	monitor.Shutdown()
+	cancel()
+	if gw != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(cfg.Gateway.ShutdownTimeoutSeconds)*time.Second)
+		if err := gw.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("gateway shutdown", "error", err)
+		}
+		cancelShutdown()
+	}
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	cancelGateway()
+	if metricsServer != nil {
+		metricsServer.Close()
+	}
+	if traceExporter != nil {
+		traceExporter.Close()
+	}
+	if localStore != nil {
+		localStore.Close()
+	}
+	slog.Info("server stopped")
+}
+
+// newGateway creates the REST gateway, either calling the service in-process
+// or proxying to the local gRPC port, over TLS when the server uses it.
+// In-process calls are rate limited by the gateway.
+func newGateway(ctx context.Context, cfg *config.Config, service *handler.DataProcessorService, tracer *tracing.Tracer, authenticator *auth.Authenticator, serverTLS *certs.Reloader, limiter *ratelimit.Limiter) (*gateway.Gateway, error) {
+	mode, err := gateway.ParseMode(cfg.Gateway.Mode)
+	if err != nil {
+		return nil, err
+	}
+	var dialOpts []grpc.DialOption
+	if serverTLS != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(serverTLS.LoopbackClientConfig())))
+	}
+	return gateway.New(ctx, gateway.Options{
+		Mode:           mode,
+		Server:         service,
+		Endpoint:       fmt.Sprintf("localhost:%d", cfg.Port),
+		DialOptions:    dialOpts,
+		Uploader:       service,
+		MaxUploadBytes: cfg.Gateway.MaxUploadBytes,
+		UploadDir:      cfg.Gateway.UploadDir,
+		Logger:         slog.Default(),
+		Tracer:         tracer,
+		Auth:           authenticator,
+		Interceptor:    limiter.UnaryServerInterceptor(rateLimitExempt...),
+	})
+}
+
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
\ No newline at end of file
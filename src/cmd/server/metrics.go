@@ -0,0 +1,20 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+)
+
+// newMetricsServer serves the metrics for Prometheus to scrape. It is a plain
+// HTTP endpoint on its own port, so no push gateway is needed.
+func newMetricsServer(cfg *config.Config, m *metrics.Metrics) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, m.Registry().Handler())
+	return &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
\ No newline at end of file
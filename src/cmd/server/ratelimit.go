@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// rateLimitExempt are the RPCs not counted against request rates, so probes
+// and usage checks still answer while a caller is throttled
+var rateLimitExempt = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+	pb.DataProcessorService_HealthCheck_FullMethodName,
+	pb.DataProcessorService_GetQuotaUsage_FullMethodName,
+}
+
+// newLimiter builds the limiter from the config
+func newLimiter(cfg *config.Config) *ratelimit.Limiter {
+	return ratelimit.New(limitsFromConfig(cfg.RateLimits.Global), limitsFromConfig(cfg.RateLimits.Account), accountLimits(cfg))
+}
+
+// accountLimits returns the per-account overrides
+func accountLimits(cfg *config.Config) map[string]ratelimit.Limits {
+	accounts := make(map[string]ratelimit.Limits, len(cfg.RateLimits.Accounts))
+	for account, limits := range cfg.RateLimits.Accounts {
+		accounts[account] = limitsFromConfig(limits)
+	}
+	return accounts
+}
+
+func limitsFromConfig(l config.LimitsConfig) ratelimit.Limits {
+	return ratelimit.Limits{
+		RequestsPerSecond: l.RequestsPerSecond,
+		Burst:             l.Burst,
+		ConcurrentImports: l.ConcurrentImports,
+		RecordsPerDay:     l.RecordsPerDay,
+	}
+}
\ No newline at end of file
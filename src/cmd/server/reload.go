@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+)
+
+// reloader applies the settings that are safe to change at runtime when the
+// server gets SIGHUP: the log level, API keys, rate limits and validation
+// rules. Other changed settings are logged and wait for a restart.
+type reloader struct {
+	configFile    string
+	running       *config.Config
+	logLevel      *slog.LevelVar
+	authenticator *auth.Authenticator
+	limiter       *ratelimit.Limiter
+	service       *handler.DataProcessorService
+}
+
+// reload reads the configuration again. Nothing is applied unless all of it
+// is valid.
+func (r *reloader) reload() error {
+	cfg, err := loadConfig(r.configFile)
+	if err != nil {
+		return err
+	}
+	level, err := logging.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return err
+	}
+	var store *auth.Store
+	if r.authenticator != nil && cfg.Auth.Enabled {
+		if store, err = newKeyStore(cfg); err != nil {
+			return err
+		}
+	}
+	ruleSets, err := newRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	r.logLevel.Set(level)
+	if store != nil {
+		r.authenticator.SetStore(store)
+	}
+	if cfg.RateLimits.Enabled {
+		r.limiter.SetLimits(limitsFromConfig(cfg.RateLimits.Global), limitsFromConfig(cfg.RateLimits.Account), accountLimits(cfg))
+	}
+	r.service.SetRules(ruleSets)
+	slog.Info("configuration reloaded", "log_level", level.String(), "api_keys", len(cfg.Auth.Keys), "rules", cfg.Rules.Enabled)
+
+	if changed := r.running.RestartRequired(cfg); len(changed) > 0 {
+		slog.Warn("changed settings take effect after a restart", "settings", changed)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+)
+
+// newRules loads the validation rules, or returns nil when the rules engine
+// is disabled
+func newRules(cfg *config.Config) (*rules.Registry, error) {
+	if !cfg.Rules.Enabled {
+		return nil, nil
+	}
+	if cfg.Rules.File == "" {
+		return rules.Default(), nil
+	}
+	return rules.LoadFile(cfg.Rules.File)
+}
\ No newline at end of file
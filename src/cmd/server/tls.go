@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/certs"
+)
+
+// newTLS loads the server certificate and client CAs. In self-signed mode a
+// certificate is generated first: written to cert_file and key_file when they
+// are set and missing, or kept in memory otherwise.
+func newTLS(cfg *config.Config) (*certs.Reloader, error) {
+	minVersion, err := certs.ParseVersion(cfg.TLS.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	opts := certs.Options{
+		CertFile:     cfg.TLS.CertFile,
+		KeyFile:      cfg.TLS.KeyFile,
+		ClientCAFile: cfg.TLS.ClientCAFile,
+		MinVersion:   minVersion,
+	}
+	if !cfg.TLS.SelfSigned {
+		return certs.NewReloader(opts)
+	}
+
+	if cfg.TLS.CertFile != "" {
+		if _, err := os.Stat(cfg.TLS.CertFile); errors.Is(err, fs.ErrNotExist) {
+			certPEM, keyPEM, err := certs.SelfSigned(cfg.TLS.SelfSignedHosts...)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(cfg.TLS.KeyFile, keyPEM, 0o600); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(cfg.TLS.CertFile, certPEM, 0o644); err != nil {
+				return nil, err
+			}
+			slog.Info("generated a self-signed certificate", "cert_file", cfg.TLS.CertFile, "hosts", cfg.TLS.SelfSignedHosts)
+		}
+		return certs.NewReloader(opts)
+	}
+
+	certPEM, keyPEM, err := certs.SelfSigned(cfg.TLS.SelfSignedHosts...)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	slog.Warn("serving an in-memory self-signed certificate; clients must skip verification", "hosts", cfg.TLS.SelfSignedHosts)
+	opts.Certificate = &cert
+	return certs.NewReloader(opts)
+}
\ No newline at end of file
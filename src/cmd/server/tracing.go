@@ -0,0 +1,22 @@
+package main
+
+import (
+	"os"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
+)
+
+// newTracer creates the tracer and its exporter, which writes spans as JSON
+// lines to stdout or the configured file. Close the exporter on shutdown.
+func newTracer(cfg *config.Config) (*tracing.Tracer, *tracing.WriterExporter, error) {
+	exporter := tracing.NewWriterExporter(os.Stdout)
+	if cfg.Tracing.Exporter == "file" {
+		var err error
+		exporter, err = tracing.NewFileExporter(cfg.Tracing.File)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return tracing.NewTracer(cfg.Tracing.ServiceName, exporter, cfg.Tracing.SampleRatio), exporter, nil
+}
\ No newline at end of file
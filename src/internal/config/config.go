@@ -4,17 +4,309 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/envconfig"
 	"gopkg.in/yaml.v3"
 )
 
+// EnvPrefix prefixes the environment variable of every setting, e.g.
+// ETC_PROCESSOR_GATEWAY_PORT for gateway.port
+const EnvPrefix = "ETC_PROCESSOR"
+
 // Config holds the application configuration
 type Config struct {
-	Port          int    `json:"port" yaml:"port"`
-	DBServiceAddr string `json:"db_service_addr" yaml:"db_service_addr"`
-	MaxBatchSize  int    `json:"max_batch_size" yaml:"max_batch_size"`
-	ValidateData  bool   `json:"validate_data" yaml:"validate_data"`
-	LogLevel      string `json:"log_level" yaml:"log_level"`
+	Port          int              `json:"port" yaml:"port"`
+	DBServiceAddr string           `json:"db_service_addr" yaml:"db_service_addr"`
+	LocalStore    LocalStoreConfig `json:"local_store" yaml:"local_store"`
+	MaxBatchSize  int              `json:"max_batch_size" yaml:"max_batch_size"`
+	ValidateData  bool             `json:"validate_data" yaml:"validate_data"`
+	LogLevel      string           `json:"log_level" yaml:"log_level"`
+	LogFormat     string           `json:"log_format" yaml:"log_format"`
+	Retry         RetryConfig      `json:"retry" yaml:"retry"`
+	DeadLetterDir string           `json:"dead_letter_dir" yaml:"dead_letter_dir"`
+	Duplicates    DuplicateConfig  `json:"duplicates" yaml:"duplicates"`
+	Rules         RulesConfig      `json:"rules" yaml:"rules"`
+	Sandbox       SandboxConfig    `json:"sandbox" yaml:"sandbox"`
+	Inbox         InboxConfig      `json:"inbox" yaml:"inbox"`
+	Batch         BatchConfig      `json:"batch" yaml:"batch"`
+	Archive       ArchiveConfig    `json:"archive" yaml:"archive"`
+	Gateway       GatewayConfig    `json:"gateway" yaml:"gateway"`
+	Health        HealthConfig     `json:"health" yaml:"health"`
+	Metrics       MetricsConfig    `json:"metrics" yaml:"metrics"`
+	Tracing       TracingConfig    `json:"tracing" yaml:"tracing"`
+	Auth          AuthConfig       `json:"auth" yaml:"auth"`
+	TLS           TLSConfig        `json:"tls" yaml:"tls"`
+	RateLimits    RateLimitConfig  `json:"rate_limits" yaml:"rate_limits"`
+}
+
+// RateLimitConfig limits each account (Account, overridden per account in
+// Accounts) and all accounts together (Global). Callers over a limit get
+// ResourceExhausted with a retry-after header.
+type RateLimitConfig struct {
+	Enabled  bool                    `json:"enabled" yaml:"enabled"`
+	Global   LimitsConfig            `json:"global" yaml:"global"`
+	Account  LimitsConfig            `json:"account" yaml:"account"`
+	Accounts map[string]LimitsConfig `json:"accounts" yaml:"accounts"`
+}
+
+// LimitsConfig is one set of limits; zero values are unlimited. Burst defaults
+// to RequestsPerSecond rounded up. RecordsPerDay counts parsed rows imported
+// since local midnight.
+type LimitsConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second" yaml:"requests_per_second"`
+	Burst             int     `json:"burst" yaml:"burst"`
+	ConcurrentImports int     `json:"concurrent_imports" yaml:"concurrent_imports"`
+	RecordsPerDay     int64   `json:"records_per_day" yaml:"records_per_day"`
+}
+
+// Validate checks that no limit is negative
+func (l LimitsConfig) Validate() error {
+	if l.RequestsPerSecond < 0 || l.Burst < 0 || l.ConcurrentImports < 0 || l.RecordsPerDay < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	return nil
+}
+
+// TLSConfig serves gRPC over TLS. With ClientCAFile set, clients must present
+// a certificate issued by that CA (mutual TLS), and auth.keys may name its
+// identity in client_cert. Changed certificate files are picked up on the next
+// handshake. SelfSigned generates CertFile and KeyFile when they are missing,
+// or an in-memory certificate when they are not set, for local testing.
+type TLSConfig struct {
+	Enabled         bool     `json:"enabled" yaml:"enabled"`
+	CertFile        string   `json:"cert_file" yaml:"cert_file"`
+	KeyFile         string   `json:"key_file" yaml:"key_file"`
+	ClientCAFile    string   `json:"client_ca_file" yaml:"client_ca_file"`
+	MinVersion      string   `json:"min_version" yaml:"min_version"`
+	SelfSigned      bool     `json:"self_signed" yaml:"self_signed"`
+	SelfSignedHosts []string `json:"self_signed_hosts" yaml:"self_signed_hosts"`
+}
+
+// AuthConfig requires an API key (authorization: Bearer <key> or x-api-key),
+// or a mutual-TLS client certificate, on every RPC and REST call except
+// health checks. A key may only act for
+// its accounts ("*" for all); requests for other accounts get
+// PermissionDenied.
+type AuthConfig struct {
+	Enabled bool           `json:"enabled" yaml:"enabled"`
+	Keys    []APIKeyConfig `json:"keys" yaml:"keys"`
+}
+
+// APIKeyConfig is one API key, given as Key or, to keep it out of the config
+// file, as the hex SHA-256 of the key. ClientCert instead names a client
+// certificate by its common name, DNS name or URI.
+type APIKeyConfig struct {
+	Name       string   `json:"name" yaml:"name"`
+	Key        string   `json:"key" yaml:"key"`
+	KeySHA256  string   `json:"key_sha256" yaml:"key_sha256"`
+	ClientCert string   `json:"client_cert" yaml:"client_cert"`
+	Accounts   []string `json:"accounts" yaml:"accounts"`
+}
+
+// LocalStoreConfig saves records to an embedded database file instead of
+// db_service, so the server can run on its own
+type LocalStoreConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Path    string `json:"path" yaml:"path"`
+}
+
+// TracingConfig exports OpenTelemetry-compatible spans as JSON lines to stdout
+// or File, so traces can be inspected without a collector. SampleRatio is the
+// fraction of new traces exported (0 < ratio <= 1); requests carrying a W3C
+// traceparent follow the caller's sampling decision.
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled" yaml:"enabled"`
+	Exporter    string  `json:"exporter" yaml:"exporter"`
+	File        string  `json:"file" yaml:"file"`
+	SampleRatio float64 `json:"sample_ratio" yaml:"sample_ratio"`
+	ServiceName string  `json:"service_name" yaml:"service_name"`
+}
+
+// MetricsConfig serves Prometheus text-format metrics on their own port for
+// Prometheus to scrape
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	Port    int    `json:"port" yaml:"port"`
+	Path    string `json:"path" yaml:"path"`
+}
+
+// HealthConfig sets how often readiness probes the dependencies (database,
+// inbox job queue, writable storage) and how long one probe may take
+type HealthConfig struct {
+	IntervalSeconds int `json:"interval_seconds" yaml:"interval_seconds"`
+	TimeoutSeconds  int `json:"timeout_seconds" yaml:"timeout_seconds"`
+}
+
+// GatewayConfig enables the REST gateway on its own port. Mode is in_process
+// (call the service directly) or proxy (forward to the gRPC port). Files sent
+// to POST /v1/upload are spooled to UploadDir (default: system temp directory).
+type GatewayConfig struct {
+	Enabled                bool   `json:"enabled" yaml:"enabled"`
+	Port                   int    `json:"port" yaml:"port"`
+	Mode                   string `json:"mode" yaml:"mode"`
+	ShutdownTimeoutSeconds int    `json:"shutdown_timeout_seconds" yaml:"shutdown_timeout_seconds"`
+	MaxUploadBytes         int64  `json:"max_upload_bytes" yaml:"max_upload_bytes"`
+	UploadDir              string `json:"upload_dir" yaml:"upload_dir"`
+}
+
+// ArchiveConfig limits how far a ZIP archive may expand: entries in the
+// archive, uncompressed bytes of one CSV entry and of all entries together
+type ArchiveConfig struct {
+	MaxEntries    int   `json:"max_entries" yaml:"max_entries"`
+	MaxEntryBytes int64 `json:"max_entry_bytes" yaml:"max_entry_bytes"`
+	MaxTotalBytes int64 `json:"max_total_bytes" yaml:"max_total_bytes"`
+}
+
+// BatchConfig bounds ProcessCSVFiles requests: how many files one request may
+// resolve to and how many are processed at once
+type BatchConfig struct {
+	MaxFiles    int `json:"max_files" yaml:"max_files"`
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+}
+
+// InboxConfig configures the watched inbox directories used in ingest mode
+type InboxConfig struct {
+	PollIntervalMs int              `json:"poll_interval_ms" yaml:"poll_interval_ms"`
+	StableSeconds  int              `json:"stable_seconds" yaml:"stable_seconds"`
+	SkipDuplicates bool             `json:"skip_duplicates" yaml:"skip_duplicates"`
+	Dirs           []InboxDirConfig `json:"dirs" yaml:"dirs"`
+}
+
+// InboxDirConfig is one watched directory. AccountPattern matches the path
+// relative to Path; "{account}" captures the account ID and "*" matches within
+// one path element. AccountID is used when the pattern has no {account}.
+type InboxDirConfig struct {
+	Path           string `json:"path" yaml:"path"`
+	AccountPattern string `json:"account_pattern" yaml:"account_pattern"`
+	AccountID      string `json:"account_id" yaml:"account_id"`
+	ArchiveDir     string `json:"archive_dir" yaml:"archive_dir"`
+	FailedDir      string `json:"failed_dir" yaml:"failed_dir"`
+}
+
+// SandboxConfig restricts which files ProcessCSVFile may read. The sandbox is
+// always on unless Disabled is set; without AllowedRoots only
+// DefaultSandboxRoot is readable.
+type SandboxConfig struct {
+	AllowedRoots      []string `json:"allowed_roots" yaml:"allowed_roots"`
+	MaxFileSizeBytes  int64    `json:"max_file_size_bytes" yaml:"max_file_size_bytes"`
+	AllowedExtensions []string `json:"allowed_extensions" yaml:"allowed_extensions"`
+	Disabled          bool     `json:"disabled" yaml:"disabled"`
+}
+
+// DefaultSandboxRoot is the directory ProcessCSVFile may read when no
+// sandbox.allowed_roots are configured
+const DefaultSandboxRoot = "data"
+
+// Roots returns the allowed roots, or DefaultSandboxRoot when none are set
+func (s SandboxConfig) Roots() []string {
+	if len(s.AllowedRoots) == 0 {
+		return []string{DefaultSandboxRoot}
+	}
+	return s.AllowedRoots
+}
+
+// RulesConfig enables the validation rules engine. File points to a YAML
+// rules file; when empty the built-in rule sets are used.
+type RulesConfig struct {
+	Enabled bool   `json:"enabled" yaml:"enabled"`
+	File    string `json:"file" yaml:"file"`
+}
+
+// DuplicateConfig selects the duplicate-key strategy, optionally per account.
+// Strategies: exact_row, card_exit_amount, ignore_status.
+type DuplicateConfig struct {
+	Strategy string            `json:"strategy" yaml:"strategy"`
+	Accounts map[string]string `json:"accounts" yaml:"accounts"`
+	Fuzzy    FuzzyConfig       `json:"fuzzy" yaml:"fuzzy"`
+}
+
+// FuzzyConfig enables matching of rows re-issued by the card issuer: same card
+// and exit IC with exit times within the window. Matched rows are classified as
+// correction, status change or duplicate, and upserted on import.
+type FuzzyConfig struct {
+	Enabled       bool `json:"enabled" yaml:"enabled"`
+	WindowMinutes int  `json:"window_minutes" yaml:"window_minutes"`
+}
+
+// RetryConfig holds the retry policy for database saves
+type RetryConfig struct {
+	MaxAttempts      int      `json:"max_attempts" yaml:"max_attempts"`
+	InitialBackoffMs int      `json:"initial_backoff_ms" yaml:"initial_backoff_ms"`
+	MaxBackoffMs     int      `json:"max_backoff_ms" yaml:"max_backoff_ms"`
+	Multiplier       float64  `json:"multiplier" yaml:"multiplier"`
+	RetryableCodes   []string `json:"retryable_codes" yaml:"retryable_codes"`
+}
+
+// Default returns the configuration used when nothing is set
+func Default() *Config {
+	cfg := &Config{ValidateData: true}
+	cfg.SetDefaults()
+	return cfg
+}
+
+// Load layers the configuration: defaults, then the file when filename is not
+// empty, then ETC_PROCESSOR_* variables from environ. Settings the file or the
+// environment leave out keep their defaults. Callers apply their flags on top
+// and then Validate.
+func Load(filename string, environ []string) (*Config, error) {
+	cfg := Default()
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			cfg = Default()
+			if jsonErr := json.Unmarshal(data, cfg); jsonErr != nil {
+				return nil, fmt.Errorf("failed to parse config file %s: %v", filename, err)
+			}
+		}
+	}
+
+	// ETC_PROCESSOR_DB_ADDR predates ETC_PROCESSOR_DB_SERVICE_ADDR
+	var rest []string
+	for _, kv := range environ {
+		if addr, ok := strings.CutPrefix(kv, EnvPrefix+"_DB_ADDR="); ok {
+			cfg.DBServiceAddr = addr
+			continue
+		}
+		rest = append(rest, kv)
+	}
+	if err := envconfig.Apply(cfg, EnvPrefix, rest); err != nil {
+		return nil, err
+	}
+
+	cfg.SetDefaults()
+	return cfg, nil
+}
+
+// RestartRequired returns the top-level settings that differ in next and are
+// only read at startup. The log level, API keys, rate limits and validation
+// rules are applied on reload and not reported.
+func (c *Config) RestartRequired(next *Config) []string {
+	old, updated := c.withoutReloadable(), next.withoutReloadable()
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(updated).Elem()
+	var changed []string
+	for i := 0; i < ov.NumField(); i++ {
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			name, _, _ := strings.Cut(ov.Type().Field(i).Tag.Get("yaml"), ",")
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// withoutReloadable returns a copy with the settings applied on reload cleared.
+// Turning auth or rate limits on or off still needs a restart.
+func (c *Config) withoutReloadable() *Config {
+	cp := *c
+	cp.LogLevel = ""
+	cp.Auth.Keys = nil
+	cp.RateLimits = RateLimitConfig{Enabled: c.RateLimits.Enabled}
+	cp.Rules = RulesConfig{}
+	return &cp
 }
 
 // LoadFromFile loads configuration from a file
@@ -49,6 +341,140 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid max_batch_size: %d", c.MaxBatchSize)
 	}
 
+	if c.Retry.MaxAttempts < 0 {
+		return fmt.Errorf("invalid retry.max_attempts: %d", c.Retry.MaxAttempts)
+	}
+
+	if c.Retry.InitialBackoffMs < 0 || c.Retry.MaxBackoffMs < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+
+	if c.Retry.Multiplier != 0 && c.Retry.Multiplier < 1 {
+		return fmt.Errorf("invalid retry.multiplier: %v", c.Retry.Multiplier)
+	}
+
+	if c.Sandbox.MaxFileSizeBytes < 0 {
+		return fmt.Errorf("invalid sandbox.max_file_size_bytes: %d", c.Sandbox.MaxFileSizeBytes)
+	}
+
+	if c.Sandbox.Disabled && len(c.Sandbox.AllowedRoots) > 0 {
+		return fmt.Errorf("sandbox.disabled and sandbox.allowed_roots cannot both be set")
+	}
+
+	if c.Inbox.PollIntervalMs < 0 || c.Inbox.StableSeconds < 0 {
+		return fmt.Errorf("inbox intervals must not be negative")
+	}
+
+	for i, dir := range c.Inbox.Dirs {
+		if dir.Path == "" {
+			return fmt.Errorf("inbox.dirs[%d].path is required", i)
+		}
+	}
+
+	if c.Batch.MaxFiles < 0 || c.Batch.Concurrency < 0 {
+		return fmt.Errorf("batch limits must not be negative")
+	}
+
+	if c.Archive.MaxEntries < 0 || c.Archive.MaxEntryBytes < 0 || c.Archive.MaxTotalBytes < 0 {
+		return fmt.Errorf("archive limits must not be negative")
+	}
+
+	if c.Gateway.Mode != "" && c.Gateway.Mode != "in_process" && c.Gateway.Mode != "proxy" {
+		return fmt.Errorf("invalid gateway.mode: %s", c.Gateway.Mode)
+	}
+
+	if c.Gateway.Port < 0 || c.Gateway.Port > 65535 {
+		return fmt.Errorf("invalid gateway.port: %d", c.Gateway.Port)
+	}
+
+	if c.Gateway.MaxUploadBytes < 0 {
+		return fmt.Errorf("invalid gateway.max_upload_bytes: %d", c.Gateway.MaxUploadBytes)
+	}
+
+	if c.Gateway.ShutdownTimeoutSeconds < 0 {
+		return fmt.Errorf("invalid gateway.shutdown_timeout_seconds: %d", c.Gateway.ShutdownTimeoutSeconds)
+	}
+
+	if c.Gateway.Enabled && c.Gateway.Port == c.Port {
+		return fmt.Errorf("gateway.port must differ from port")
+	}
+
+	switch strings.ToLower(c.LogLevel) {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return fmt.Errorf("invalid log_level: %s", c.LogLevel)
+	}
+
+	if c.LogFormat != "" && c.LogFormat != "json" && c.LogFormat != "logfmt" {
+		return fmt.Errorf("invalid log_format: %s", c.LogFormat)
+	}
+
+	if c.Metrics.Port < 0 || c.Metrics.Port > 65535 {
+		return fmt.Errorf("invalid metrics.port: %d", c.Metrics.Port)
+	}
+
+	if c.Metrics.Path != "" && !strings.HasPrefix(c.Metrics.Path, "/") {
+		return fmt.Errorf("metrics.path must start with /: %s", c.Metrics.Path)
+	}
+
+	if c.Metrics.Enabled && (c.Metrics.Port == c.Port || (c.Gateway.Enabled && c.Metrics.Port == c.Gateway.Port)) {
+		return fmt.Errorf("metrics.port must differ from port and gateway.port")
+	}
+
+	if c.Tracing.Exporter != "" && c.Tracing.Exporter != "stdout" && c.Tracing.Exporter != "file" {
+		return fmt.Errorf("invalid tracing.exporter: %s", c.Tracing.Exporter)
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Exporter == "file" && c.Tracing.File == "" {
+		return fmt.Errorf("tracing.file is required for the file exporter")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing.sample_ratio: %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Auth.Enabled && len(c.Auth.Keys) == 0 {
+		return fmt.Errorf("auth.keys is required when auth is enabled")
+	}
+
+	if c.TLS.Enabled && !c.TLS.SelfSigned && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		return fmt.Errorf("tls.cert_file and tls.key_file are required when tls is enabled")
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("tls.cert_file and tls.key_file must be set together")
+	}
+
+	if c.TLS.MinVersion != "" && c.TLS.MinVersion != "1.2" && c.TLS.MinVersion != "1.3" {
+		return fmt.Errorf("invalid tls.min_version: %s", c.TLS.MinVersion)
+	}
+
+	if err := c.RateLimits.Global.Validate(); err != nil {
+		return fmt.Errorf("invalid rate_limits.global: %w", err)
+	}
+
+	if err := c.RateLimits.Account.Validate(); err != nil {
+		return fmt.Errorf("invalid rate_limits.account: %w", err)
+	}
+
+	for account, limits := range c.RateLimits.Accounts {
+		if err := limits.Validate(); err != nil {
+			return fmt.Errorf("invalid rate_limits.accounts.%s: %w", account, err)
+		}
+	}
+
+	if c.Health.IntervalSeconds < 0 || c.Health.TimeoutSeconds < 0 {
+		return fmt.Errorf("health intervals must not be negative")
+	}
+
+	if c.Duplicates.Fuzzy.WindowMinutes < 0 {
+		return fmt.Errorf("invalid duplicates.fuzzy.window_minutes: %d", c.Duplicates.Fuzzy.WindowMinutes)
+	}
+
+	if c.LocalStore.Enabled && c.DBServiceAddr != "" {
+		return fmt.Errorf("local_store and db_service_addr cannot both be set")
+	}
+
 	return nil
 }
 
@@ -65,4 +491,125 @@ func (c *Config) SetDefaults() {
 	if c.LogLevel == "" {
 		c.LogLevel = "info"
 	}
+
+	if c.LogFormat == "" {
+		c.LogFormat = "json"
+	}
+
+	c.Retry.SetDefaults()
+
+	if c.Sandbox.MaxFileSizeBytes == 0 {
+		c.Sandbox.MaxFileSizeBytes = 50 << 20
+	}
+
+	if len(c.Sandbox.AllowedExtensions) == 0 {
+		c.Sandbox.AllowedExtensions = []string{".csv", ".zip"}
+	}
+
+	if c.Inbox.PollIntervalMs == 0 {
+		c.Inbox.PollIntervalMs = 2000
+	}
+
+	if c.Inbox.StableSeconds == 0 {
+		c.Inbox.StableSeconds = 5
+	}
+
+	if c.Batch.MaxFiles == 0 {
+		c.Batch.MaxFiles = 100
+	}
+
+	if c.Batch.Concurrency == 0 {
+		c.Batch.Concurrency = 4
+	}
+
+	if c.Gateway.Port == 0 {
+		c.Gateway.Port = 8080
+	}
+
+	if c.Gateway.Mode == "" {
+		c.Gateway.Mode = "in_process"
+	}
+
+	if c.Gateway.ShutdownTimeoutSeconds == 0 {
+		c.Gateway.ShutdownTimeoutSeconds = 10
+	}
+
+	if c.Gateway.MaxUploadBytes == 0 {
+		c.Gateway.MaxUploadBytes = 50 << 20
+	}
+
+	if c.Archive.MaxEntries == 0 {
+		c.Archive.MaxEntries = 100
+	}
+
+	if c.Archive.MaxEntryBytes == 0 {
+		c.Archive.MaxEntryBytes = 50 << 20
+	}
+
+	if c.Archive.MaxTotalBytes == 0 {
+		c.Archive.MaxTotalBytes = 200 << 20
+	}
+
+	if c.Metrics.Port == 0 {
+		c.Metrics.Port = 9090
+	}
+
+	if c.Metrics.Path == "" {
+		c.Metrics.Path = "/metrics"
+	}
+
+	if c.Health.IntervalSeconds == 0 {
+		c.Health.IntervalSeconds = 10
+	}
+
+	if c.Health.TimeoutSeconds == 0 {
+		c.Health.TimeoutSeconds = 2
+	}
+
+	if c.LocalStore.Path == "" {
+		c.LocalStore.Path = "data/etc.db"
+	}
+
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "stdout"
+	}
+
+	if c.Tracing.SampleRatio == 0 {
+		c.Tracing.SampleRatio = 1
+	}
+
+	if c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = "etc_data_processor"
+	}
+
+	if c.TLS.MinVersion == "" {
+		c.TLS.MinVersion = "1.2"
+	}
+
+	if len(c.TLS.SelfSignedHosts) == 0 {
+		c.TLS.SelfSignedHosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+}
+
+// SetDefaults sets default values for empty retry fields
+func (r *RetryConfig) SetDefaults() {
+	if r.MaxAttempts == 0 {
+		r.MaxAttempts = 3
+	}
+
+	if r.InitialBackoffMs == 0 {
+		r.InitialBackoffMs = 100
+	}
+
+	if r.MaxBackoffMs == 0 {
+		r.MaxBackoffMs = 2000
+	}
+
+	if r.Multiplier == 0 {
+		r.Multiplier = 2.0
+	}
+
+	if len(r.RetryableCodes) == 0 {
+		r.RetryableCodes = []string{"UNAVAILABLE", "DEADLINE_EXCEEDED", "RESOURCE_EXHAUSTED", "ABORTED"}
+	}
 }
\ No newline at end of file
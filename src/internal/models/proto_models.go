@@ -4,8 +4,8 @@ import "time"
 
 // ProtoService defines the gRPC service
 type ProtoService struct {
-	Name    string `proto:"DataProcessorService"`
-	Package string `proto:"etcdataprocessor.v1"`
+	Name      string `proto:"DataProcessorService"`
+	Package   string `proto:"etcdataprocessor.v1"`
 	GoPackage string `proto:"github.com/yhonda-ohishi/etc_data_processor/src/api/pb;pb"`
 }
 
@@ -18,39 +18,91 @@ type ProcessCSVFileRequest struct {
 
 // ProcessCSVFileResponse represents response for CSV file processing
 type ProcessCSVFileResponse struct {
-	Success bool             `json:"success" proto:"1"`
-	Message string           `json:"message" proto:"2"`
-	Stats   *ProcessingStats `json:"stats" proto:"3"`
-	Errors  []string         `json:"errors" proto:"4,repeated"`
+	Success           bool             `json:"success" proto:"1"`
+	Message           string           `json:"message" proto:"2"`
+	Stats             *ProcessingStats `json:"stats" proto:"3"`
+	Errors            []string         `json:"errors" proto:"4,repeated"`
+	ImportID          string           `json:"import_id" proto:"5"`
+	RecordErrors      []RecordError    `json:"record_errors" proto:"6,repeated"`
+	DuplicateStrategy string           `json:"duplicate_strategy" proto:"7"`
+	RuleSet           string           `json:"rule_set" proto:"8"`
+	Entries           []FileResult     `json:"entries" proto:"9,repeated"`
 }
 
-// ProcessCSVDataRequest represents request for CSV data processing
+// ProcessCSVFilesRequest represents request for processing several CSV files
+type ProcessCSVFilesRequest struct {
+	CSVFilePaths   []string `json:"csv_file_paths" proto:"1,repeated"`
+	Directory      string   `json:"directory" proto:"2"`
+	Glob           string   `json:"glob" proto:"3"`
+	AccountID      string   `json:"account_id" proto:"4"`
+	SkipDuplicates bool     `json:"skip_duplicates" proto:"5"`
+	Concurrency    int32    `json:"concurrency" proto:"6"`
+}
+
+// FileResult represents the result of one file in a batch import
+type FileResult struct {
+	CSVFilePath  string           `json:"csv_file_path" proto:"1"`
+	Success      bool             `json:"success" proto:"2"`
+	Message      string           `json:"message" proto:"3"`
+	Stats        *ProcessingStats `json:"stats" proto:"4"`
+	Errors       []string         `json:"errors" proto:"5,repeated"`
+	RecordErrors []RecordError    `json:"record_errors" proto:"6,repeated"`
+}
+
+// ProcessCSVFilesResponse represents response for processing several CSV files
+type ProcessCSVFilesResponse struct {
+	Success           bool             `json:"success" proto:"1"`
+	Message           string           `json:"message" proto:"2"`
+	Totals            *ProcessingStats `json:"totals" proto:"3"`
+	Files             []FileResult     `json:"files" proto:"4,repeated"`
+	ImportID          string           `json:"import_id" proto:"5"`
+	FailedFiles       int32            `json:"failed_files" proto:"6"`
+	DuplicateStrategy string           `json:"duplicate_strategy" proto:"7"`
+	RuleSet           string           `json:"rule_set" proto:"8"`
+}
+
+// ProcessCSVDataRequest represents request for CSV data processing.
+// Encoding holds the Encoding enum value for CSVBytes.
 type ProcessCSVDataRequest struct {
 	CSVData        string `json:"csv_data" proto:"1"`
 	AccountID      string `json:"account_id" proto:"2"`
 	SkipDuplicates bool   `json:"skip_duplicates" proto:"3"`
+	CSVBytes       []byte `json:"csv_bytes" proto:"4"`
+	Encoding       int32  `json:"encoding" proto:"5"`
 }
 
 // ProcessCSVDataResponse represents response for CSV data processing
 type ProcessCSVDataResponse struct {
-	Success bool             `json:"success" proto:"1"`
-	Message string           `json:"message" proto:"2"`
-	Stats   *ProcessingStats `json:"stats" proto:"3"`
-	Errors  []string         `json:"errors" proto:"4,repeated"`
+	Success           bool             `json:"success" proto:"1"`
+	Message           string           `json:"message" proto:"2"`
+	Stats             *ProcessingStats `json:"stats" proto:"3"`
+	Errors            []string         `json:"errors" proto:"4,repeated"`
+	ImportID          string           `json:"import_id" proto:"5"`
+	RecordErrors      []RecordError    `json:"record_errors" proto:"6,repeated"`
+	DuplicateStrategy string           `json:"duplicate_strategy" proto:"7"`
+	RuleSet           string           `json:"rule_set" proto:"8"`
 }
 
-// ValidateCSVDataRequest represents request for CSV validation
+// ValidateCSVDataRequest represents request for CSV validation.
+// Encoding holds the Encoding enum value for CSVBytes.
 type ValidateCSVDataRequest struct {
 	CSVData   string `json:"csv_data" proto:"1"`
 	AccountID string `json:"account_id" proto:"2"`
+	CSVBytes  []byte `json:"csv_bytes" proto:"3"`
+	Encoding  int32  `json:"encoding" proto:"4"`
 }
 
 // ValidateCSVDataResponse represents response for CSV validation
 type ValidateCSVDataResponse struct {
-	IsValid        bool               `json:"is_valid" proto:"1"`
-	Errors         []ValidationError  `json:"errors" proto:"2,repeated"`
-	DuplicateCount int32              `json:"duplicate_count" proto:"3"`
-	TotalRecords   int32              `json:"total_records" proto:"4"`
+	IsValid           bool              `json:"is_valid" proto:"1"`
+	Errors            []ValidationError `json:"errors" proto:"2,repeated"`
+	DuplicateCount    int32             `json:"duplicate_count" proto:"3"`
+	TotalRecords      int32             `json:"total_records" proto:"4"`
+	RecordErrors      []RecordError     `json:"record_errors" proto:"5,repeated"`
+	DuplicateStrategy string            `json:"duplicate_strategy" proto:"6"`
+	CorrectionCount   int32             `json:"correction_count" proto:"7"`
+	StatusChangeCount int32             `json:"status_change_count" proto:"8"`
+	RuleSet           string            `json:"rule_set" proto:"9"`
 }
 
 // HealthCheckRequest represents health check request
@@ -66,10 +118,17 @@ type HealthCheckResponse struct {
 
 // ProcessingStats represents processing statistics
 type ProcessingStats struct {
-	TotalRecords   int32 `json:"total_records" proto:"1"`
-	SavedRecords   int32 `json:"saved_records" proto:"2"`
-	SkippedRecords int32 `json:"skipped_records" proto:"3"`
-	ErrorRecords   int32 `json:"error_records" proto:"4"`
+	TotalRecords     int32 `json:"total_records" proto:"1"`
+	SavedRecords     int32 `json:"saved_records" proto:"2"`
+	SkippedRecords   int32 `json:"skipped_records" proto:"3"`
+	ErrorRecords     int32 `json:"error_records" proto:"4"`
+	RetriedRecords   int32 `json:"retried_records" proto:"5"`
+	RetryAttempts    int32 `json:"retry_attempts" proto:"6"`
+	TransientErrors  int32 `json:"transient_errors" proto:"7"`
+	PermanentErrors  int32 `json:"permanent_errors" proto:"8"`
+	CorrectedRecords int32 `json:"corrected_records" proto:"9"`
+	StatusChanges    int32 `json:"status_changes" proto:"10"`
+	UpdatedRecords   int32 `json:"updated_records" proto:"11"`
 }
 
 // ValidationError represents validation error details
@@ -80,6 +139,109 @@ type ValidationError struct {
 	RecordData string `json:"record_data" proto:"4"`
 }
 
+// RecordError represents a structured record-level error.
+// Code and Severity hold the ErrorCode and Severity enum values.
+type RecordError struct {
+	Code        int32  `json:"code" proto:"1"`
+	LineNumber  int32  `json:"line_number" proto:"2"`
+	RecordIndex int32  `json:"record_index" proto:"3"`
+	Field       string `json:"field" proto:"4"`
+	Severity    int32  `json:"severity" proto:"5"`
+	Message     string `json:"message" proto:"6"`
+	Retryable   bool   `json:"retryable" proto:"7"`
+	Rule        string `json:"rule" proto:"8"`
+}
+
+// ListFailedRecordsRequest represents request for listing dead-lettered records
+type ListFailedRecordsRequest struct {
+	AccountID string `json:"account_id" proto:"1"`
+	ImportID  string `json:"import_id" proto:"2"`
+	Limit     int32  `json:"limit" proto:"3"`
+}
+
+// ListFailedRecordsResponse represents response for listing dead-lettered records
+type ListFailedRecordsResponse struct {
+	Records    []FailedRecord `json:"records" proto:"1,repeated"`
+	TotalCount int32          `json:"total_count" proto:"2"`
+}
+
+// ReprocessFailedRecordsRequest represents request for replaying dead-lettered records
+type ReprocessFailedRecordsRequest struct {
+	AccountID string   `json:"account_id" proto:"1"`
+	ImportID  string   `json:"import_id" proto:"2"`
+	IDs       []string `json:"ids" proto:"3,repeated"`
+}
+
+// ReprocessFailedRecordsResponse represents response for replaying dead-lettered records
+type ReprocessFailedRecordsResponse struct {
+	Success        bool             `json:"success" proto:"1"`
+	Message        string           `json:"message" proto:"2"`
+	Stats          *ProcessingStats `json:"stats" proto:"3"`
+	Errors         []string         `json:"errors" proto:"4,repeated"`
+	RemainingCount int32            `json:"remaining_count" proto:"5"`
+	RecordErrors   []RecordError    `json:"record_errors" proto:"6,repeated"`
+}
+
+// GetQuotaUsageRequest represents request for rate limit and quota usage
+type GetQuotaUsageRequest struct {
+	AccountID string `json:"account_id" proto:"1"`
+}
+
+// GetQuotaUsageResponse represents response for rate limit and quota usage
+type GetQuotaUsageResponse struct {
+	Global   *QuotaUsage  `json:"global" proto:"1"`
+	Accounts []QuotaUsage `json:"accounts" proto:"2,repeated"`
+}
+
+// QuotaUsage represents the use of one account's limits, or of the global limits
+type QuotaUsage struct {
+	AccountID              string  `json:"account_id" proto:"1"`
+	RequestsPerSecondLimit float64 `json:"requests_per_second_limit" proto:"2"`
+	BurstLimit             int32   `json:"burst_limit" proto:"3"`
+	ConcurrentImports      int32   `json:"concurrent_imports" proto:"4"`
+	ConcurrentImportsLimit int32   `json:"concurrent_imports_limit" proto:"5"`
+	RecordsToday           int64   `json:"records_today" proto:"6"`
+	RecordsPerDayLimit     int64   `json:"records_per_day_limit" proto:"7"`
+	Day                    string  `json:"day" proto:"8"`
+	ThrottledRequests      int64   `json:"throttled_requests" proto:"9"`
+	RejectedImports        int64   `json:"rejected_imports" proto:"10"`
+}
+
+// FailedRecord represents a record held in the dead-letter store
+type FailedRecord struct {
+	ID                string         `json:"id" proto:"1"`
+	ImportID          string         `json:"import_id" proto:"2"`
+	AccountID         string         `json:"account_id" proto:"3"`
+	LineNumber        int32          `json:"line_number" proto:"4"`
+	RawRow            string         `json:"raw_row" proto:"5"`
+	Record            *ETCRecordData `json:"record" proto:"6"`
+	Stage             string         `json:"stage" proto:"7"`
+	Error             string         `json:"error" proto:"8"`
+	ErrorClass        string         `json:"error_class" proto:"9"`
+	ReprocessAttempts int32          `json:"reprocess_attempts" proto:"10"`
+	CreatedAt         int64          `json:"created_at" proto:"11"`
+	UpdatedAt         int64          `json:"updated_at" proto:"12"`
+}
+
+// ETCRecordData represents a parsed ETC CSV row
+type ETCRecordData struct {
+	EntryDate       string `json:"entry_date" proto:"1"`
+	EntryTime       string `json:"entry_time" proto:"2"`
+	ExitDate        string `json:"exit_date" proto:"3"`
+	ExitTime        string `json:"exit_time" proto:"4"`
+	EntryIC         string `json:"entry_ic" proto:"5"`
+	ExitIC          string `json:"exit_ic" proto:"6"`
+	RouteInfo       string `json:"route_info" proto:"7"`
+	ETCAmount       int32  `json:"etc_amount" proto:"8"`
+	NormalAmount    int32  `json:"normal_amount" proto:"9"`
+	DiscountApplied int32  `json:"discount_applied" proto:"10"`
+	Mileage         int32  `json:"mileage" proto:"11"`
+	VehicleClass    int32  `json:"vehicle_class" proto:"12"`
+	VehicleNumber   string `json:"vehicle_number" proto:"13"`
+	CardNumber      string `json:"card_number" proto:"14"`
+	Notes           string `json:"notes" proto:"15"`
+}
+
 // ServiceMethod represents a gRPC service method
 type ServiceMethod struct {
 	Name       string      `json:"name"`
@@ -111,6 +273,13 @@ func GetServiceDefinition() ServiceDefinition {
 				HTTPMethod: "POST",
 				HTTPPath:   "/v1/process/file",
 			},
+			{
+				Name:       "ProcessCSVFiles",
+				Request:    ProcessCSVFilesRequest{},
+				Response:   ProcessCSVFilesResponse{},
+				HTTPMethod: "POST",
+				HTTPPath:   "/v1/process/files",
+			},
 			{
 				Name:       "ProcessCSVData",
 				Request:    ProcessCSVDataRequest{},
@@ -132,6 +301,27 @@ func GetServiceDefinition() ServiceDefinition {
 				HTTPMethod: "GET",
 				HTTPPath:   "/v1/health",
 			},
+			{
+				Name:       "ListFailedRecords",
+				Request:    ListFailedRecordsRequest{},
+				Response:   ListFailedRecordsResponse{},
+				HTTPMethod: "GET",
+				HTTPPath:   "/v1/failed-records",
+			},
+			{
+				Name:       "ReprocessFailedRecords",
+				Request:    ReprocessFailedRecordsRequest{},
+				Response:   ReprocessFailedRecordsResponse{},
+				HTTPMethod: "POST",
+				HTTPPath:   "/v1/failed-records/reprocess",
+			},
+			{
+				Name:       "GetQuotaUsage",
+				Request:    GetQuotaUsageRequest{},
+				Response:   GetQuotaUsageResponse{},
+				HTTPMethod: "GET",
+				HTTPPath:   "/v1/admin/quota-usage",
+			},
 		},
 	}
 }
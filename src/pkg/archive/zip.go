@@ -0,0 +1,176 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+const (
+	DefaultMaxEntries    = 100
+	DefaultMaxEntryBytes = 50 << 20
+	DefaultMaxTotalBytes = 200 << 20
+)
+
+// Limits protects against ZIP bombs. Zero values use the defaults.
+type Limits struct {
+	MaxEntries    int   // entries in the archive, including skipped ones
+	MaxEntryBytes int64 // uncompressed size of one CSV entry
+	MaxTotalBytes int64 // uncompressed size of all CSV entries together
+}
+
+// LimitError is returned when an archive exceeds its limits
+type LimitError struct {
+	Reason string
+}
+
+// Error returns the limit that was exceeded
+func (e *LimitError) Error() string {
+	return e.Reason
+}
+
+// Entry is one CSV file read from an archive
+type Entry struct {
+	Name string // path inside the archive, decoded to UTF-8
+	Data []byte // raw file contents, not yet decoded
+}
+
+// IsZip reports whether a path names a ZIP archive
+func IsZip(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".zip")
+}
+
+// OpenFile reads the CSV entries of a ZIP file
+func OpenFile(filePath string, limits Limits) ([]Entry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return Read(file, info.Size(), limits)
+}
+
+// ReadBytes reads the CSV entries of an in-memory ZIP archive
+func ReadBytes(data []byte, limits Limits) ([]Entry, error) {
+	return Read(bytes.NewReader(data), int64(len(data)), limits)
+}
+
+// Read returns the CSV entries of a ZIP archive in archive order. Directories,
+// non-CSV files and macOS metadata are skipped. Names not flagged as UTF-8 are
+// decoded as CP932, which is what Japanese Windows uses. Sizes are enforced
+// while reading, so archives with forged headers are still caught.
+func Read(r io.ReaderAt, size int64, limits Limits) ([]Entry, error) {
+	limits = limits.withDefaults()
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %w", err)
+	}
+	if len(zr.File) > limits.MaxEntries {
+		return nil, &LimitError{Reason: fmt.Sprintf("archive has %d entries, more than the limit of %d", len(zr.File), limits.MaxEntries)}
+	}
+
+	var entries []Entry
+	var total int64
+	for _, f := range zr.File {
+		name := entryName(f)
+		if !isCSVEntry(f, name) {
+			continue
+		}
+		if f.UncompressedSize64 > uint64(limits.MaxEntryBytes) {
+			return nil, entryTooLarge(name, limits.MaxEntryBytes)
+		}
+
+		data, err := readEntry(f, limits.MaxEntryBytes)
+		if err != nil {
+			if _, ok := err.(*LimitError); ok {
+				return nil, entryTooLarge(name, limits.MaxEntryBytes)
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		total += int64(len(data))
+		if total > limits.MaxTotalBytes {
+			return nil, &LimitError{Reason: fmt.Sprintf("archive expands to more than %d bytes", limits.MaxTotalBytes)}
+		}
+		entries = append(entries, Entry{Name: name, Data: data})
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("archive contains no CSV files")
+	}
+	return entries, nil
+}
+
+// withDefaults fills in zero limits
+func (l Limits) withDefaults() Limits {
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = DefaultMaxEntries
+	}
+	if l.MaxEntryBytes <= 0 {
+		l.MaxEntryBytes = DefaultMaxEntryBytes
+	}
+	if l.MaxTotalBytes <= 0 {
+		l.MaxTotalBytes = DefaultMaxTotalBytes
+	}
+	return l
+}
+
+// readEntry reads at most max bytes of an entry
+func readEntry(f *zip.File, max int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, &LimitError{}
+	}
+	return data, nil
+}
+
+// entryName returns an entry's name as UTF-8. Many zippers write UTF-8 names
+// without the UTF-8 flag, so the flag is ignored: only names that are not
+// valid UTF-8 are decoded as CP932.
+func entryName(f *zip.File) string {
+	if utf8.ValidString(f.Name) {
+		return f.Name
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().String(f.Name)
+	if err != nil {
+		return strings.ToValidUTF8(f.Name, "?")
+	}
+	return decoded
+}
+
+// isCSVEntry reports whether an entry should be imported
+func isCSVEntry(f *zip.File, name string) bool {
+	if f.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") {
+		return false
+	}
+	base := path.Base(name)
+	return !strings.HasPrefix(base, ".") && strings.EqualFold(path.Ext(base), ".csv")
+}
+
+// entryTooLarge returns the limit error for an oversized entry
+func entryTooLarge(name string, max int64) error {
+	return &LimitError{Reason: fmt.Sprintf("%s expands to more than %d bytes", name, max)}
+}
\ No newline at end of file
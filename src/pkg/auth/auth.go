@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Credential headers, also used as gRPC metadata keys
+const (
+	AuthorizationHeader = "authorization" // "Bearer <key>"
+	APIKeyHeader        = "x-api-key"     // "<key>"
+)
+
+// AllAccounts in a key's accounts lets it act for every account
+const AllAccounts = "*"
+
+// Key is one configured API key. The key is given either in plain text or as
+// the hex SHA-256 of the key, so the config need not hold the key itself.
+// Instead of a key, ClientCert names a verified mutual-TLS client certificate
+// by its common name or a DNS or URI subject alternative name.
+type Key struct {
+	Name       string
+	Key        string
+	KeySHA256  string
+	ClientCert string
+	Accounts   []string
+}
+
+// Principal is an authenticated caller and the accounts it may act for
+type Principal struct {
+	Name     string
+	all      bool
+	accounts map[string]bool
+}
+
+// Allows reports whether the principal may act for the account
+func (p *Principal) Allows(accountID string) bool {
+	return p.all || p.accounts[accountID]
+}
+
+// Store holds the configured keys, indexed by their SHA-256, and the client
+// certificate identities
+type Store struct {
+	keys  map[[sha256.Size]byte]*Principal
+	certs map[string]*Principal
+}
+
+// NewStore checks and indexes the keys. Every key needs a name, one of a key,
+// its hash or a client certificate identity, and at least one account.
+func NewStore(keys []Key) (*Store, error) {
+	s := &Store{keys: make(map[[sha256.Size]byte]*Principal, len(keys)), certs: map[string]*Principal{}}
+	names := map[string]bool{}
+	for i, k := range keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("key %d: name is required", i)
+		}
+		if names[k.Name] {
+			return nil, fmt.Errorf("key %s: duplicate name", k.Name)
+		}
+		names[k.Name] = true
+
+		set := 0
+		for _, credential := range []string{k.Key, k.KeySHA256, k.ClientCert} {
+			if credential != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("key %s: set one of key, key_sha256 or client_cert", k.Name)
+		}
+
+		var sum [sha256.Size]byte
+		switch {
+		case k.Key != "":
+			sum = sha256.Sum256([]byte(k.Key))
+		case k.KeySHA256 != "":
+			b, err := hex.DecodeString(k.KeySHA256)
+			if err != nil || len(b) != sha256.Size {
+				return nil, fmt.Errorf("key %s: key_sha256 must be 64 hex characters", k.Name)
+			}
+			copy(sum[:], b)
+		}
+		if _, dup := s.certs[k.ClientCert]; dup && k.ClientCert != "" {
+			return nil, fmt.Errorf("key %s: client certificate %q is configured twice", k.Name, k.ClientCert)
+		}
+		if _, dup := s.keys[sum]; dup && k.ClientCert == "" {
+			return nil, fmt.Errorf("key %s: the same key is configured twice", k.Name)
+		}
+
+		if len(k.Accounts) == 0 {
+			return nil, fmt.Errorf("key %s: at least one account is required", k.Name)
+		}
+		p := &Principal{Name: k.Name, accounts: map[string]bool{}}
+		for _, account := range k.Accounts {
+			if account == AllAccounts {
+				p.all = true
+			}
+			p.accounts[account] = true
+		}
+		if k.ClientCert != "" {
+			s.certs[k.ClientCert] = p
+		} else {
+			s.keys[sum] = p
+		}
+	}
+	return s, nil
+}
+
+// Authenticate returns the principal of a key, or an Unauthenticated error
+func (s *Store) Authenticate(key string) (*Principal, error) {
+	if key == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing credentials: send \"authorization: Bearer <key>\" or \"x-api-key: <key>\"")
+	}
+	if p, ok := s.keys[sha256.Sum256([]byte(key))]; ok {
+		return p, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "invalid API key")
+}
+
+// AuthenticateCertificate returns the principal of a verified client
+// certificate, matched by common name, DNS name or URI, or an Unauthenticated
+// error
+func (s *Store) AuthenticateCertificate(cert *x509.Certificate) (*Principal, error) {
+	identities := append([]string{cert.Subject.CommonName}, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	for _, identity := range identities {
+		if p, ok := s.certs[identity]; ok && identity != "" {
+			return p, nil
+		}
+	}
+	return nil, status.Errorf(codes.Unauthenticated, "client certificate %q is not authorized", cert.Subject.CommonName)
+}
+
+// credential returns the key from an authorization or x-api-key value
+func credential(authorization, apiKey string) string {
+	if authorization != "" {
+		scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+		if ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(apiKey)
+}
+
+type principalKey struct{}
+
+// NewContext returns a context carrying the authenticated principal
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the request's principal, or nil when the request was
+// not authenticated (authentication disabled, or an inbox import)
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Authorize returns a PermissionDenied error when the request's principal may
+// not act for the account. Keys limited to some accounts must name one.
+// Requests without a principal are not restricted.
+func Authorize(ctx context.Context, accountID string) error {
+	p := FromContext(ctx)
+	if p == nil || p.all {
+		return nil
+	}
+	if accountID == "" {
+		return status.Errorf(codes.PermissionDenied, "key %q must name an account_id", p.Name)
+	}
+	if !p.Allows(accountID) {
+		return status.Errorf(codes.PermissionDenied, "key %q may not act for account %q", p.Name, accountID)
+	}
+	return nil
+}
\ No newline at end of file
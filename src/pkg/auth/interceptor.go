@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/grpcstream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator checks the credentials of gRPC and HTTP requests against a
+// store and attaches the principal to the request context. Requests for an
+// account are then authorized by the service with Authorize.
+type Authenticator struct {
+	store  atomic.Pointer[Store]
+	public []string
+}
+
+// NewAuthenticator authenticates every method except the public ones. A
+// public entry is a full method name ("/pkg.Service/Method") or a prefix of
+// one ("/grpc.health.v1.Health/"). A nil *Authenticator lets every request
+// through.
+func NewAuthenticator(store *Store, public ...string) *Authenticator {
+	a := &Authenticator{public: public}
+	a.store.Store(store)
+	return a
+}
+
+// SetStore replaces the keys; requests already authenticated keep their
+// principal
+func (a *Authenticator) SetStore(store *Store) {
+	if a == nil {
+		return
+	}
+	a.store.Store(store)
+}
+
+// UnaryServerInterceptor rejects RPCs without a valid key or client
+// certificate with Unauthenticated
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticateRPC(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateRPC(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, grpcstream.WithContext(ss, ctx))
+	}
+}
+
+// Middleware authenticates HTTP requests from the Authorization or X-Api-Key
+// header, or a verified client certificate. Failures get the JSON error body
+// of the REST gateway.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := a.authenticate(credential(r.Header.Get(AuthorizationHeader), r.Header.Get(APIKeyHeader)), r.TLS)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"code":    codes.Unauthenticated,
+				"message": status.Convert(err).Message(),
+			})
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+	})
+}
+
+func (a *Authenticator) authenticateRPC(ctx context.Context, fullMethod string) (context.Context, error) {
+	if a == nil {
+		return ctx, nil
+	}
+	for _, prefix := range a.public {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return ctx, nil
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	var state *tls.ConnectionState
+	if pr, ok := peer.FromContext(ctx); ok {
+		if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+	p, err := a.authenticate(credential(first(md, AuthorizationHeader), first(md, APIKeyHeader)), state)
+	if err != nil {
+		return ctx, err
+	}
+	return NewContext(ctx, p), nil
+}
+
+// authenticate checks the key, or without one the verified client
+// certificate of a mutual-TLS connection
+func (a *Authenticator) authenticate(key string, state *tls.ConnectionState) (*Principal, error) {
+	if key == "" && state != nil && len(state.VerifiedChains) > 0 {
+		return a.store.Load().AuthenticateCertificate(state.VerifiedChains[0][0])
+	}
+	return a.store.Load().Authenticate(key)
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
\ No newline at end of file
@@ -0,0 +1,191 @@
+package certs
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// ParseVersion returns the TLS version named "1.2" or "1.3"; "" is TLS 1.2
+func ParseVersion(name string) (uint16, error) {
+	switch name {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version: %s (use 1.2 or 1.3)", name)
+}
+
+// Options configures the server side of TLS
+type Options struct {
+	// CertFile and KeyFile hold the PEM certificate chain and private key
+	CertFile string
+	KeyFile  string
+	// Certificate is served instead of CertFile and KeyFile, e.g. one made
+	// by SelfSigned; it is never reloaded
+	Certificate *tls.Certificate
+	// ClientCAFile enables mutual TLS: clients must present a certificate
+	// issued by one of the PEM CAs in this file
+	ClientCAFile string
+	// MinVersion defaults to TLS 1.2
+	MinVersion uint16
+}
+
+// Reloader serves the current certificate and client CAs. The files are
+// checked on every handshake and reloaded when one has changed, so renewed
+// certificates are picked up without a restart. A failed reload keeps the
+// previous certificate.
+type Reloader struct {
+	opts Options
+
+	mu     sync.Mutex
+	stamps map[string]fileStamp
+	config *tls.Config
+}
+
+// fileStamp identifies the version of a file that was loaded
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloader loads the certificate and client CAs
+func NewReloader(opts Options) (*Reloader, error) {
+	if opts.Certificate == nil && (opts.CertFile == "" || opts.KeyFile == "") {
+		return nil, errors.New("certs: a certificate file and key file are required")
+	}
+	if opts.MinVersion == 0 {
+		opts.MinVersion = tls.VersionTLS12
+	}
+	r := &Reloader{opts: opts}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload reads the certificate and client CA files again
+func (r *Reloader) Reload() error {
+	stamps := make(map[string]fileStamp)
+	for _, path := range r.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("certs: %w", err)
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	cert := r.opts.Certificate
+	if cert == nil {
+		loaded, err := tls.LoadX509KeyPair(r.opts.CertFile, r.opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("certs: load key pair: %w", err)
+		}
+		cert = &loaded
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		MinVersion:   r.opts.MinVersion,
+		NextProtos:   []string{"h2"},
+	}
+	if r.opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.opts.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("certs: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("certs: no certificates in %s", r.opts.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.mu.Lock()
+	r.stamps = stamps
+	r.config = config
+	r.mu.Unlock()
+	return nil
+}
+
+// ServerConfig returns the config for a TLS listener or gRPC server
+// credentials. Each handshake uses the current certificate.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.opts.MinVersion,
+		NextProtos: []string{"h2"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current(), nil
+		},
+	}
+}
+
+// LoopbackClientConfig returns the config for connecting to this server from
+// the same process, such as the proxy gateway. It accepts only the certificate
+// the server currently presents and, for mutual TLS, presents that same
+// certificate, so it must then be issued by a client CA and allow client
+// authentication.
+func (r *Reloader) LoopbackClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: r.opts.MinVersion,
+		// The server certificate is pinned in VerifyPeerCertificate instead
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			cert := r.current().Certificates[0]
+			if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], cert.Certificate[0]) {
+				return errors.New("certs: the server did not present its own certificate")
+			}
+			return nil
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert := r.current().Certificates[0]
+			return &cert, nil
+		},
+	}
+}
+
+// current reloads changed files and returns the config to serve
+func (r *Reloader) current() *tls.Config {
+	if r.changed() {
+		if err := r.Reload(); err != nil {
+			slog.Warn("certs: reload failed; keeping the previous certificate", "error", err)
+		} else {
+			slog.Info("certs: reloaded TLS certificates", "cert_file", r.opts.CertFile, "client_ca_file", r.opts.ClientCAFile)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.config
+}
+
+// changed reports whether a file differs from the one last loaded
+func (r *Reloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for path, stamp := range r.stamps {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(stamp.modTime) || info.Size() != stamp.size {
+			return true
+		}
+	}
+	return false
+}
+
+// files lists the files the config is loaded from
+func (r *Reloader) files() []string {
+	var files []string
+	if r.opts.Certificate == nil {
+		files = append(files, r.opts.CertFile, r.opts.KeyFile)
+	}
+	if r.opts.ClientCAFile != "" {
+		files = append(files, r.opts.ClientCAFile)
+	}
+	return files
+}
\ No newline at end of file
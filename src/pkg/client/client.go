@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultTimeout is the deadline of calls whose context has none, unless
+// WithTimeout overrides it
+const DefaultTimeout = 60 * time.Second
+
+// RetryPolicy controls how calls refused with Unavailable or
+// ResourceExhausted are retried. A retry-after hint from the server's rate
+// limit replaces the backoff; a hint longer than MaxBackoff is not waited for.
+//
+// Imports (Process and ProcessFile) are only retried on ResourceExhausted,
+// which the rate limiter returns before anything is saved. Unavailable can
+// arrive after the server saved some rows, so retrying it may import them
+// twice; set RetryImportsOnUnavailable to accept that. Calls that never reach
+// the server are still retried by gRPC itself.
+type RetryPolicy struct {
+	MaxAttempts               int // total attempts, including the first; 1 disables retries
+	InitialBackoff            time.Duration
+	MaxBackoff                time.Duration
+	RetryImportsOnUnavailable bool
+}
+
+// DefaultRetryPolicy retries a refused call up to twice
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// Client calls a DataProcessorService. It is safe for concurrent use.
+type Client struct {
+	conn       *grpc.ClientConn
+	rpc        pb.DataProcessorServiceClient
+	tlsConfig  *tls.Config
+	apiKey     string
+	timeout    time.Duration
+	retry      RetryPolicy
+	chunkBytes int
+	dialOpts   []grpc.DialOption
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithTLS connects over TLS; without it the connection is plaintext
+func WithTLS(config *tls.Config) Option {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithAPIKey sends key in the x-api-key metadata of every call
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTimeout sets the deadline of calls whose context has none. Zero or
+// less leaves such calls without a deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithRetryPolicy overrides DefaultRetryPolicy
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		if p.MaxAttempts < 1 {
+			p.MaxAttempts = 1
+		}
+		c.retry = p
+	}
+}
+
+// WithChunkBytes sets the size above which Validate splits a file into
+// several calls. By default files are sent whole; Process always sends them
+// whole.
+func WithChunkBytes(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.chunkBytes = n
+		}
+	}
+}
+
+// WithDialOptions adds gRPC dial options, e.g. interceptors
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Client) {
+		c.dialOpts = append(c.dialOpts, opts...)
+	}
+}
+
+// New creates a client for the server at target (host:port). The connection
+// is established on the first call.
+func New(target string, opts ...Option) (*Client, error) {
+	if target == "" {
+		return nil, errors.New("target is required")
+	}
+	c := &Client{
+		timeout: DefaultTimeout,
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	creds := insecure.NewCredentials()
+	if c.tlsConfig != nil {
+		creds = credentials.NewTLS(c.tlsConfig)
+	}
+	dialOpts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, c.dialOpts...)
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.rpc = pb.NewDataProcessorServiceClient(conn)
+	return c, nil
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// invoke runs call with the default deadline and API key, retrying it as
+// the retry policy allows. Calls that are not idempotent are retried on
+// Unavailable only when the policy opts in.
+func invoke[Resp any](ctx context.Context, c *Client, idempotent bool, call func(context.Context, ...grpc.CallOption) (Resp, error)) (Resp, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	if c.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, c.apiKey)
+	}
+
+	backoff := c.retry.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		var header metadata.MD
+		resp, err := call(ctx, grpc.Header(&header))
+		if err == nil || attempt >= c.retry.MaxAttempts {
+			return resp, err
+		}
+		switch status.Code(err) {
+		case codes.ResourceExhausted:
+		case codes.Unavailable:
+			if !idempotent && !c.retry.RetryImportsOnUnavailable {
+				return resp, err
+			}
+		default:
+			return resp, err
+		}
+
+		wait := backoff
+		if v := header.Get(ratelimit.RetryAfterHeader); len(v) > 0 {
+			seconds, perr := strconv.Atoi(v[0])
+			if perr == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+			if wait > c.retry.MaxBackoff {
+				return resp, err
+			}
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, c.retry.MaxBackoff)
+	}
+}
\ No newline at end of file
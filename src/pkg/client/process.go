@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+)
+
+// FileOptions describe the file sent to Process or Validate
+type FileOptions struct {
+	AccountID      string
+	SkipDuplicates bool            // Process only
+	Encoding       parser.Encoding // EncodingAuto detects it
+}
+
+// chunk is the part of a file sent in one Validate call
+type chunk struct {
+	data     []byte
+	encoding pb.Encoding
+	firstRow int // data rows of the file before this chunk's
+}
+
+// Process imports data as one import, so duplicates and re-issued rows are
+// matched across the whole file and its failed records share one import ID.
+// The file is never split; data must fit the server's message size.
+// Unavailable is not retried unless the retry policy opts in, since the
+// server may have saved part of the file.
+func (c *Client) Process(ctx context.Context, data []byte, opts FileOptions) (*ProcessResult, error) {
+	req := &pb.ProcessCSVDataRequest{
+		CsvBytes:       data,
+		Encoding:       pb.Encoding(opts.Encoding),
+		AccountId:      opts.AccountID,
+		SkipDuplicates: opts.SkipDuplicates,
+	}
+	resp, err := invoke(ctx, c, false, func(ctx context.Context, callOpts ...grpc.CallOption) (*pb.ProcessCSVDataResponse, error) {
+		return c.rpc.ProcessCSVData(ctx, req, callOpts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProcessResult{
+		Success:           resp.Success,
+		Message:           resp.Message,
+		ImportID:          resp.ImportId,
+		Errors:            recordErrors(resp.RecordErrors, 0, 0),
+		DuplicateStrategy: resp.DuplicateStrategy,
+		RuleSet:           resp.RuleSet,
+	}
+	result.Stats.addStats(resp.Stats)
+	return result, nil
+}
+
+// ProcessFile imports a local file, see Process
+func (c *Client) ProcessFile(ctx context.Context, path string, opts FileOptions) (*ProcessResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Process(ctx, data, opts)
+}
+
+// Validate checks data without saving it. A file larger than the chunk size
+// set with WithChunkBytes is decoded and sent in several calls of whole rows,
+// each repeating the header row, so duplicates and re-issued rows are only
+// found within a chunk. The lines of the returned errors are those of the
+// file.
+func (c *Client) Validate(ctx context.Context, data []byte, opts FileOptions) (*ValidationResult, error) {
+	chunks, err := c.split(data, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ValidationResult{Valid: true}
+	for i, ch := range chunks {
+		req := &pb.ValidateCSVDataRequest{
+			CsvBytes:  ch.data,
+			Encoding:  ch.encoding,
+			AccountId: opts.AccountID,
+		}
+		resp, err := invoke(ctx, c, true, func(ctx context.Context, callOpts ...grpc.CallOption) (*pb.ValidateCSVDataResponse, error) {
+			return c.rpc.ValidateCSVData(ctx, req, callOpts...)
+		})
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			return result, fmt.Errorf("chunk %d of %d: %w", i+1, len(chunks), err)
+		}
+
+		result.Errors = append(result.Errors, recordErrors(resp.RecordErrors, ch.firstRow, result.Total)...)
+		result.Valid = result.Valid && resp.IsValid
+		result.Total += int(resp.TotalRecords)
+		result.Duplicates += int(resp.DuplicateCount)
+		result.Corrections += int(resp.CorrectionCount)
+		result.StatusChanges += int(resp.StatusChangeCount)
+		result.Chunks++
+		result.DuplicateStrategy = resp.DuplicateStrategy
+		result.RuleSet = resp.RuleSet
+	}
+	return result, nil
+}
+
+// ValidateFile checks a local file, see Validate
+func (c *Client) ValidateFile(ctx context.Context, path string, opts FileOptions) (*ValidationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.Validate(ctx, data, opts)
+}
+
+// split returns data as one chunk when no chunk size is set or it fits. Otherwise it
+// decodes data and cuts it at row boundaries into UTF-8 chunks of about the
+// chunk size, each starting with the header row if there is one.
+func (c *Client) split(data []byte, enc parser.Encoding) ([]chunk, error) {
+	if c.chunkBytes <= 0 || len(data) <= c.chunkBytes {
+		return []chunk{{data: data, encoding: pb.Encoding(enc)}}, nil
+	}
+	if enc == parser.EncodingAuto {
+		enc = parser.DetectEncoding(data)
+	}
+	text, err := parser.Decode(data, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(strings.NewReader(text))
+	r.LazyQuotes = true
+	r.FieldsPerRecord = -1
+	var (
+		chunks    []chunk
+		header    string
+		start     int // offset of the current chunk's first row
+		rows      int // data rows before the current chunk
+		chunkRows int
+	)
+	add := func(end int) {
+		chunks = append(chunks, chunk{
+			data:     []byte(header + text[start:end]),
+			encoding: pb.Encoding_ENCODING_UTF8,
+			firstRow: rows,
+		})
+		rows += chunkRows
+		chunkRows = 0
+		start = end
+	}
+	for first := true; ; first = false {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		end := int(r.InputOffset())
+		if first && parser.IsHeaderRow(row) {
+			header, start = text[:end], end
+			continue
+		}
+		chunkRows++
+		if end-start >= c.chunkBytes {
+			add(end)
+		}
+	}
+	if chunkRows > 0 || len(chunks) == 0 {
+		add(len(text))
+	}
+	return chunks, nil
+}
\ No newline at end of file
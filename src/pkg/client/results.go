@@ -0,0 +1,148 @@
+package client
+
+import (
+	"strings"
+	"time"
+
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// Stats counts what happened to the records of an import
+type Stats struct {
+	Total         int `json:"total"`
+	Saved         int `json:"saved"`
+	Skipped       int `json:"skipped"`
+	Failed        int `json:"failed"`
+	Updated       int `json:"updated"` // saves that replaced an already stored row
+	Retried       int `json:"retried"` // records that needed more than one save attempt
+	RetryAttempts int `json:"retry_attempts"`
+	Transient     int `json:"transient"` // failed records whose error was retryable
+	Permanent     int `json:"permanent"`
+	Corrected     int `json:"corrected"`      // re-issued rows with a corrected amount
+	StatusChanges int `json:"status_changes"` // re-issued rows whose notes/status changed
+}
+
+// RecordError is a problem with one record, or with the whole request when
+// RecordIndex is -1
+type RecordError struct {
+	Code        string `json:"code"`     // e.g. INVALID_DATE
+	Severity    string `json:"severity"` // ERROR, WARNING or INFO
+	Line        int    `json:"line"`     // 1-based line in the file, 0 if unknown
+	RecordIndex int    `json:"record_index"`
+	Field       string `json:"field"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+	Retryable   bool   `json:"retryable"`
+}
+
+// ProcessResult is the outcome of Process
+type ProcessResult struct {
+	Success           bool          `json:"success"`
+	Message           string        `json:"message"`
+	ImportID          string        `json:"import_id"` // identifies failed records
+	Stats             Stats         `json:"stats"`
+	Errors            []RecordError `json:"errors"`
+	DuplicateStrategy string        `json:"duplicate_strategy"`
+	RuleSet           string        `json:"rule_set"`
+}
+
+// ValidationResult is the outcome of Validate
+type ValidationResult struct {
+	Valid             bool          `json:"valid"`
+	Total             int           `json:"total"`
+	Duplicates        int           `json:"duplicates"`
+	Corrections       int           `json:"corrections"`
+	StatusChanges     int           `json:"status_changes"`
+	Chunks            int           `json:"chunks"` // calls the file was sent in
+	Errors            []RecordError `json:"errors"`
+	DuplicateStrategy string        `json:"duplicate_strategy"`
+	RuleSet           string        `json:"rule_set"`
+}
+
+// Health is the server's health
+type Health struct {
+	Status  string            `json:"status"` // healthy or unhealthy
+	Version string            `json:"version"`
+	Time    time.Time         `json:"time"`
+	Details map[string]string `json:"details"`
+}
+
+// FailedRecord is a record kept in the server's dead-letter store
+type FailedRecord struct {
+	ID                string    `json:"id"`
+	ImportID          string    `json:"import_id"`
+	AccountID         string    `json:"account_id"`
+	Line              int       `json:"line"`
+	RawRow            string    `json:"raw_row"`
+	Stage             string    `json:"stage"` // conversion or save
+	Error             string    `json:"error"`
+	ErrorClass        string    `json:"error_class"` // transient or permanent
+	ReprocessAttempts int       `json:"reprocess_attempts"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ImportStatus is what is left of an import: the records that failed and
+// have not been reprocessed yet
+type ImportStatus struct {
+	ImportID      string         `json:"import_id"`
+	FailedRecords []FailedRecord `json:"failed_records"`
+	Total         int            `json:"total"`
+}
+
+// addStats adds the counts of a chunk
+func (s *Stats) addStats(p *pb.ProcessingStats) {
+	s.Total += int(p.GetTotalRecords())
+	s.Saved += int(p.GetSavedRecords())
+	s.Skipped += int(p.GetSkippedRecords())
+	s.Failed += int(p.GetErrorRecords())
+	s.Updated += int(p.GetUpdatedRecords())
+	s.Retried += int(p.GetRetriedRecords())
+	s.RetryAttempts += int(p.GetRetryAttempts())
+	s.Transient += int(p.GetTransientErrors())
+	s.Permanent += int(p.GetPermanentErrors())
+	s.Corrected += int(p.GetCorrectedRecords())
+	s.StatusChanges += int(p.GetStatusChanges())
+}
+
+// recordErrors converts the errors of a chunk whose first data row is
+// firstRow rows and firstRecord records into the file
+func recordErrors(errs []*pb.RecordError, firstRow, firstRecord int) []RecordError {
+	var out []RecordError
+	for _, e := range errs {
+		r := RecordError{
+			Code:        strings.TrimPrefix(e.Code.String(), "ERROR_CODE_"),
+			Severity:    strings.TrimPrefix(e.Severity.String(), "SEVERITY_"),
+			Line:        int(e.LineNumber),
+			RecordIndex: int(e.RecordIndex),
+			Field:       e.Field,
+			Rule:        e.Rule,
+			Message:     e.Message,
+			Retryable:   e.Retryable,
+		}
+		if r.Line > 0 {
+			r.Line += firstRow
+		}
+		if r.RecordIndex >= 0 {
+			r.RecordIndex += firstRecord
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func failedRecord(r *pb.FailedRecord) FailedRecord {
+	return FailedRecord{
+		ID:                r.Id,
+		ImportID:          r.ImportId,
+		AccountID:         r.AccountId,
+		Line:              int(r.LineNumber),
+		RawRow:            r.RawRow,
+		Stage:             r.Stage,
+		Error:             r.Error,
+		ErrorClass:        r.ErrorClass,
+		ReprocessAttempts: int(r.ReprocessAttempts),
+		CreatedAt:         time.Unix(r.CreatedAt, 0),
+		UpdatedAt:         time.Unix(r.UpdatedAt, 0),
+	}
+}
\ No newline at end of file
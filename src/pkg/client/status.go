@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+)
+
+// Health returns the server's health. An unhealthy server is not an error.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	resp, err := invoke(ctx, c, true, func(ctx context.Context, callOpts ...grpc.CallOption) (*pb.HealthCheckResponse, error) {
+		return c.rpc.HealthCheck(ctx, &pb.HealthCheckRequest{}, callOpts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Health{
+		Status:  resp.Status,
+		Version: resp.Version,
+		Time:    time.Unix(resp.Timestamp, 0),
+		Details: resp.Details,
+	}, nil
+}
+
+// ImportStatus returns the failed records of an import that are still in
+// the dead-letter store; with no importID, those of every import of the
+// account. The server needs a dead-letter store for this.
+func (c *Client) ImportStatus(ctx context.Context, accountID, importID string) (*ImportStatus, error) {
+	resp, err := invoke(ctx, c, true, func(ctx context.Context, callOpts ...grpc.CallOption) (*pb.ListFailedRecordsResponse, error) {
+		return c.rpc.ListFailedRecords(ctx, &pb.ListFailedRecordsRequest{AccountId: accountID, ImportId: importID}, callOpts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	st := &ImportStatus{ImportID: importID, Total: int(resp.TotalCount)}
+	for _, r := range resp.Records {
+		st.FailedRecords = append(st.FailedRecords, failedRecord(r))
+	}
+	return st, nil
+}
\ No newline at end of file
@@ -0,0 +1,133 @@
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore keeps each failed record as a JSON file in a directory,
+// so failures survive restarts and can be replayed later. Records hold card
+// numbers and raw rows, so files are readable by the owner only.
+type FileStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileStore creates a file-backed store, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("dead-letter directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Add stores a failed record, assigning an ID and timestamps if missing
+func (f *FileStore) Add(rec FailedRecord) (FailedRecord, error) {
+	rec = prepare(rec)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.write(rec); err != nil {
+		return FailedRecord{}, err
+	}
+	return rec, nil
+}
+
+// Get returns a failed record by ID
+func (f *FileStore) Get(id string) (FailedRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.read(f.path(id))
+}
+
+// List returns failed records matching the filter, oldest first
+func (f *FileStore) List(filter Filter) ([]FailedRecord, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter directory: %w", err)
+	}
+
+	var result []FailedRecord
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		rec, err := f.read(filepath.Join(f.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if filter.Matches(rec) {
+			result = append(result, rec)
+		}
+	}
+	return sortAndLimit(result, filter.Limit), nil
+}
+
+// Update replaces an existing failed record
+func (f *FileStore) Update(rec FailedRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := os.Stat(f.path(rec.ID)); err != nil {
+		return fmt.Errorf("failed record not found: %s", rec.ID)
+	}
+	rec.UpdatedAt = time.Now()
+	return f.write(rec)
+}
+
+// Delete removes a failed record
+func (f *FileStore) Delete(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.Remove(f.path(id)); err != nil {
+		return fmt.Errorf("failed record not found: %s", id)
+	}
+	return nil
+}
+
+// path returns the file path for a record ID
+func (f *FileStore) path(id string) string {
+	return filepath.Join(f.dir, filepath.Base(id)+".json")
+}
+
+// read loads a record from a JSON file
+func (f *FileStore) read(path string) (FailedRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FailedRecord{}, fmt.Errorf("failed record not found: %s", strings.TrimSuffix(filepath.Base(path), ".json"))
+	}
+
+	var rec FailedRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return FailedRecord{}, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// write saves a record atomically via a temporary file
+func (f *FileStore) write(rec FailedRecord) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failed record: %w", err)
+	}
+
+	tmp := f.path(rec.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write failed record: %w", err)
+	}
+	return os.Rename(tmp, f.path(rec.ID))
+}
\ No newline at end of file
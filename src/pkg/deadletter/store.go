@@ -0,0 +1,181 @@
+package deadletter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// Stage identifies where in the pipeline a record failed
+const (
+	StageValidation = "validation"
+	StageConversion = "conversion"
+	StageSave       = "save"
+)
+
+// FailedRecord is a record that could not be converted or saved
+type FailedRecord struct {
+	ID                string                 `json:"id"`
+	ImportID          string                 `json:"import_id"`
+	AccountID         string                 `json:"account_id"`
+	LineNumber        int                    `json:"line_number"`
+	RawRow            string                 `json:"raw_row"`
+	MatchKey          string                 `json:"match_key,omitempty"`
+	Record            parser.ActualETCRecord `json:"record"`
+	Stage             string                 `json:"stage"`
+	Error             string                 `json:"error"`
+	ErrorClass        string                 `json:"error_class"`
+	ReprocessAttempts int                    `json:"reprocess_attempts"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// Filter selects failed records. Empty fields match everything.
+type Filter struct {
+	AccountID string
+	ImportID  string
+	IDs       []string
+	Limit     int
+}
+
+// Matches reports whether a record satisfies the filter (ignoring Limit)
+func (f Filter) Matches(rec FailedRecord) bool {
+	if f.AccountID != "" && rec.AccountID != f.AccountID {
+		return false
+	}
+	if f.ImportID != "" && rec.ImportID != f.ImportID {
+		return false
+	}
+	if len(f.IDs) > 0 {
+		for _, id := range f.IDs {
+			if id == rec.ID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Store persists failed records so they can be inspected and replayed
+type Store interface {
+	Add(rec FailedRecord) (FailedRecord, error)
+	Get(id string) (FailedRecord, error)
+	List(filter Filter) ([]FailedRecord, error)
+	Update(rec FailedRecord) error
+	Delete(id string) error
+}
+
+// NewID returns a random identifier for failed records and imports
+func NewID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// MemoryStore is an in-memory Store for tests and short-lived processes. It
+// is unbounded, so long-running servers should use a FileStore.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]FailedRecord
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]FailedRecord)}
+}
+
+// Add stores a failed record, assigning an ID and timestamps if missing
+func (m *MemoryStore) Add(rec FailedRecord) (FailedRecord, error) {
+	rec = prepare(rec)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[rec.ID] = rec
+	return rec, nil
+}
+
+// Get returns a failed record by ID
+func (m *MemoryStore) Get(id string) (FailedRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rec, ok := m.records[id]
+	if !ok {
+		return FailedRecord{}, fmt.Errorf("failed record not found: %s", id)
+	}
+	return rec, nil
+}
+
+// List returns failed records matching the filter, oldest first
+func (m *MemoryStore) List(filter Filter) ([]FailedRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []FailedRecord
+	for _, rec := range m.records {
+		if filter.Matches(rec) {
+			result = append(result, rec)
+		}
+	}
+	return sortAndLimit(result, filter.Limit), nil
+}
+
+// Update replaces an existing failed record
+func (m *MemoryStore) Update(rec FailedRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[rec.ID]; !ok {
+		return fmt.Errorf("failed record not found: %s", rec.ID)
+	}
+	rec.UpdatedAt = time.Now()
+	m.records[rec.ID] = rec
+	return nil
+}
+
+// Delete removes a failed record
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.records[id]; !ok {
+		return fmt.Errorf("failed record not found: %s", id)
+	}
+	delete(m.records, id)
+	return nil
+}
+
+// prepare fills in the ID and timestamps of a new record
+func prepare(rec FailedRecord) FailedRecord {
+	if rec.ID == "" {
+		rec.ID = NewID()
+	}
+	now := time.Now()
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = now
+	}
+	rec.UpdatedAt = now
+	return rec
+}
+
+// sortAndLimit orders records by creation time and applies the limit
+func sortAndLimit(records []FailedRecord, limit int) []FailedRecord {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].CreatedAt.Equal(records[j].CreatedAt) {
+			return records[i].LineNumber < records[j].LineNumber
+		}
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records
+}
\ No newline at end of file
@@ -0,0 +1,162 @@
+package dedup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// MatchKind classifies how a re-issued row relates to an earlier one
+type MatchKind int
+
+const (
+	// MatchNone means the rows describe different trips
+	MatchNone MatchKind = iota
+	// MatchDuplicate means the same trip with the same amount and notes
+	MatchDuplicate
+	// MatchCorrection means the same trip with a corrected amount
+	MatchCorrection
+	// MatchStatusChange means the same trip and amount with different notes (e.g. 確定)
+	MatchStatusChange
+)
+
+// String returns the match kind name
+func (k MatchKind) String() string {
+	switch k {
+	case MatchDuplicate:
+		return "duplicate"
+	case MatchCorrection:
+		return "correction"
+	case MatchStatusChange:
+		return "status_change"
+	default:
+		return "none"
+	}
+}
+
+// DefaultFuzzyWindow is the exit-time tolerance used when none is configured
+const DefaultFuzzyWindow = 5 * time.Minute
+
+// FuzzyMatcher detects rows that the card issuer re-issued for the same trip:
+// same card and exit IC, with exit times within Window of each other
+type FuzzyMatcher struct {
+	Window time.Duration
+}
+
+// NewFuzzyMatcher creates a matcher. A non-positive window selects DefaultFuzzyWindow.
+func NewFuzzyMatcher(window time.Duration) *FuzzyMatcher {
+	if window <= 0 {
+		window = DefaultFuzzyWindow
+	}
+	return &FuzzyMatcher{Window: window}
+}
+
+// Classify compares a later row against an earlier one
+func (m *FuzzyMatcher) Classify(earlier, later parser.ActualETCRecord) MatchKind {
+	if earlier.CardNumber != later.CardNumber || earlier.ExitIC != later.ExitIC {
+		return MatchNone
+	}
+
+	t1, err1 := ExitTime(earlier)
+	t2, err2 := ExitTime(later)
+	if err1 != nil || err2 != nil {
+		// Without usable times only an exact exit timestamp can match
+		if earlier.ExitDate != later.ExitDate || earlier.ExitTime != later.ExitTime {
+			return MatchNone
+		}
+	} else if d := t2.Sub(t1); d > m.Window || d < -m.Window {
+		return MatchNone
+	}
+
+	switch {
+	case earlier.ETCAmount != later.ETCAmount:
+		return MatchCorrection
+	case strings.TrimSpace(earlier.Notes) != strings.TrimSpace(later.Notes):
+		return MatchStatusChange
+	default:
+		return MatchDuplicate
+	}
+}
+
+// MatchKey identifies the trip a row belongs to, so a re-issued row can replace
+// the stored one. It is built from the card, exit IC and exit timestamp.
+func MatchKey(r parser.ActualETCRecord) string {
+	return joinKey(r.CardNumber, r.ExitIC, r.ExitDate, r.ExitTime)
+}
+
+// ExitTime parses the exit date ("YY/MM/DD" or "YYYY/MM/DD") and time ("HH:MM")
+func ExitTime(r parser.ActualETCRecord) (time.Time, error) {
+	parts := strings.Split(strings.TrimSpace(r.ExitDate), "/")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid exit date: %s", r.ExitDate)
+	}
+
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid exit date: %s", r.ExitDate)
+		}
+		nums[i] = n
+	}
+	if nums[0] < 100 {
+		nums[0] += 2000
+	}
+
+	clock, err := time.Parse("15:04", strings.TrimSpace(r.ExitTime))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid exit time: %s", r.ExitTime)
+	}
+
+	return time.Date(nums[0], time.Month(nums[1]), nums[2], clock.Hour(), clock.Minute(), 0, 0, time.UTC), nil
+}
+
+// Match describes how a row relates to earlier rows of the same import
+type Match struct {
+	Index int       // index of the matched row, or of the row itself when Kind is MatchNone
+	Kind  MatchKind // how the rows relate
+	Key   string    // match key the trip is stored under
+}
+
+// FuzzyIndex tracks the latest row seen for each trip within one import
+type FuzzyIndex struct {
+	matcher *FuzzyMatcher
+	rows    map[string][]fuzzyEntry
+}
+
+type fuzzyEntry struct {
+	index  int
+	key    string
+	record parser.ActualETCRecord
+}
+
+// NewFuzzyIndex creates an index for the given matcher
+func NewFuzzyIndex(matcher *FuzzyMatcher) *FuzzyIndex {
+	return &FuzzyIndex{matcher: matcher, rows: make(map[string][]fuzzyEntry)}
+}
+
+// Observe compares a row with earlier rows of the same card and exit IC.
+// A correction or status change replaces the matched row, keeping its match
+// key, so that later re-issues of the same trip chain onto one stored row.
+func (x *FuzzyIndex) Observe(r parser.ActualETCRecord, index int) Match {
+	bucket := joinKey(r.CardNumber, r.ExitIC)
+	entries := x.rows[bucket]
+
+	for i, entry := range entries {
+		kind := x.matcher.Classify(entry.record, r)
+		if kind == MatchNone {
+			continue
+		}
+		if kind != MatchDuplicate {
+			entries[i] = fuzzyEntry{index: index, key: entry.key, record: r}
+		}
+		return Match{Index: entry.index, Kind: kind, Key: entry.key}
+	}
+
+	key := MatchKey(r)
+	x.rows[bucket] = append(entries, fuzzyEntry{index: index, key: key, record: r})
+	return Match{Index: index, Kind: MatchNone, Key: key}
+}
\ No newline at end of file
@@ -0,0 +1,155 @@
+package dedup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// Strategy selects which columns make two records duplicates
+type Strategy string
+
+const (
+	// StrategyExactRow treats records as duplicates only when every column matches
+	StrategyExactRow Strategy = "exact_row"
+	// StrategyCardExitAmount matches on card number, exit date/time and amount
+	StrategyCardExitAmount Strategy = "card_exit_amount"
+	// StrategyIgnoreStatus matches every column except the notes/status column (備考)
+	StrategyIgnoreStatus Strategy = "ignore_status"
+
+	// DefaultStrategy is used when no strategy is configured
+	DefaultStrategy = StrategyCardExitAmount
+)
+
+// ParseStrategy validates a strategy name. An empty name selects DefaultStrategy.
+func ParseStrategy(name string) (Strategy, error) {
+	switch Strategy(name) {
+	case "":
+		return DefaultStrategy, nil
+	case StrategyExactRow, StrategyCardExitAmount, StrategyIgnoreStatus:
+		return Strategy(name), nil
+	default:
+		return "", fmt.Errorf("unknown duplicate strategy: %s", name)
+	}
+}
+
+// Policy builds duplicate keys for records
+type Policy struct {
+	Strategy Strategy
+}
+
+// NewPolicy creates a policy for the named strategy
+func NewPolicy(name string) (*Policy, error) {
+	strategy, err := ParseStrategy(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{Strategy: strategy}, nil
+}
+
+// Key returns the duplicate key of a record under the policy's strategy
+func (p *Policy) Key(r parser.ActualETCRecord) string {
+	switch p.Strategy {
+	case StrategyExactRow:
+		return joinKey(allColumns(r)...)
+	case StrategyIgnoreStatus:
+		cols := allColumns(r)
+		return joinKey(cols[:len(cols)-1]...)
+	default:
+		return joinKey(r.CardNumber, r.ExitDate, r.ExitTime, strconv.Itoa(r.ETCAmount))
+	}
+}
+
+// Fuzzy reports whether re-issued rows may be matched to earlier rows of the
+// same trip. Under exact_row only identical rows are the same trip.
+func (p *Policy) Fuzzy() bool {
+	return p.Strategy != StrategyExactRow
+}
+
+// MatchKey returns the key a record's trip is stored under: the row itself
+// under exact_row, so differing rows are never upserted over each other, and
+// the package MatchKey otherwise
+func (p *Policy) MatchKey(r parser.ActualETCRecord) string {
+	if p.Strategy == StrategyExactRow {
+		return p.Key(r)
+	}
+	return MatchKey(r)
+}
+
+// Tracker remembers which keys have been seen within one import
+type Tracker struct {
+	policy *Policy
+	seen   map[string]int
+}
+
+// NewTracker creates a tracker for the given policy
+func NewTracker(policy *Policy) *Tracker {
+	return &Tracker{policy: policy, seen: make(map[string]int)}
+}
+
+// Observe records a record at the given index. It returns whether an earlier
+// record had the same key, and the index of that first record.
+func (t *Tracker) Observe(r parser.ActualETCRecord, index int) (int, bool) {
+	key := t.policy.Key(r)
+	if first, ok := t.seen[key]; ok {
+		return first, true
+	}
+	t.seen[key] = index
+	return index, false
+}
+
+// PolicySet resolves the policy for an account, falling back to a default
+type PolicySet struct {
+	Default  *Policy
+	Accounts map[string]*Policy
+}
+
+// NewPolicySet creates a policy set from strategy names
+func NewPolicySet(defaultStrategy string, accounts map[string]string) (*PolicySet, error) {
+	def, err := NewPolicy(defaultStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &PolicySet{Default: def, Accounts: make(map[string]*Policy)}
+	for accountID, name := range accounts {
+		policy, err := NewPolicy(name)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", accountID, err)
+		}
+		set.Accounts[accountID] = policy
+	}
+	return set, nil
+}
+
+// DefaultPolicySet returns a set that uses DefaultStrategy for every account
+func DefaultPolicySet() *PolicySet {
+	return &PolicySet{Default: &Policy{Strategy: DefaultStrategy}}
+}
+
+// For returns the policy for an account
+func (s *PolicySet) For(accountID string) *Policy {
+	if policy, ok := s.Accounts[accountID]; ok {
+		return policy
+	}
+	return s.Default
+}
+
+// allColumns lists every CSV column of a record, with the notes column last
+func allColumns(r parser.ActualETCRecord) []string {
+	return []string{
+		r.EntryDate, r.EntryTime, r.ExitDate, r.ExitTime,
+		r.EntryIC, r.ExitIC, r.RouteInfo,
+		strconv.Itoa(r.ETCAmount), strconv.Itoa(r.NormalAmount),
+		strconv.Itoa(r.DiscountApplied), strconv.Itoa(r.Mileage),
+		strconv.Itoa(r.VehicleClass), r.VehicleNumber, r.CardNumber,
+		r.Notes,
+	}
+}
+
+// joinKey joins key parts with a separator that cannot appear in CSV text
+func joinKey(parts ...string) string {
+	return strings.Join(parts, "\x1f")
+}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Apply sets the fields of v, a pointer to a struct, from the variables in
+// environ ("NAME=value", as from os.Environ), so every config file setting
+// can also be set from the environment. A field's variable is the prefix and
+// the yaml tags of the field's path, upper-cased and joined with "_":
+// PREFIX_GATEWAY_PORT for gateway.port. Strings, bools and numbers are parsed
+// as such; lists of strings are comma-separated; maps and lists of structs are
+// YAML or JSON. Unknown variables with the prefix and values that do not parse
+// are errors naming the variable.
+func Apply(v interface{}, prefix string, environ []string) error {
+	prefix = strings.ToUpper(prefix)
+	fields := map[string]reflect.Value{}
+	collect(reflect.ValueOf(v).Elem(), prefix, fields)
+
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, prefix+"_") {
+			continue
+		}
+		field, known := fields[name]
+		if !known {
+			return fmt.Errorf("unknown environment variable %s", name)
+		}
+		if err := set(field, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// collect maps the variable name of every settable leaf field under v
+func collect(v reflect.Value, name string, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if !f.IsExported() || tag == "-" || tag == "" {
+			continue
+		}
+		fieldName := name + "_" + strings.ToUpper(tag)
+		if f.Type.Kind() == reflect.Struct {
+			collect(v.Field(i), fieldName, fields)
+			continue
+		}
+		fields[fieldName] = v.Field(i)
+	}
+}
+
+// set parses value into the field
+func set(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("not a boolean: %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("not an integer: %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("not an unsigned integer: %q", value)
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("not a number: %q", value)
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var list []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			field.Set(reflect.ValueOf(list).Convert(field.Type()))
+			return nil
+		}
+		return setYAML(field, value)
+	default:
+		return setYAML(field, value)
+	}
+	return nil
+}
+
+// setYAML replaces the field with value decoded as YAML, which includes JSON
+func setYAML(field reflect.Value, value string) error {
+	decoded := reflect.New(field.Type())
+	if err := yaml.Unmarshal([]byte(value), decoded.Interface()); err != nil {
+		return fmt.Errorf("not valid YAML or JSON for %s: %v", field.Type(), err)
+	}
+	field.Set(decoded.Elem())
+	return nil
+}
\ No newline at end of file
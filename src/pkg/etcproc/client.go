@@ -0,0 +1,366 @@
+package etcproc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/client"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// APIKeyEnv is the variable the client commands read an API key from when
+// -api-key is not given
+const APIKeyEnv = "ETCPROC_API_KEY"
+
+const clientUsage = `Usage: etcproc client <command> [flags] [file]
+
+Calls a running server.
+
+Commands:
+  process   import a file
+  validate  validate a file without saving it
+  status    show the server health and, with -account, the failed records
+            of an import still in the dead-letter store
+`
+
+// connFlags select the server and how to authenticate to it
+type connFlags struct {
+	addr       *string
+	apiKey     *string
+	tls        *bool
+	ca         *string
+	cert       *string
+	key        *string
+	serverName *string
+	timeout    *time.Duration
+	chunkBytes *int
+}
+
+// addConnFlags adds the flags shared by the client commands
+func addConnFlags(fs *flag.FlagSet) *connFlags {
+	return &connFlags{
+		addr:       fs.String("addr", "localhost:50051", "server gRPC address"),
+		apiKey:     fs.String("api-key", "", "API key (default: $"+APIKeyEnv+")"),
+		tls:        fs.Bool("tls", false, "connect over TLS; implied by -ca"),
+		ca:         fs.String("ca", "", "CA certificate file to verify the server with (default: system roots)"),
+		cert:       fs.String("cert", "", "client certificate file, for mutual TLS"),
+		key:        fs.String("key", "", "client key file, for mutual TLS"),
+		serverName: fs.String("server-name", "", "server name to verify instead of the -addr host"),
+		timeout:    fs.Duration("timeout", client.DefaultTimeout, "deadline of each call"),
+		chunkBytes: fs.Int("chunk-bytes", 0, "validate: send files larger than this in several calls (0: whole)"),
+	}
+}
+
+// dial creates a client from the flags
+func (c *command) dial(f *connFlags) (*client.Client, error) {
+	key := *f.apiKey
+	if key == "" {
+		key = lookupEnv(c.environ, APIKeyEnv)
+	}
+	opts := []client.Option{
+		client.WithAPIKey(key),
+		client.WithTimeout(*f.timeout),
+		client.WithChunkBytes(*f.chunkBytes),
+	}
+	if *f.tls || *f.ca != "" || *f.cert != "" {
+		config, err := f.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLS(config))
+	}
+	return client.New(*f.addr, opts...)
+}
+
+// tlsConfig builds the client TLS config from the flags
+func (f *connFlags) tlsConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: *f.serverName}
+	if *f.ca != "" {
+		pem, err := os.ReadFile(*f.ca)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", *f.ca)
+		}
+	}
+	if (*f.cert == "") != (*f.key == "") {
+		return nil, errors.New("-cert and -key must be given together")
+	}
+	if *f.cert != "" {
+		cert, err := tls.LoadX509KeyPair(*f.cert, *f.key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// client runs a client command
+func (c *command) client(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, clientUsage)
+		return ExitUsage
+	}
+	switch args[0] {
+	case "process":
+		return c.clientProcess(args[1:])
+	case "validate":
+		return c.clientValidate(args[1:])
+	case "status":
+		return c.clientStatus(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(c.stdout, clientUsage)
+		return ExitOK
+	default:
+		fmt.Fprintf(c.stderr, "etcproc client: unknown command %q\n\n%s", args[0], clientUsage)
+		return ExitUsage
+	}
+}
+
+// clientProcess imports a file through the server. The exit code is
+// ExitInvalid when any record failed.
+func (c *command) clientProcess(args []string) int {
+	fs := c.flags("client process", "<file>")
+	conn := addConnFlags(fs)
+	enc := encodingFlag(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	account := fs.String("account", "", "account to import for")
+	skipDuplicates := fs.Bool("skip-duplicates", false, "skip rows that duplicate earlier rows instead of failing them")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	opts, code, ok := c.fileOptions("client process", *format, *enc, *account)
+	if !ok {
+		return code
+	}
+	opts.SkipDuplicates = *skipDuplicates
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("client process", ExitError, err)
+	}
+	cl, err := c.dial(conn)
+	if err != nil {
+		return c.fail("client process", ExitError, err)
+	}
+	defer cl.Close()
+
+	res, err := cl.Process(context.Background(), data, opts)
+	if res != nil {
+		if *format == "json" {
+			c.writeJSON(res)
+		} else {
+			st := res.Stats
+			fmt.Fprintf(c.stdout, "%s\n", res.Message)
+			fmt.Fprintf(c.stdout, "total %d, saved %d, skipped %d, failed %d, updated %d\n",
+				st.Total, st.Saved, st.Skipped, st.Failed, st.Updated)
+			c.printRecordErrors(res.Errors)
+			if res.ImportID != "" && st.Failed > 0 {
+				fmt.Fprintf(c.stdout, "failed records: import %s\n", res.ImportID)
+			}
+		}
+	}
+	if err != nil {
+		return c.fail("client process", ExitError, err)
+	}
+	if !res.Success || res.Stats.Failed > 0 {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// clientValidate validates a file on the server. The exit code is
+// ExitInvalid when the data is not valid.
+func (c *command) clientValidate(args []string) int {
+	fs := c.flags("client validate", "<file>")
+	conn := addConnFlags(fs)
+	enc := encodingFlag(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	account := fs.String("account", "", "account whose rules and duplicate strategy apply")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	opts, code, ok := c.fileOptions("client validate", *format, *enc, *account)
+	if !ok {
+		return code
+	}
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("client validate", ExitError, err)
+	}
+	cl, err := c.dial(conn)
+	if err != nil {
+		return c.fail("client validate", ExitError, err)
+	}
+	defer cl.Close()
+
+	res, err := cl.Validate(context.Background(), data, opts)
+	if err != nil {
+		return c.fail("client validate", ExitError, err)
+	}
+	if *format == "json" {
+		c.writeJSON(res)
+	} else {
+		c.printRecordErrors(res.Errors)
+		result := "valid"
+		if !res.Valid {
+			result = "invalid"
+		}
+		fmt.Fprintf(c.stdout, "%s: %d records, %d duplicates (%s)", result, res.Total, res.Duplicates, res.DuplicateStrategy)
+		if res.RuleSet != "" {
+			fmt.Fprintf(c.stdout, ", rule set %s", res.RuleSet)
+		}
+		fmt.Fprintln(c.stdout)
+	}
+	if !res.Valid {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// serverStatus is the JSON output of client status
+type serverStatus struct {
+	Health *client.Health       `json:"health"`
+	Import *client.ImportStatus `json:"import,omitempty"`
+}
+
+// clientStatus shows the server health and the failed records of an import.
+// The exit code is ExitInvalid when the server is unhealthy or records of
+// the import failed.
+func (c *command) clientStatus(args []string) int {
+	fs := c.flags("client status", "")
+	conn := addConnFlags(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	account := fs.String("account", "", "account whose failed records are listed")
+	importID := fs.String("import", "", "only list the failed records of this import; needs -account")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if fs.NArg() != 0 {
+		fmt.Fprintf(c.stderr, "etcproc client status: unexpected arguments %q\n", fs.Args())
+		fs.Usage()
+		return ExitUsage
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return c.fail("client status", ExitUsage, err)
+	}
+	if *importID != "" && *account == "" {
+		return c.fail("client status", ExitUsage, errors.New("-import needs -account"))
+	}
+	cl, err := c.dial(conn)
+	if err != nil {
+		return c.fail("client status", ExitError, err)
+	}
+	defer cl.Close()
+
+	ctx := context.Background()
+	var out serverStatus
+	if out.Health, err = cl.Health(ctx); err != nil {
+		return c.fail("client status", ExitError, err)
+	}
+	if *account != "" {
+		if out.Import, err = cl.ImportStatus(ctx, *account, *importID); err != nil {
+			return c.fail("client status", ExitError, err)
+		}
+	}
+
+	if *format == "json" {
+		c.writeJSON(out)
+	} else {
+		h := out.Health
+		fmt.Fprintf(c.stdout, "server:  %s (version %s)\n", h.Status, h.Version)
+		for _, name := range slices.Sorted(maps.Keys(h.Details)) {
+			fmt.Fprintf(c.stdout, "  %s: %s\n", name, h.Details[name])
+		}
+		if out.Import != nil {
+			c.printFailedRecords(out.Import)
+		}
+	}
+	if out.Health.Status != "healthy" || (out.Import != nil && out.Import.Total > 0) {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// fileOptions checks the flags shared by process and validate
+func (c *command) fileOptions(name, format, enc, account string) (client.FileOptions, int, bool) {
+	if err := checkFormat(format, "text", "json"); err != nil {
+		return client.FileOptions{}, c.fail(name, ExitUsage, err), false
+	}
+	if account == "" {
+		return client.FileOptions{}, c.fail(name, ExitUsage, errors.New("-account is required")), false
+	}
+	encoding, err := parser.ParseEncoding(enc)
+	if err != nil {
+		return client.FileOptions{}, c.fail(name, ExitUsage, err), false
+	}
+	return client.FileOptions{AccountID: account, Encoding: encoding}, ExitOK, true
+}
+
+// printRecordErrors prints record errors as a table
+func (c *command) printRecordErrors(errs []client.RecordError) {
+	if len(errs) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tSEVERITY\tCODE\tFIELD\tRULE\tMESSAGE")
+	for _, e := range errs {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", e.Line, e.Severity, e.Code, e.Field, e.Rule, e.Message)
+	}
+	tw.Flush()
+	fmt.Fprintln(c.stdout)
+}
+
+// printFailedRecords prints the failed records of an import as a table
+func (c *command) printFailedRecords(st *client.ImportStatus) {
+	name := "all imports"
+	if st.ImportID != "" {
+		name = "import " + st.ImportID
+	}
+	fmt.Fprintf(c.stdout, "failed records (%s): %d\n", name, st.Total)
+	if len(st.FailedRecords) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "  ID\tIMPORT\tLINE\tSTAGE\tCLASS\tATTEMPTS\tERROR")
+	for _, r := range st.FailedRecords {
+		fmt.Fprintf(tw, "  %s\t%s\t%d\t%s\t%s\t%d\t%s\n",
+			r.ID, r.ImportID, r.Line, r.Stage, r.ErrorClass, r.ReprocessAttempts, r.Error)
+	}
+	tw.Flush()
+}
+
+// writeJSON writes v as indented JSON
+func (c *command) writeJSON(v interface{}) {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// lookupEnv returns the value of name in environ
+func lookupEnv(environ []string, name string) string {
+	for _, kv := range environ {
+		if v, ok := strings.CutPrefix(kv, name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
\ No newline at end of file
@@ -0,0 +1,95 @@
+package etcproc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// convert writes the parsed records in canonical form: UTF-8, one column or
+// key per field named as in validation rules, plus the source line number
+func (c *command) convert(args []string) int {
+	fs := c.flags("convert", "<file>")
+	enc := encodingFlag(fs)
+	to := fs.String("to", "csv", "output format: csv or jsonl")
+	output := fs.String("o", "", "output file (default: standard output)")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if err := checkFormat(*to, "csv", "jsonl"); err != nil {
+		return c.fail("convert", ExitUsage, err)
+	}
+	encoding, err := parser.ParseEncoding(*enc)
+	if err != nil {
+		return c.fail("convert", ExitUsage, err)
+	}
+
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("convert", ExitError, err)
+	}
+	text, err := parser.Decode(data, encoding)
+	if err != nil {
+		return c.fail("convert", ExitError, err)
+	}
+	records, err := parser.NewETCCSVParser().Parse(strings.NewReader(text))
+	if err != nil {
+		return c.fail("convert", ExitError, err)
+	}
+
+	w := c.stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return c.fail("convert", ExitError, err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if *to == "jsonl" {
+		err = writeJSONL(w, records)
+	} else {
+		err = writeCSV(w, records)
+	}
+	if err != nil {
+		return c.fail("convert", ExitError, err)
+	}
+	return ExitOK
+}
+
+// writeCSV writes a header row of the field names and one row per record
+func writeCSV(w io.Writer, records []parser.ActualETCRecord) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"line_number"}, parser.Fields...))
+	for _, r := range records {
+		row := []string{strconv.Itoa(r.LineNumber)}
+		for _, v := range r.FieldValues() {
+			row = append(row, fmt.Sprint(v))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeJSONL writes one JSON object per record, keyed by field name
+func writeJSONL(w io.Writer, records []parser.ActualETCRecord) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		obj := map[string]interface{}{"line_number": r.LineNumber}
+		for i, v := range r.FieldValues() {
+			obj[parser.Fields[i]] = v
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,149 @@
+package etcproc
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes returned by Run, for use in scripts
+const (
+	ExitOK      = 0 // the command succeeded and the data has no errors
+	ExitInvalid = 1 // the data has validation errors or records failed to import
+	ExitUsage   = 2 // the command line is wrong
+	ExitError   = 3 // the input or configuration could not be read, or the import failed
+)
+
+const usage = `Usage: etcproc <command> [flags] <file>
+
+Checks and imports ETC statement CSV files without a server, or calls a
+running one. <file> may be "-" to read standard input.
+
+Commands:
+  inspect   show the detected encoding, layout and column mapping
+  validate  apply the validation rules and duplicate checks
+  convert   write the records as canonical UTF-8 CSV or JSON lines
+  import    process the records and save them to a DB client
+  client    process, validate or check the status of imports on a server
+
+Exit codes: 0 ok, 1 invalid data or failed records, 2 usage, 3 error.
+Run "etcproc <command> -h" for the flags of a command.
+`
+
+// command is one invocation of the CLI
+type command struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	environ        []string
+}
+
+// Run runs the command line args (without the program name) and returns the
+// exit code. Settings read from a config file can be overridden by
+// ETC_PROCESSOR_* variables in environ, as for the server.
+func Run(args []string, stdin io.Reader, stdout, stderr io.Writer, environ []string) int {
+	c := &command{stdin: stdin, stdout: stdout, stderr: stderr, environ: environ}
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+
+	var run func([]string) int
+	switch args[0] {
+	case "inspect":
+		run = c.inspect
+	case "validate":
+		run = c.validate
+	case "convert":
+		run = c.convert
+	case "import":
+		run = c.importFile
+	case "client":
+		run = c.client
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return ExitOK
+	default:
+		fmt.Fprintf(stderr, "etcproc: unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+	return run(args[1:])
+}
+
+// flags creates the flag set of a subcommand
+func (c *command) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: etcproc %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses a subcommand's flags and returns its one file argument,
+// or the exit code when the command line is wrong or help was asked for
+func (c *command) parseFlags(fs *flag.FlagSet, args []string) (string, int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return "", ExitOK, false
+		}
+		return "", ExitUsage, false
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintf(c.stderr, "etcproc %s: expected one file, got %d arguments\n", fs.Name(), fs.NArg())
+		fs.Usage()
+		return "", ExitUsage, false
+	}
+	return fs.Arg(0), ExitOK, true
+}
+
+// fail reports an error and returns code
+func (c *command) fail(name string, code int, err error) int {
+	if st, ok := status.FromError(err); ok {
+		err = errors.New(st.Message())
+	}
+	fmt.Fprintf(c.stderr, "etcproc %s: %v\n", name, err)
+	return code
+}
+
+// readInput reads a file, or standard input for "-"
+func (c *command) readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(c.stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// encodingFlag adds the -encoding flag
+func encodingFlag(fs *flag.FlagSet) *string {
+	return fs.String("encoding", "auto", "input encoding: auto, shift_jis, utf8 or utf16")
+}
+
+// loadConfig layers an optional config file and the environment like the
+// server does, and validates the result
+func (c *command) loadConfig(file string) (*config.Config, error) {
+	cfg, err := config.Load(file, c.environ)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// checkFormat reports a -format value that is not one of allowed
+func checkFormat(format string, allowed ...string) error {
+	for _, a := range allowed {
+		if format == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q: use %s", format, strings.Join(allowed, " or "))
+}
\ No newline at end of file
@@ -0,0 +1,155 @@
+package etcproc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/localstore"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// importFile runs ProcessCSVData in process, saving to the DB client chosen
+// by the flags and config. The exit code is ExitInvalid when any record
+// failed, and ExitError when the import could not run.
+func (c *command) importFile(args []string) int {
+	fs := c.flags("import", "<file>")
+	enc := encodingFlag(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	out := fs.String("out", "", "append the saved records to this file as JSON lines instead of the configured local_store")
+	skipDuplicates := fs.Bool("skip-duplicates", false, "skip rows that duplicate earlier rows instead of failing them")
+	pipeline := addPipelineFlags(fs, "")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return c.fail("import", ExitUsage, err)
+	}
+	if *pipeline.account == "" {
+		return c.fail("import", ExitUsage, errors.New("-account is required"))
+	}
+	encoding, err := parser.ParseEncoding(*enc)
+	if err != nil {
+		return c.fail("import", ExitUsage, err)
+	}
+
+	cfg, err := c.loadConfig(*pipeline.config)
+	if err != nil {
+		return c.fail("import", ExitError, err)
+	}
+	opts, err := pipeline.serviceOptions(cfg)
+	if err != nil {
+		return c.fail("import", ExitError, err)
+	}
+	db, err := c.dbClient(cfg, *out)
+	if err != nil {
+		return c.fail("import", ExitError, err)
+	}
+	defer db.Close()
+	if cfg.DeadLetterDir != "" {
+		// Failed records can then be reprocessed by the server
+		store, err := deadletter.NewFileStore(cfg.DeadLetterDir)
+		if err != nil {
+			return c.fail("import", ExitError, err)
+		}
+		opts = append(opts, handler.WithDeadLetterStore(store))
+	}
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("import", ExitError, err)
+	}
+
+	service := handler.NewDataProcessorServiceWithOptions(db, opts...)
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvBytes:       data,
+		Encoding:       pb.Encoding(encoding),
+		AccountId:      *pipeline.account,
+		SkipDuplicates: *skipDuplicates,
+	})
+	if err != nil {
+		return c.fail("import", ExitError, err)
+	}
+
+	if *format == "json" {
+		b, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(resp)
+		if err != nil {
+			return c.fail("import", ExitError, err)
+		}
+		fmt.Fprintln(c.stdout, string(b))
+	} else {
+		st := resp.Stats
+		fmt.Fprintf(c.stdout, "%s\n", resp.Message)
+		fmt.Fprintf(c.stdout, "total %d, saved %d, skipped %d, failed %d, updated %d\n",
+			st.GetTotalRecords(), st.GetSavedRecords(), st.GetSkippedRecords(), st.GetErrorRecords(), st.GetUpdatedRecords())
+		for _, e := range resp.Errors {
+			fmt.Fprintf(c.stdout, "  %s\n", e)
+		}
+		if resp.ImportId != "" && st.GetErrorRecords() > 0 {
+			fmt.Fprintf(c.stdout, "failed records: import %s\n", resp.ImportId)
+		}
+	}
+	if !resp.Success || resp.Stats.GetErrorRecords() > 0 {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// dbClient is a DB client that must be closed after the import
+type dbClient interface {
+	handler.DBClient
+	Close() error
+}
+
+// dbClient returns the DB client records are saved to: the -out file, else
+// the configured local store
+func (c *command) dbClient(cfg *config.Config, out string) (dbClient, error) {
+	if out == "" {
+		if cfg.LocalStore.Enabled {
+			store, err := localstore.Open(cfg.LocalStore.Path)
+			if err != nil {
+				return nil, err
+			}
+			return store, nil
+		}
+		if cfg.DBServiceAddr != "" {
+			return nil, fmt.Errorf("no client for db_service_addr %s is available; use -out or local_store", cfg.DBServiceAddr)
+		}
+		return nil, errors.New("no DB client configured; use -out or local_store")
+	}
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonlClient{f: f}, nil
+}
+
+// jsonlClient is a DB client that writes each saved record as a JSON line
+type jsonlClient struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func (j *jsonlClient) SaveETCData(data interface{}) error {
+	line, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (j *jsonlClient) Close() error {
+	return j.f.Close()
+}
\ No newline at end of file
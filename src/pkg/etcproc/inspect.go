@@ -0,0 +1,100 @@
+package etcproc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// inspection is the JSON output of inspect
+type inspection struct {
+	File        string   `json:"file"`
+	Encoding    string   `json:"encoding"`
+	Format      string   `json:"format"`
+	Header      []string `json:"header,omitempty"`
+	Columns     []column `json:"columns"`
+	Missing     []string `json:"missing,omitempty"`
+	Unmapped    []string `json:"unmapped,omitempty"`
+	DataRows    int      `json:"data_rows"`
+	Records     int      `json:"records"`
+	SkippedRows int      `json:"skipped_rows"`
+}
+
+type column struct {
+	Field  string `json:"field"`
+	Index  int    `json:"index"`
+	Header string `json:"header,omitempty"`
+}
+
+// inspect shows how the parser reads a file: encoding, layout and the column
+// each field comes from
+func (c *command) inspect(args []string) int {
+	fs := c.flags("inspect", "<file>")
+	enc := encodingFlag(fs)
+	format := fs.String("format", "text", "output format: text or json")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return c.fail("inspect", ExitUsage, err)
+	}
+	encoding, err := parser.ParseEncoding(*enc)
+	if err != nil {
+		return c.fail("inspect", ExitUsage, err)
+	}
+
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("inspect", ExitError, err)
+	}
+	in, err := parser.NewETCCSVParser().Inspect(data, encoding)
+	if err != nil {
+		return c.fail("inspect", ExitError, err)
+	}
+
+	out := inspection{
+		File:        file,
+		Encoding:    in.Encoding.String(),
+		Format:      in.Format,
+		Header:      in.Header,
+		Missing:     in.Missing,
+		Unmapped:    in.Unmapped,
+		DataRows:    in.DataRows,
+		Records:     in.Records,
+		SkippedRows: in.SkippedRows,
+	}
+	for _, col := range in.Columns {
+		out.Columns = append(out.Columns, column{Field: col.Field, Index: col.Index, Header: col.Header})
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return c.fail("inspect", ExitError, err)
+		}
+		return ExitOK
+	}
+
+	fmt.Fprintf(c.stdout, "file:      %s\n", out.File)
+	fmt.Fprintf(c.stdout, "encoding:  %s\n", out.Encoding)
+	fmt.Fprintf(c.stdout, "format:    %s\n", out.Format)
+	fmt.Fprintf(c.stdout, "rows:      %d (%d records, %d skipped)\n", out.DataRows, out.Records, out.SkippedRows)
+	fmt.Fprintln(c.stdout, "columns:")
+	tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	for _, col := range out.Columns {
+		fmt.Fprintf(tw, "  %s\t%d\t%s\n", col.Field, col.Index, col.Header)
+	}
+	tw.Flush()
+	if len(out.Missing) > 0 {
+		fmt.Fprintf(c.stdout, "missing:   %s\n", strings.Join(out.Missing, ", "))
+	}
+	if len(out.Unmapped) > 0 {
+		fmt.Fprintf(c.stdout, "unmapped:  %s\n", strings.Join(out.Unmapped, ", "))
+	}
+	return ExitOK
+}
\ No newline at end of file
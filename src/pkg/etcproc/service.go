@@ -0,0 +1,80 @@
+package etcproc
+
+import (
+	"flag"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/internal/config"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+)
+
+// pipelineFlags select the settings the service pipeline runs with
+type pipelineFlags struct {
+	config     *string
+	account    *string
+	rules      *string
+	duplicates *string
+}
+
+// addPipelineFlags adds the flags shared by validate and import
+func addPipelineFlags(fs *flag.FlagSet, defaultAccount string) *pipelineFlags {
+	return &pipelineFlags{
+		config:     fs.String("config", "", "server config file for rules, duplicates, retries and dead letters"),
+		account:    fs.String("account", defaultAccount, "account whose rules and duplicate strategy apply"),
+		rules:      fs.String("rules", "", "validation rules file; enables the rules engine"),
+		duplicates: fs.String("duplicates", "", "duplicate-key strategy: exact_row, card_exit_amount or ignore_status"),
+	}
+}
+
+// serviceOptions builds the service options of the server's pipeline from the
+// config, overridden by the flags
+func (f *pipelineFlags) serviceOptions(cfg *config.Config) ([]handler.ServiceOption, error) {
+	// -duplicates applies to every account
+	strategy, accounts := cfg.Duplicates.Strategy, cfg.Duplicates.Accounts
+	if *f.duplicates != "" {
+		strategy, accounts = *f.duplicates, nil
+	}
+	duplicates, err := dedup.NewPolicySet(strategy, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	var ruleSets *rules.Registry
+	switch {
+	case *f.rules != "":
+		if ruleSets, err = rules.LoadFile(*f.rules); err != nil {
+			return nil, err
+		}
+	case cfg.Rules.Enabled && cfg.Rules.File != "":
+		if ruleSets, err = rules.LoadFile(cfg.Rules.File); err != nil {
+			return nil, err
+		}
+	case cfg.Rules.Enabled:
+		ruleSets = rules.Default()
+	}
+
+	var fuzzy *dedup.FuzzyMatcher
+	if cfg.Duplicates.Fuzzy.Enabled {
+		fuzzy = dedup.NewFuzzyMatcher(time.Duration(cfg.Duplicates.Fuzzy.WindowMinutes) * time.Minute)
+	}
+
+	retryPolicy, err := handler.NewRetryPolicy(
+		cfg.Retry.MaxAttempts,
+		time.Duration(cfg.Retry.InitialBackoffMs)*time.Millisecond,
+		time.Duration(cfg.Retry.MaxBackoffMs)*time.Millisecond,
+		cfg.Retry.Multiplier,
+		cfg.Retry.RetryableCodes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return []handler.ServiceOption{
+		handler.WithDuplicatePolicies(duplicates),
+		handler.WithFuzzyMatcher(fuzzy),
+		handler.WithRules(ruleSets),
+		handler.WithRetryPolicy(retryPolicy),
+	}, nil
+}
\ No newline at end of file
@@ -0,0 +1,105 @@
+package etcproc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// validate runs ValidateCSVData in process and prints the results. The exit
+// code is ExitInvalid when the data is not valid.
+func (c *command) validate(args []string) int {
+	fs := c.flags("validate", "<file>")
+	enc := encodingFlag(fs)
+	format := fs.String("format", "table", "output format: table or json")
+	pipeline := addPipelineFlags(fs, "local")
+	file, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if err := checkFormat(*format, "table", "json"); err != nil {
+		return c.fail("validate", ExitUsage, err)
+	}
+	encoding, err := parser.ParseEncoding(*enc)
+	if err != nil {
+		return c.fail("validate", ExitUsage, err)
+	}
+
+	cfg, err := c.loadConfig(*pipeline.config)
+	if err != nil {
+		return c.fail("validate", ExitError, err)
+	}
+	opts, err := pipeline.serviceOptions(cfg)
+	if err != nil {
+		return c.fail("validate", ExitError, err)
+	}
+	data, err := c.readInput(file)
+	if err != nil {
+		return c.fail("validate", ExitError, err)
+	}
+
+	service := handler.NewDataProcessorServiceWithOptions(nil, opts...)
+	resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvBytes:  data,
+		Encoding:  pb.Encoding(encoding),
+		AccountId: *pipeline.account,
+	})
+	if err != nil {
+		return c.fail("validate", ExitError, err)
+	}
+
+	if *format == "json" {
+		out, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(resp)
+		if err != nil {
+			return c.fail("validate", ExitError, err)
+		}
+		fmt.Fprintln(c.stdout, string(out))
+	} else {
+		c.printValidation(resp)
+	}
+	if !resp.IsValid {
+		return ExitInvalid
+	}
+	return ExitOK
+}
+
+// printValidation prints the record errors as a table and a summary line
+func (c *command) printValidation(resp *pb.ValidateCSVDataResponse) {
+	errs, warnings := 0, 0
+	if len(resp.RecordErrors) > 0 {
+		tw := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "LINE\tSEVERITY\tCODE\tFIELD\tRULE\tMESSAGE")
+		for _, e := range resp.RecordErrors {
+			switch e.Severity {
+			case pb.Severity_SEVERITY_ERROR:
+				errs++
+			case pb.Severity_SEVERITY_WARNING:
+				warnings++
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				e.LineNumber,
+				strings.TrimPrefix(e.Severity.String(), "SEVERITY_"),
+				strings.TrimPrefix(e.Code.String(), "ERROR_CODE_"),
+				e.Field, e.Rule, e.Message)
+		}
+		tw.Flush()
+		fmt.Fprintln(c.stdout)
+	}
+
+	result := "valid"
+	if !resp.IsValid {
+		result = "invalid"
+	}
+	fmt.Fprintf(c.stdout, "%s: %d records, %d errors, %d warnings, %d duplicates (%s)",
+		result, resp.TotalRecords, errs, warnings, resp.DuplicateCount, resp.DuplicateStrategy)
+	if resp.RuleSet != "" {
+		fmt.Fprintf(c.stdout, ", rule set %s", resp.RuleSet)
+	}
+	fmt.Fprintln(c.stdout)
+}
\ No newline at end of file
@@ -0,0 +1,203 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/yhonda-ohishi/etc_data_processor/src/api"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Mode selects how the gateway reaches the service
+type Mode string
+
+const (
+	// ModeInProcess calls the service directly, without a gRPC round trip
+	ModeInProcess Mode = "in_process"
+	// ModeProxy forwards requests to the gRPC server over a client connection,
+	// so they pass through the same interceptors as gRPC callers
+	ModeProxy Mode = "proxy"
+)
+
+// ParseMode returns the mode with the given name; "" selects ModeInProcess
+func ParseMode(name string) (Mode, error) {
+	switch Mode(name) {
+	case "", ModeInProcess:
+		return ModeInProcess, nil
+	case ModeProxy:
+		return ModeProxy, nil
+	default:
+		return "", fmt.Errorf("unknown gateway mode: %s", name)
+	}
+}
+
+// Options configures the gateway
+type Options struct {
+	Mode Mode
+	// Server is called in ModeInProcess
+	Server pb.DataProcessorServiceServer
+	// Endpoint is the gRPC address dialled in ModeProxy, e.g. "localhost:50051"
+	Endpoint string
+	// DialOptions are used in ModeProxy; default is an insecure connection
+	DialOptions []grpc.DialOption
+
+	// Uploader enables POST /v1/upload. Uploads are imported in-process in
+	// both modes.
+	Uploader Uploader
+	// MaxUploadBytes limits the uploaded file; 0 uses DefaultMaxUploadBytes
+	MaxUploadBytes int64
+	// UploadDir is where uploads are spooled; "" uses the system temp directory
+	UploadDir string
+
+	// Logger logs every HTTP request with its request ID; nil uses the
+	// default logger
+	Logger *slog.Logger
+	// Tracer traces every HTTP request and passes the trace on to the
+	// service; nil disables tracing
+	Tracer *tracing.Tracer
+	// Auth requires an API key on the /v1/ routes except /v1/health; the
+	// service then checks the key's accounts. nil disables authentication.
+	Auth *auth.Authenticator
+	// Interceptor runs around the calls the gateway makes in-process, which
+	// skip the gRPC server's interceptors: every REST call in ModeInProcess
+	// and uploads in both modes. main uses it for rate limits.
+	Interceptor grpc.UnaryServerInterceptor
+}
+
+// Gateway serves the REST API generated from the proto http annotations, the
+// multipart upload endpoint, the OpenAPI document at /swagger.json and an
+// explorer page at /docs/
+type Gateway struct {
+	handler http.Handler
+	server  *http.Server
+}
+
+// New registers the REST routes. In ModeProxy the connection is made lazily,
+// so the gRPC server does not need to be listening yet.
+func New(ctx context.Context, opts Options) (*Gateway, error) {
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(headerMatcher), runtime.WithErrorHandler(errorHandler))
+
+	uploader := opts.Uploader
+	if opts.Interceptor != nil && uploader != nil {
+		uploader = &interceptedUploader{Uploader: uploader, interceptor: opts.Interceptor}
+	}
+
+	switch opts.Mode {
+	case "", ModeInProcess:
+		if opts.Server == nil {
+			return nil, fmt.Errorf("in-process gateway requires a server")
+		}
+		server := opts.Server
+		if opts.Interceptor != nil {
+			server = &interceptedServer{DataProcessorServiceServer: server, interceptor: opts.Interceptor}
+		}
+		if err := pb.RegisterDataProcessorServiceHandlerServer(ctx, mux, server); err != nil {
+			return nil, err
+		}
+	case ModeProxy:
+		if opts.Endpoint == "" {
+			return nil, fmt.Errorf("proxy gateway requires a gRPC endpoint")
+		}
+		dialOpts := opts.DialOptions
+		if len(dialOpts) == 0 {
+			dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+		}
+		if err := pb.RegisterDataProcessorServiceHandlerFromEndpoint(ctx, mux, opts.Endpoint, dialOpts); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown gateway mode: %s", opts.Mode)
+	}
+
+	root := http.NewServeMux()
+	root.Handle("/v1/", opts.Auth.Middleware(mux))
+	root.Handle("/v1/health", mux)
+	if uploader != nil {
+		maxBytes := opts.MaxUploadBytes
+		if maxBytes <= 0 {
+			maxBytes = DefaultMaxUploadBytes
+		}
+		root.Handle("/v1/upload", opts.Auth.Middleware(&uploadHandler{mux: mux, uploader: uploader, maxBytes: maxBytes, dir: opts.UploadDir}))
+	}
+	root.HandleFunc("/swagger.json", serveSwagger)
+	root.HandleFunc("/docs/", serveExplorer)
+	root.Handle("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently))
+
+	handler := logging.Middleware(opts.Logger, opts.Tracer.Middleware(root))
+	return &Gateway{
+		handler: handler,
+		server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+// Handler returns the gateway's HTTP handler
+func (g *Gateway) Handler() http.Handler {
+	return g.handler
+}
+
+// Serve serves HTTP on the listener until Shutdown is called, in which case
+// it returns nil
+func (g *Gateway) Serve(lis net.Listener) error {
+	if err := g.server.Serve(lis); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests
+// until the context expires
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	return g.server.Shutdown(ctx)
+}
+
+// headerMatcher forwards the request ID, W3C trace context and API key to gRPC
+// as metadata, in addition to the headers grpc-gateway forwards by default
+// (including Authorization)
+func headerMatcher(key string) (string, bool) {
+	for _, name := range []string{logging.RequestIDKey, tracing.TraceparentHeader, tracing.TracestateHeader, auth.APIKeyHeader} {
+		if strings.EqualFold(key, name) {
+			return name, true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// errorHandler is the default error handler, adding a Retry-After header to
+// throttled requests
+func errorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if retryAfter, ok := ratelimit.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// serveSwagger returns the generated OpenAPI document
+func serveSwagger(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(api.SwaggerJSON)
+}
+
+// serveExplorer returns the bundled API explorer page
+func serveExplorer(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/docs/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(api.ExplorerHTML)
+}
\ No newline at end of file
@@ -0,0 +1,75 @@
+package gateway
+
+import (
+	"context"
+
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+)
+
+// interceptedServer runs a unary server interceptor around in-process calls,
+// which do not pass through the gRPC server's interceptors. Methods it does
+// not wrap call the server directly.
+type interceptedServer struct {
+	pb.DataProcessorServiceServer
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// intercept calls fn through the interceptor as the RPC fullMethod
+func intercept[Req, Resp any](ctx context.Context, s *interceptedServer, fullMethod string, req Req, fn func(context.Context, Req) (Resp, error)) (Resp, error) {
+	var zero Resp
+	resp, err := s.interceptor(ctx, req, &grpc.UnaryServerInfo{Server: s.DataProcessorServiceServer, FullMethod: fullMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return fn(ctx, req.(Req))
+		})
+	if err != nil {
+		return zero, err
+	}
+	typed, _ := resp.(Resp)
+	return typed, nil
+}
+
+func (s *interceptedServer) ProcessCSVFile(ctx context.Context, req *pb.ProcessCSVFileRequest) (*pb.ProcessCSVFileResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ProcessCSVFile_FullMethodName, req, s.DataProcessorServiceServer.ProcessCSVFile)
+}
+
+func (s *interceptedServer) ProcessCSVFiles(ctx context.Context, req *pb.ProcessCSVFilesRequest) (*pb.ProcessCSVFilesResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ProcessCSVFiles_FullMethodName, req, s.DataProcessorServiceServer.ProcessCSVFiles)
+}
+
+func (s *interceptedServer) ProcessCSVData(ctx context.Context, req *pb.ProcessCSVDataRequest) (*pb.ProcessCSVDataResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ProcessCSVData_FullMethodName, req, s.DataProcessorServiceServer.ProcessCSVData)
+}
+
+func (s *interceptedServer) ValidateCSVData(ctx context.Context, req *pb.ValidateCSVDataRequest) (*pb.ValidateCSVDataResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ValidateCSVData_FullMethodName, req, s.DataProcessorServiceServer.ValidateCSVData)
+}
+
+func (s *interceptedServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_HealthCheck_FullMethodName, req, s.DataProcessorServiceServer.HealthCheck)
+}
+
+func (s *interceptedServer) ListFailedRecords(ctx context.Context, req *pb.ListFailedRecordsRequest) (*pb.ListFailedRecordsResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ListFailedRecords_FullMethodName, req, s.DataProcessorServiceServer.ListFailedRecords)
+}
+
+func (s *interceptedServer) ReprocessFailedRecords(ctx context.Context, req *pb.ReprocessFailedRecordsRequest) (*pb.ReprocessFailedRecordsResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_ReprocessFailedRecords_FullMethodName, req, s.DataProcessorServiceServer.ReprocessFailedRecords)
+}
+
+func (s *interceptedServer) GetQuotaUsage(ctx context.Context, req *pb.GetQuotaUsageRequest) (*pb.GetQuotaUsageResponse, error) {
+	return intercept(ctx, s, pb.DataProcessorService_GetQuotaUsage_FullMethodName, req, s.DataProcessorServiceServer.GetQuotaUsage)
+}
+
+// interceptedUploader runs the interceptor around uploads, as ProcessCSVFile
+type interceptedUploader struct {
+	Uploader
+	interceptor grpc.UnaryServerInterceptor
+}
+
+func (u *interceptedUploader) ProcessUploadedFile(ctx context.Context, req *pb.ProcessCSVFileRequest, path string) (*pb.ProcessCSVFileResponse, error) {
+	s := &interceptedServer{interceptor: u.interceptor}
+	return intercept(ctx, s, pb.DataProcessorService_ProcessCSVFile_FullMethodName, req, func(ctx context.Context, req *pb.ProcessCSVFileRequest) (*pb.ProcessCSVFileResponse, error) {
+		return u.Uploader.ProcessUploadedFile(ctx, req, path)
+	})
+}
\ No newline at end of file
@@ -0,0 +1,171 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxUploadBytes is the largest file accepted by POST /v1/upload
+const DefaultMaxUploadBytes = 50 << 20
+
+// Uploader imports files received by POST /v1/upload. It is implemented by
+// handler.DataProcessorService.
+type Uploader interface {
+	ProcessUploadedFile(ctx context.Context, req *pb.ProcessCSVFileRequest, path string) (*pb.ProcessCSVFileResponse, error)
+}
+
+// uploadHandler serves POST /v1/upload. The multipart form has a "file" part
+// with the raw CSV or ZIP bytes (any encoding ParseFile understands) and the
+// fields account_id and skip_duplicates, in any order. The file is streamed to
+// a temporary file, never held in memory, and imported like ProcessCSVFile.
+type uploadHandler struct {
+	mux      *runtime.ServeMux
+	uploader Uploader
+	maxBytes int64
+	dir      string
+}
+
+func (h *uploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	_, marshaler := runtime.MarshalerForRequest(h.mux, r)
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		runtime.HTTPError(ctx, h.mux, marshaler, w, r, &runtime.HTTPStatusError{
+			HTTPStatus: http.StatusMethodNotAllowed,
+			Err:        status.Error(codes.Unimplemented, "method not allowed"),
+		})
+		return
+	}
+
+	req, path, err := h.receive(w, r)
+	if path != "" {
+		defer os.Remove(path)
+	}
+	if err != nil {
+		runtime.HTTPError(ctx, h.mux, marshaler, w, r, err)
+		return
+	}
+
+	resp, err := h.uploader.ProcessUploadedFile(ctx, req, path)
+	if err != nil {
+		runtime.HTTPError(ctx, h.mux, marshaler, w, r, err)
+		return
+	}
+	runtime.ForwardResponseMessage(ctx, h.mux, marshaler, w, r, resp)
+}
+
+// receive reads the multipart form, spooling the file part to a temporary file
+// whose path is returned so the caller can remove it
+func (h *uploadHandler) receive(w http.ResponseWriter, r *http.Request) (*pb.ProcessCSVFileRequest, string, error) {
+	// Allow some room for the other form fields and part headers
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+1<<20)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", status.Error(codes.InvalidArgument, "request must be multipart/form-data")
+	}
+
+	req := &pb.ProcessCSVFileRequest{}
+	var path string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, path, h.readError(err)
+		}
+
+		switch part.FormName() {
+		case "file":
+			if path != "" {
+				return nil, path, status.Error(codes.InvalidArgument, "only one file may be uploaded")
+			}
+			req.CsvFilePath = filepath.Base(strings.ReplaceAll(part.FileName(), `\`, "/"))
+			if req.CsvFilePath == "." || req.CsvFilePath == "/" {
+				req.CsvFilePath = "upload.csv"
+			}
+			path, err = h.spool(part, filepath.Ext(req.CsvFilePath))
+			if err != nil {
+				return nil, path, err
+			}
+		case "account_id":
+			value, err := formValue(part)
+			if err != nil {
+				return nil, path, h.readError(err)
+			}
+			req.AccountId = value
+		case "skip_duplicates":
+			value, err := formValue(part)
+			if err != nil {
+				return nil, path, h.readError(err)
+			}
+			if req.SkipDuplicates, err = strconv.ParseBool(value); err != nil {
+				return nil, path, status.Errorf(codes.InvalidArgument, "invalid skip_duplicates: %q", value)
+			}
+		}
+		part.Close()
+	}
+
+	if path == "" {
+		return nil, "", status.Error(codes.InvalidArgument, `multipart form has no "file" part`)
+	}
+	return req, path, nil
+}
+
+// spool copies the file part to a temporary file with the given extension
+func (h *uploadHandler) spool(part *multipart.Part, ext string) (string, error) {
+	tmp, err := os.CreateTemp(h.dir, "upload-*"+strings.ToLower(ext))
+	if err != nil {
+		return "", status.Error(codes.Internal, "failed to store upload")
+	}
+	defer tmp.Close()
+
+	n, err := io.Copy(tmp, io.LimitReader(part, h.maxBytes+1))
+	if err != nil {
+		return tmp.Name(), h.readError(err)
+	}
+	if n > h.maxBytes {
+		return tmp.Name(), tooLarge(h.maxBytes)
+	}
+	return tmp.Name(), nil
+}
+
+// readError converts an error reading the request body
+func (h *uploadHandler) readError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return tooLarge(h.maxBytes)
+	}
+	return status.Errorf(codes.InvalidArgument, "invalid multipart form: %v", err)
+}
+
+// tooLarge is the 413 returned for oversized uploads
+func tooLarge(max int64) error {
+	return &runtime.HTTPStatusError{
+		HTTPStatus: http.StatusRequestEntityTooLarge,
+		Err:        status.Errorf(codes.InvalidArgument, "upload is larger than %d bytes", max),
+	}
+}
+
+// formValue reads a small form field
+func formValue(part *multipart.Part) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(part, 1024))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
\ No newline at end of file
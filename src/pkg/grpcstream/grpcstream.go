@@ -0,0 +1,24 @@
+// Package grpcstream holds helpers shared by the server stream interceptors
+package grpcstream
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// WithContext returns ss with its context replaced by ctx, so a stream
+// interceptor can pass values on to the handler
+func WithContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &contextStream{ServerStream: ss, ctx: ctx}
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
\ No newline at end of file
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// processArchive imports every CSV entry of a ZIP archive as one import.
+// Entries are decoded like ParseFile and reported as sub-results; duplicates
+// are detected across entries, and an entry correcting an earlier one is
+// saved after it. The response stats are the entry totals.
+func (s *DataProcessorService) processArchive(ctx context.Context, req *pb.ProcessCSVFileRequest, filePath string) (*pb.ProcessCSVFileResponse, error) {
+	ctx, span := s.startImport(ctx, "etc.import", req.CsvFilePath, metrics.FormatZIP, req.AccountId)
+	defer span.End()
+
+	_, unzipSpan := s.tracer.Start(ctx, "etc.unzip", slog.String("etc.file", req.CsvFilePath))
+	entries, err := archive.OpenFile(filePath, s.archives)
+	unzipSpan.SetAttributes(slog.Int("etc.entries", len(entries)))
+	unzipSpan.RecordError(err)
+	unzipSpan.End()
+	if err != nil {
+		span.RecordError(err)
+		s.metrics.ParseFailed(metrics.FormatZIP)
+		logParseFailure(ctx, req.CsvFilePath, metrics.FormatZIP, req.AccountId, err)
+		return &pb.ProcessCSVFileResponse{
+			Success: false,
+			Message: fmt.Sprintf("Failed to read ZIP archive: %v", err),
+			Stats:   &pb.ProcessingStats{},
+			Errors:  []string{err.Error()},
+			RecordErrors: []*pb.RecordError{
+				recordErrorToProto(recorderr.Wrap(recorderr.CodeParseFailed, "zip", err)),
+			},
+		}, nil
+	}
+
+	files := s.entryFiles(ctx, "", entries)
+	if err := s.reserveRecords(ctx, req.AccountId, files); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	importID := deadletter.NewID()
+	totals, failed := s.importBatch(ctx, files, importID, req.AccountId, req.SkipDuplicates, s.batchLimits().MaxConcurrency)
+	logImport(ctx, req.CsvFilePath, metrics.FormatZIP, req.AccountId, importID, totals,
+		slog.Int("entries", len(files)), slog.Int("failed_entries", int(failed)))
+	endImport(span, importID, totals)
+
+	resp := &pb.ProcessCSVFileResponse{
+		Success:           totals.SavedRecords > 0,
+		Message:           fmt.Sprintf("Processed %d records from %d archive entries (%d failed)", totals.TotalRecords, len(files), failed),
+		Stats:             totals,
+		ImportId:          importID,
+		DuplicateStrategy: string(s.duplicates.For(req.AccountId).Strategy),
+		RuleSet:           s.ruleSetName(req.AccountId),
+	}
+	for _, f := range files {
+		resp.Entries = append(resp.Entries, f.result)
+		for _, message := range f.result.Errors {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", f.path, message))
+		}
+	}
+	return resp, nil
+}
+
+// entryFiles parses archive entries into batch files named prefix+entry name.
+// Entries that fail to parse get a failed result.
+func (s *DataProcessorService) entryFiles(ctx context.Context, prefix string, entries []archive.Entry) []*batchFile {
+	files := make([]*batchFile, len(entries))
+	for i, entry := range entries {
+		f := &batchFile{path: prefix + entry.Name, format: metrics.FormatZIP}
+		_, span := s.tracer.Start(ctx, "etc.parse", slog.String("etc.format", f.format), slog.String("etc.file", f.path))
+		text, err := parser.Decode(entry.Data, parser.EncodingAuto)
+		var records []parser.ActualETCRecord
+		if err == nil {
+			records, err = s.parser.Parse(strings.NewReader(text))
+		}
+		endParse(span, len(records), err)
+		if err != nil {
+			s.metrics.ParseFailed(f.format)
+			f.result = failedFileResult(f.path, fmt.Sprintf("Failed to parse CSV file: %v", err), recorderr.Wrap(recorderr.CodeParseFailed, "csv", err))
+		}
+		f.records = records
+		files[i] = f
+	}
+	return files
+}
\ No newline at end of file
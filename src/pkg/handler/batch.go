@@ -0,0 +1,405 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultBatchMaxFiles    = 100
+	defaultBatchConcurrency = 4
+	defaultSaveBatchSize    = 100
+)
+
+// BatchLimits bounds ProcessCSVFiles requests and sets how many records of an
+// import are validated, converted and saved per DB batch. Zero values use the
+// defaults.
+type BatchLimits struct {
+	MaxFiles       int // files one request may resolve to
+	MaxConcurrency int // files parsed and saved at once
+	SaveBatchSize  int // records per DB batch
+}
+
+// batchFile is one file of a batch import
+type batchFile struct {
+	path    string
+	format  string
+	records []parser.ActualETCRecord
+	checks  []recordCheck
+	after   []int // earlier files sharing a match key, saved before this one
+	result  *pb.FileResult
+}
+
+// ProcessCSVFiles processes several CSV files as one import. Files come from
+// csv_file_paths, a directory and a glob, in that order. Duplicates and
+// re-issued rows are detected across all files, in file order; files are
+// parsed and saved with bounded concurrency.
+func (s *DataProcessorService) ProcessCSVFiles(ctx context.Context, req *pb.ProcessCSVFilesRequest) (*pb.ProcessCSVFilesResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is nil")
+	}
+	if err := s.validator.ValidateAccountID(req.AccountId); err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, req.AccountId); err != nil {
+		return nil, err
+	}
+	if req.Concurrency < 0 {
+		return nil, invalidArgument("concurrency", "concurrency must not be negative")
+	}
+
+	paths, err := s.batchPaths(req)
+	if err != nil {
+		return nil, err
+	}
+	done, err := s.startJob(ctx, req.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	limits := s.batchLimits()
+	concurrency := limits.MaxConcurrency
+	if req.Concurrency > 0 && int(req.Concurrency) < concurrency {
+		concurrency = int(req.Concurrency)
+	}
+
+	loaded := make([][]*batchFile, len(paths))
+	forEachFile(len(paths), concurrency, func(i int) {
+		loaded[i] = s.loadBatchFile(ctx, paths[i])
+	})
+	var files []*batchFile
+	for _, l := range loaded {
+		files = append(files, l...)
+	}
+	if err := s.reserveRecords(ctx, req.AccountId, files); err != nil {
+		return nil, err
+	}
+
+	importID := deadletter.NewID()
+	totals, failed := s.importBatch(ctx, files, importID, req.AccountId, req.SkipDuplicates, concurrency)
+
+	resp := &pb.ProcessCSVFilesResponse{
+		Success:           totals.SavedRecords > 0,
+		Message:           fmt.Sprintf("Processed %d records from %d files (%d failed)", totals.TotalRecords, len(files), failed),
+		Totals:            totals,
+		ImportId:          importID,
+		FailedFiles:       failed,
+		DuplicateStrategy: string(s.duplicates.For(req.AccountId).Strategy),
+		RuleSet:           s.ruleSetName(req.AccountId),
+	}
+	for _, f := range files {
+		resp.Files = append(resp.Files, f.result)
+		logImport(ctx, f.path, f.format, req.AccountId, importID, f.result.Stats)
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "batch import finished",
+		slog.String("account_id", req.AccountId),
+		slog.String("import_id", importID),
+		slog.Int("files", len(files)),
+		slog.Int("failed_files", int(failed)),
+		statsAttr(totals),
+	)
+	return resp, nil
+}
+
+// importBatch classifies the records of all loaded files together, then saves
+// each file with bounded concurrency. A file waits for the earlier files it
+// shares a match key with, so a re-issued row always replaces the row it
+// corrects. It returns the summed stats and the number of files that failed
+// or saved nothing.
+func (s *DataProcessorService) importBatch(ctx context.Context, files []*batchFile, importID, accountID string, skipDuplicates bool, concurrency int) (*pb.ProcessingStats, int32) {
+	_, span := s.tracer.Start(ctx, "etc.dedup", slog.Int("etc.files", len(files)))
+	s.classifyBatch(files, accountID)
+	span.End()
+
+	done := make([]chan struct{}, len(files))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	forEachFile(len(files), concurrency, func(i int) {
+		defer close(done[i])
+		f := files[i]
+		if f.result != nil {
+			return
+		}
+		// Earlier files were started first, so waiting on them cannot deadlock
+		for _, j := range f.after {
+			<-done[j]
+		}
+		fileCtx, span := s.startImport(ctx, "etc.import.file", f.path, f.format, accountID)
+		stats, errors := s.importRecords(fileCtx, f.records, f.checks, importID, accountID, skipDuplicates)
+		endImport(span, importID, stats)
+		s.observeImport(f.format, stats)
+		f.result = &pb.FileResult{
+			CsvFilePath:  f.path,
+			Success:      stats.SavedRecords > 0,
+			Message:      fmt.Sprintf("Processed %d records from file", stats.TotalRecords),
+			Stats:        stats,
+			Errors:       errors.messages,
+			RecordErrors: errors.records,
+		}
+	})
+
+	totals := &pb.ProcessingStats{}
+	failed := int32(0)
+	for _, f := range files {
+		addStats(totals, f.result.Stats)
+		if !f.result.Success {
+			failed++
+		}
+	}
+	return totals, failed
+}
+
+// reserveRecords counts the parsed records of the files against the daily
+// record quotas
+func (s *DataProcessorService) reserveRecords(ctx context.Context, accountID string, files []*batchFile) error {
+	n := 0
+	for _, f := range files {
+		n += len(f.records)
+	}
+	return s.limits.ReserveRecords(ctx, accountID, n)
+}
+
+// batchLimits returns the configured limits with defaults filled in
+func (s *DataProcessorService) batchLimits() BatchLimits {
+	limits := s.batch
+	if limits.SaveBatchSize <= 0 {
+		limits.SaveBatchSize = defaultSaveBatchSize
+	}
+	if limits.MaxFiles <= 0 {
+		limits.MaxFiles = defaultBatchMaxFiles
+	}
+	if limits.MaxConcurrency <= 0 {
+		limits.MaxConcurrency = defaultBatchConcurrency
+	}
+	return limits
+}
+
+// batchPaths expands the request into a list of files. Listed paths keep
+// their order; directory and glob matches are sorted. A path found more than
+// once is processed once. The directory and the glob base are confined to the
+// sandbox before they are listed.
+func (s *DataProcessorService) batchPaths(req *pb.ProcessCSVFilesRequest) ([]string, error) {
+	paths := append([]string(nil), req.CsvFilePaths...)
+
+	if req.Directory != "" {
+		dir, err := s.confineDir("directory", req.Directory)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, status.Errorf(codes.NotFound, "directory not found: %s", req.Directory)
+			}
+			return nil, status.Error(codes.Internal, "failed to list directory")
+		}
+		for _, entry := range entries {
+			if entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(entry.Name()), ".csv") {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+
+	if req.Glob != "" {
+		if _, err := filepath.Match(req.Glob, ""); err != nil {
+			return nil, invalidArgument("glob", fmt.Sprintf("invalid glob: %v", err))
+		}
+		if _, err := s.confineDir("glob", globBase(req.Glob)); err != nil {
+			return nil, err
+		}
+		matches, _ := filepath.Glob(req.Glob)
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.Mode().IsRegular() {
+				paths = append(paths, match)
+			}
+		}
+	}
+
+	var unique []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		key := filepath.Clean(path)
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, path)
+	}
+
+	if len(unique) == 0 {
+		return nil, invalidArgument("csv_file_paths", "no CSV files to process: set csv_file_paths, directory or glob")
+	}
+	if max := s.batchLimits().MaxFiles; len(unique) > max {
+		return nil, invalidArgument("csv_file_paths", fmt.Sprintf("request resolves to %d files, more than the limit of %d", len(unique), max))
+	}
+	return unique, nil
+}
+
+// globBase returns the directory part of a glob before its first pattern element
+func globBase(pattern string) string {
+	dir := filepath.Dir(filepath.Clean(pattern))
+	for strings.ContainsAny(dir, `*?[\`) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
+// loadBatchFile confines, checks and parses one file. A ZIP archive yields one
+// file per CSV entry, named "<archive>!/<entry>". A file that cannot be read
+// gets a failed result and takes no further part in the import.
+func (s *DataProcessorService) loadBatchFile(ctx context.Context, path string) []*batchFile {
+	format := metrics.FormatCSV
+	if archive.IsZip(path) {
+		format = metrics.FormatZIP
+	}
+
+	confined, err := s.confinePath(path)
+	if err == nil {
+		err = s.validator.CheckFileExists(confined)
+	}
+	if err != nil {
+		message := status.Convert(err).Message()
+		return []*batchFile{{path: path, format: format, result: failedFileResult(path, message, recorderr.New(recorderr.CodeInvalidRequest, "csv_file_path", message))}}
+	}
+
+	if archive.IsZip(confined) {
+		_, span := s.tracer.Start(ctx, "etc.unzip", slog.String("etc.file", path))
+		entries, err := archive.OpenFile(confined, s.archives)
+		span.SetAttributes(slog.Int("etc.entries", len(entries)))
+		span.RecordError(err)
+		span.End()
+		if err != nil {
+			s.metrics.ParseFailed(metrics.FormatZIP)
+			return []*batchFile{{path: path, format: metrics.FormatZIP, result: failedFileResult(path, fmt.Sprintf("Failed to read ZIP archive: %v", err), recorderr.Wrap(recorderr.CodeParseFailed, "zip", err))}}
+		}
+		return s.entryFiles(ctx, path+"!/", entries)
+	}
+
+	f := &batchFile{path: path, format: metrics.FormatCSV}
+	_, span := s.tracer.Start(ctx, "etc.parse", slog.String("etc.format", f.format), slog.String("etc.file", path))
+	records, err := s.parser.ParseFile(confined)
+	endParse(span, len(records), err)
+	if err != nil {
+		s.metrics.ParseFailed(f.format)
+		f.result = failedFileResult(path, fmt.Sprintf("Failed to parse CSV file: %v", err), recorderr.Wrap(recorderr.CodeParseFailed, "csv", err))
+		return []*batchFile{f}
+	}
+	f.records = records
+	return []*batchFile{f}
+}
+
+// failedFileResult returns the result of a file that could not be read
+func failedFileResult(path, message string, e *recorderr.Error) *pb.FileResult {
+	return &pb.FileResult{
+		CsvFilePath:  path,
+		Success:      false,
+		Message:      message,
+		Stats:        &pb.ProcessingStats{},
+		Errors:       []string{e.Message},
+		RecordErrors: []*pb.RecordError{recordErrorToProto(e)},
+	}
+}
+
+// classifyBatch checks every record against the earlier records of all files,
+// in file order. Notices about a record in another file name that file. Each
+// file records the earlier files holding a row with one of its match keys.
+func (s *DataProcessorService) classifyBatch(files []*batchFile, accountID string) {
+	type location struct {
+		file  int
+		index int
+	}
+
+	checker := s.newRowChecker(accountID, true)
+	var all []parser.ActualETCRecord
+	var locations []location
+	owners := make(map[string]int) // match key -> last file with a row under it
+
+	for fi, f := range files {
+		if f.result != nil {
+			continue
+		}
+		f.checks = make([]recordCheck, len(f.records))
+		for i, record := range f.records {
+			global := len(all)
+			all = append(all, record)
+			locations = append(locations, location{file: fi, index: i})
+
+			match := checker.check(record, global)
+			if owner, ok := owners[match.Key]; ok && owner != fi && !slices.Contains(f.after, owner) {
+				f.after = append(f.after, owner)
+			}
+			owners[match.Key] = fi
+			if match.Kind == dedup.MatchNone {
+				f.checks[i] = recordCheck{match: match}
+				continue
+			}
+
+			earlier, where := all[match.Index], locations[match.Index]
+			at := fmt.Sprintf("line %d", lineNumber(earlier, where.index))
+			if where.file != fi {
+				at = fmt.Sprintf("%s %s", files[where.file].path, at)
+			}
+			match.Index = where.index
+			f.checks[i] = recordCheck{
+				match:  match,
+				notice: matchNotice(match.Kind, earlier, record, at).At(lineNumber(record, i), i),
+			}
+		}
+	}
+}
+
+// forEachFile calls fn for 0..n-1 with at most limit calls running at once
+func forEachFile(n, limit int, fn func(i int)) {
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// addStats adds the counters of one file to the batch totals
+func addStats(total, stats *pb.ProcessingStats) {
+	total.TotalRecords += stats.TotalRecords
+	total.SavedRecords += stats.SavedRecords
+	total.SkippedRecords += stats.SkippedRecords
+	total.ErrorRecords += stats.ErrorRecords
+	total.RetriedRecords += stats.RetriedRecords
+	total.RetryAttempts += stats.RetryAttempts
+	total.TransientErrors += stats.TransientErrors
+	total.PermanentErrors += stats.PermanentErrors
+	total.CorrectedRecords += stats.CorrectedRecords
+	total.StatusChanges += stats.StatusChanges
+	total.UpdatedRecords += stats.UpdatedRecords
+}
\ No newline at end of file
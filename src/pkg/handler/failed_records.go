@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ListFailedRecords returns records held in the dead-letter store
+func (s *DataProcessorService) ListFailedRecords(ctx context.Context, req *pb.ListFailedRecordsRequest) (*pb.ListFailedRecordsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is nil")
+	}
+	if err := s.validator.ValidateAccountID(req.AccountId); err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, req.AccountId); err != nil {
+		return nil, err
+	}
+	if s.deadLetters == nil {
+		return nil, status.Error(codes.FailedPrecondition, "dead-letter store is not configured")
+	}
+
+	filter := deadletter.Filter{AccountID: req.AccountId, ImportID: req.ImportId}
+	all, err := s.deadLetters.List(filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list failed records: %v", err)
+	}
+
+	limited := all
+	if req.Limit > 0 && len(limited) > int(req.Limit) {
+		limited = limited[:req.Limit]
+	}
+
+	resp := &pb.ListFailedRecordsResponse{TotalCount: int32(len(all))}
+	for _, rec := range limited {
+		resp.Records = append(resp.Records, failedRecordToProto(rec))
+	}
+	return resp, nil
+}
+
+// ReprocessFailedRecords replays failed records through conversion and saving.
+// Records that succeed are removed from the store; the rest are updated with the new error.
+func (s *DataProcessorService) ReprocessFailedRecords(ctx context.Context, req *pb.ReprocessFailedRecordsRequest) (*pb.ReprocessFailedRecordsResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is nil")
+	}
+	if err := s.validator.ValidateAccountID(req.AccountId); err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, req.AccountId); err != nil {
+		return nil, err
+	}
+	if s.deadLetters == nil {
+		return nil, status.Error(codes.FailedPrecondition, "dead-letter store is not configured")
+	}
+	done, err := s.startJob(ctx, req.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	failed, err := s.deadLetters.List(deadletter.Filter{
+		AccountID: req.AccountId,
+		ImportID:  req.ImportId,
+		IDs:       req.Ids,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list failed records: %v", err)
+	}
+
+	ctx, span := s.tracer.Start(ctx, "etc.reprocess", slog.String("etc.account_id", req.AccountId))
+	defer span.End()
+
+	stats := &pb.ProcessingStats{TotalRecords: int32(len(failed))}
+	errors := &errorList{}
+
+	for i, rec := range failed {
+		if ctx.Err() != nil {
+			cancelled := recorderr.Wrap(recorderr.CodeCancelled, "", ctx.Err())
+			errors.add(fmt.Sprintf("Reprocessing cancelled at failed record %s", rec.ID), cancelled.At(rec.LineNumber, i))
+			stats.ErrorRecords += int32(len(failed) - i)
+			break
+		}
+
+		matchKey := rec.MatchKey
+		if matchKey == "" {
+			matchKey = s.duplicates.For(rec.AccountID).MatchKey(rec.Record)
+		}
+		var result saveResult
+		if _, rejected := s.checkRules(rec.Record, i, rec.AccountID); rejected != nil {
+			result = *rejected
+		} else {
+			result = s.saveRecord(ctx, rec.Record, rec.AccountID, matchKey)
+		}
+		result.addRetryStats(stats)
+		if result.err != nil {
+			errors.add(fmt.Sprintf("Failed record %s: %s", rec.ID, result.message(rec.LineNumber)), result.recordError(rec.LineNumber, i))
+			result.addErrorStats(stats)
+
+			rec.Stage = result.stage
+			rec.Error = result.err.Error()
+			rec.ErrorClass = result.class.String()
+			rec.ReprocessAttempts++
+			if err := s.deadLetters.Update(rec); err != nil {
+				errors.messages = append(errors.messages, fmt.Sprintf("Failed record %s: update failed: %v", rec.ID, err))
+			}
+			continue
+		}
+
+		if err := s.deadLetters.Delete(rec.ID); err != nil {
+			errors.messages = append(errors.messages, fmt.Sprintf("Failed record %s: delete failed: %v", rec.ID, err))
+		}
+		stats.SavedRecords++
+	}
+
+	remaining, err := s.deadLetters.List(deadletter.Filter{AccountID: req.AccountId, ImportID: req.ImportId})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count remaining records: %v", err)
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "reprocess finished",
+		slog.String("account_id", req.AccountId),
+		slog.String("import_id", req.ImportId),
+		slog.Int("remaining", len(remaining)),
+		statsAttr(stats),
+	)
+	endImport(span, req.ImportId, stats)
+
+	return &pb.ReprocessFailedRecordsResponse{
+		Success:        stats.ErrorRecords == 0,
+		Message:        fmt.Sprintf("Reprocessed %d failed records, %d saved", stats.TotalRecords, stats.SavedRecords),
+		Stats:          stats,
+		Errors:         errors.messages,
+		RemainingCount: int32(len(remaining)),
+		RecordErrors:   errors.records,
+	}, nil
+}
+
+// deadLetter keeps a failed record, with its raw row, for later replay
+func (s *DataProcessorService) deadLetter(importID, accountID string, record parser.ActualETCRecord, matchKey string, result saveResult) {
+	if s.deadLetters == nil {
+		return
+	}
+
+	// A failing dead-letter store must not break the import itself
+	s.deadLetters.Add(deadletter.FailedRecord{
+		ImportID:   importID,
+		AccountID:  accountID,
+		LineNumber: record.LineNumber,
+		RawRow:     record.RawRow,
+		MatchKey:   matchKey,
+		Record:     record,
+		Stage:      result.stage,
+		Error:      result.err.Error(),
+		ErrorClass: result.class.String(),
+	})
+}
+
+// failedRecordToProto converts a stored failed record to its API form
+func failedRecordToProto(rec deadletter.FailedRecord) *pb.FailedRecord {
+	r := rec.Record
+	return &pb.FailedRecord{
+		Id:         rec.ID,
+		ImportId:   rec.ImportID,
+		AccountId:  rec.AccountID,
+		LineNumber: int32(rec.LineNumber),
+		RawRow:     rec.RawRow,
+		Record: &pb.ETCRecordData{
+			EntryDate:       r.EntryDate,
+			EntryTime:       r.EntryTime,
+			ExitDate:        r.ExitDate,
+			ExitTime:        r.ExitTime,
+			EntryIc:         r.EntryIC,
+			ExitIc:          r.ExitIC,
+			RouteInfo:       r.RouteInfo,
+			EtcAmount:       int32(r.ETCAmount),
+			NormalAmount:    int32(r.NormalAmount),
+			DiscountApplied: int32(r.DiscountApplied),
+			Mileage:         int32(r.Mileage),
+			VehicleClass:    int32(r.VehicleClass),
+			VehicleNumber:   r.VehicleNumber,
+			CardNumber:      r.CardNumber,
+			Notes:           r.Notes,
+		},
+		Stage:             rec.Stage,
+		Error:             rec.Error,
+		ErrorClass:        rec.ErrorClass,
+		ReprocessAttempts: int32(rec.ReprocessAttempts),
+		CreatedAt:         rec.CreatedAt.Unix(),
+		UpdatedAt:         rec.UpdatedAt.Unix(),
+	}
+}
\ No newline at end of file
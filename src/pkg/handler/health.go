@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// Pinger is implemented by DB clients that can check their connection. The
+// readiness probe uses it; clients without it are assumed reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheck returns the service health status. Status is "healthy" unless
+// the health monitor's latest probe found a dependency down, in which case it
+// is "unhealthy" and the failing dependency's detail carries the error.
+func (s *DataProcessorService) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	uptime := time.Since(s.started)
+	details := map[string]string{
+		"service":        "etc_data_processor",
+		"uptime":         uptime.Truncate(time.Second).String(),
+		"uptime_seconds": strconv.FormatInt(int64(uptime.Seconds()), 10),
+		"started_at":     s.started.UTC().Format(time.RFC3339),
+		"in_flight_jobs": strconv.FormatInt(s.InFlightJobs(), 10),
+	}
+	for key, value := range buildDetails() {
+		details[key] = value
+	}
+
+	healthy := true
+	if s.health != nil {
+		statuses := s.health.Statuses()
+		if statuses == nil {
+			statuses = s.health.Probe(ctx)
+		}
+		for _, st := range statuses {
+			value := "ok"
+			if !st.Healthy {
+				value = "error: " + st.Error
+				healthy = false
+			}
+			details["dependency."+st.Name] = value
+		}
+	}
+
+	status := "healthy"
+	if !healthy {
+		status = "unhealthy"
+	}
+	return &pb.HealthCheckResponse{
+		Status:    status,
+		Version:   version,
+		Timestamp: time.Now().Unix(),
+		Details:   details,
+	}, nil
+}
+
+// InFlightJobs returns how many imports are running
+func (s *DataProcessorService) InFlightJobs() int64 {
+	return s.jobs.Load()
+}
+
+// PingDatabase checks that the DB client can reach the database. It fails
+// when no client is configured and succeeds for clients that are not Pingers.
+func (s *DataProcessorService) PingDatabase(ctx context.Context) error {
+	if s.dbClient == nil {
+		return fmt.Errorf("database client is not configured")
+	}
+	if p, ok := s.dbClient.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// startJob admits an import for the account under the concurrent import
+// limits and counts it as in flight; call the returned func when it ends
+func (s *DataProcessorService) startJob(ctx context.Context, accountID string) (func(), error) {
+	release, err := s.limits.StartImport(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	s.jobs.Add(1)
+	done := s.metrics.ImportStarted()
+	return func() {
+		s.jobs.Add(-1)
+		done()
+		release()
+	}, nil
+}
+
+// buildDetails describes the running binary from its embedded build info
+func buildDetails() map[string]string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	details := map[string]string{"go_version": info.GoVersion}
+	if info.Main.Version != "" {
+		details["build_version"] = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			details["build_revision"] = setting.Value
+		case "vcs.time":
+			details["build_time"] = setting.Value
+		case "vcs.modified":
+			details["build_modified"] = setting.Value
+		}
+	}
+	return details
+}
\ No newline at end of file
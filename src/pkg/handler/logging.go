@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// logImport logs a finished import with the request's logger. file is empty
+// for inline data.
+func logImport(ctx context.Context, file, format, accountID, importID string, stats *pb.ProcessingStats, extra ...slog.Attr) {
+	attrs := []slog.Attr{
+		slog.String("account_id", accountID),
+		slog.String("format", format),
+		slog.String("import_id", importID),
+	}
+	if file != "" {
+		attrs = append(attrs, slog.String("file", file))
+	}
+	attrs = append(attrs, extra...)
+	attrs = append(attrs, statsAttr(stats))
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "import finished", attrs...)
+}
+
+// logParseFailure logs a file or payload that could not be parsed
+func logParseFailure(ctx context.Context, file, format, accountID string, err error) {
+	attrs := []slog.Attr{
+		slog.String("account_id", accountID),
+		slog.String("format", format),
+		slog.String("error", err.Error()),
+	}
+	if file != "" {
+		attrs = append(attrs, slog.String("file", file))
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, slog.LevelWarn, "import failed", attrs...)
+}
+
+// statsAttr groups the processing counters under "stats"
+func statsAttr(stats *pb.ProcessingStats) slog.Attr {
+	return slog.Group("stats",
+		slog.Int("total", int(stats.GetTotalRecords())),
+		slog.Int("saved", int(stats.GetSavedRecords())),
+		slog.Int("skipped", int(stats.GetSkippedRecords())),
+		slog.Int("errors", int(stats.GetErrorRecords())),
+		slog.Int("updated", int(stats.GetUpdatedRecords())),
+		slog.Int("corrected", int(stats.GetCorrectedRecords())),
+		slog.Int("retried", int(stats.GetRetriedRecords())),
+	)
+}
\ No newline at end of file
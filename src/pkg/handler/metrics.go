@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// observeImport counts the rows of one imported file or archive entry by outcome
+func (s *DataProcessorService) observeImport(format string, stats *pb.ProcessingStats) {
+	if s.metrics == nil || stats == nil {
+		return
+	}
+	s.metrics.AddRecords(metrics.OutcomeParsed, format, int(stats.TotalRecords))
+	s.metrics.AddRecords(metrics.OutcomeSaved, format, int(stats.SavedRecords))
+	s.metrics.AddRecords(metrics.OutcomeUpdated, format, int(stats.UpdatedRecords))
+	s.metrics.AddRecords(metrics.OutcomeSkipped, format, int(stats.SkippedRecords))
+	s.metrics.AddRecords(metrics.OutcomeRejected, format, int(stats.ErrorRecords))
+}
\ No newline at end of file
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/health"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
+)
+
+// ServiceOption configures optional dependencies of DataProcessorService
+type ServiceOption func(*DataProcessorService)
+
+// WithParser overrides the CSV parser
+func WithParser(p Parser) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.parser = p
+	}
+}
+
+// WithValidator overrides the request validator
+func WithValidator(v Validator) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.validator = v
+	}
+}
+
+// WithRetryPolicy sets the retry policy used for database saves
+func WithRetryPolicy(p *RetryPolicy) ServiceOption {
+	return func(s *DataProcessorService) {
+		if p != nil {
+			s.retryPolicy = p
+		}
+	}
+}
+
+// WithDeadLetterStore sets where records that fail conversion or saving are kept.
+// A nil store, the default, disables dead-lettering.
+func WithDeadLetterStore(store deadletter.Store) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.deadLetters = store
+	}
+}
+
+// WithDuplicatePolicies sets the duplicate-key policies used by both
+// ValidateCSVData and the process RPCs
+func WithDuplicatePolicies(set *dedup.PolicySet) ServiceOption {
+	return func(s *DataProcessorService) {
+		if set != nil {
+			s.duplicates = set
+		}
+	}
+}
+
+// WithFuzzyMatcher enables detection of rows re-issued by the card issuer
+// (corrections and status changes). Imports only use it with a DB client
+// that implements Upserter or ContextUpserter. A nil matcher disables it.
+func WithFuzzyMatcher(m *dedup.FuzzyMatcher) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.fuzzy = m
+	}
+}
+
+// WithRules enables the validation rules engine. Rule sets are applied per
+// account by ValidateCSVData and the process RPCs; records with error-severity
+// violations are not saved. A nil registry disables it.
+func WithRules(reg *rules.Registry) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.rules.Store(reg)
+	}
+}
+
+// WithSandbox confines ProcessCSVFile to the sandbox's allowed roots, size
+// limit and extensions. A nil sandbox allows any readable path.
+func WithSandbox(sb *sandbox.Sandbox) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.sandbox = sb
+	}
+}
+
+// WithBatchLimits bounds how many files a ProcessCSVFiles request may
+// resolve to and how many of them are processed at once
+func WithBatchLimits(limits BatchLimits) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.batch = limits
+	}
+}
+
+// WithArchiveLimits sets the ZIP bomb limits for archives passed to
+// ProcessCSVFile and ProcessCSVFiles. Zero values use the archive defaults.
+func WithArchiveLimits(limits archive.Limits) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.archives = limits
+	}
+}
+
+// WithHealthMonitor makes HealthCheck report the monitor's dependency
+// statuses. A nil monitor reports the service as healthy.
+func WithHealthMonitor(m *health.Monitor) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.health = m
+	}
+}
+
+// WithMetrics records row outcomes, save latency and in-flight imports.
+// A nil set records nothing.
+func WithMetrics(m *metrics.Metrics) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.metrics = m
+	}
+}
+
+// WithTracer traces imports: parsing, duplicate detection, validation,
+// conversion and each DB batch. A nil tracer records nothing.
+func WithTracer(t *tracing.Tracer) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.tracer = t
+	}
+}
+
+// WithLimiter admits imports under the concurrent import limits and daily
+// record quotas, and reports their use in GetQuotaUsage. Request rates are
+// enforced by the limiter's interceptor. A nil limiter allows everything.
+func WithLimiter(l *ratelimit.Limiter) ServiceOption {
+	return func(s *DataProcessorService) {
+		s.limits = l
+	}
+}
+
+// NewDataProcessorServiceWithOptions creates a service with the default
+// parser and validator, then applies the given options
+func NewDataProcessorServiceWithOptions(dbClient DBClient, opts ...ServiceOption) *DataProcessorService {
+	s := NewDataProcessorService(dbClient)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
\ No newline at end of file
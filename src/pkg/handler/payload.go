@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// payloadRequest is implemented by the requests that carry CSV data inline
+type payloadRequest interface {
+	GetCsvData() string
+	GetCsvBytes() []byte
+	GetEncoding() pb.Encoding
+	GetAccountId() string
+}
+
+// decodedRequest presents a request whose CSV came as csv_bytes to the request
+// validators, which check csv_data
+type decodedRequest struct {
+	csvData   string
+	accountID string
+}
+
+func (r decodedRequest) GetCsvData() string   { return r.csvData }
+func (r decodedRequest) GetAccountId() string { return r.accountID }
+
+// csvPayload returns the request's CSV as UTF-8 together with the request to
+// validate. csv_bytes is decoded the same way as ParseFile reads files; it
+// cannot be combined with csv_data.
+func csvPayload(req payloadRequest) (string, interface{}, error) {
+	if len(req.GetCsvBytes()) == 0 {
+		return req.GetCsvData(), req, nil
+	}
+	if req.GetCsvData() != "" {
+		return "", nil, invalidArgument("csv_bytes", "set either csv_data or csv_bytes, not both")
+	}
+
+	text, err := parser.Decode(req.GetCsvBytes(), parser.Encoding(req.GetEncoding()))
+	if err != nil {
+		return "", nil, invalidArgument("csv_bytes", err.Error())
+	}
+	return text, decodedRequest{csvData: text, accountID: req.GetAccountId()}, nil
+}
\ No newline at end of file
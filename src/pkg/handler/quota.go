@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GetQuotaUsage reports the use of the global limits and of each account's
+// limits, or of one account. Keys limited to some accounts must name one of
+// them.
+func (s *DataProcessorService) GetQuotaUsage(ctx context.Context, req *pb.GetQuotaUsageRequest) (*pb.GetQuotaUsageResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is nil")
+	}
+	if req.AccountId != "" {
+		if err := s.validator.ValidateAccountID(req.AccountId); err != nil {
+			return nil, err
+		}
+	}
+	if err := auth.Authorize(ctx, req.AccountId); err != nil {
+		return nil, err
+	}
+	if s.limits == nil {
+		return nil, status.Error(codes.FailedPrecondition, "rate limits are not configured")
+	}
+
+	usages := s.limits.Usage(req.AccountId)
+	resp := &pb.GetQuotaUsageResponse{Global: quotaUsageToProto(usages[0])}
+	for _, u := range usages[1:] {
+		resp.Accounts = append(resp.Accounts, quotaUsageToProto(u))
+	}
+	return resp, nil
+}
+
+func quotaUsageToProto(u ratelimit.Usage) *pb.QuotaUsage {
+	return &pb.QuotaUsage{
+		AccountId:              u.AccountID,
+		RequestsPerSecondLimit: u.Limits.RequestsPerSecond,
+		BurstLimit:             int32(u.Limits.Burst),
+		ConcurrentImports:      int32(u.ConcurrentImports),
+		ConcurrentImportsLimit: int32(u.Limits.ConcurrentImports),
+		RecordsToday:           u.RecordsToday,
+		RecordsPerDayLimit:     u.Limits.RecordsPerDay,
+		Day:                    u.Day,
+		ThrottledRequests:      u.ThrottledRequests,
+		RejectedImports:        u.RejectedImports,
+	}
+}
\ No newline at end of file
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorList collects record errors both as legacy strings and as typed errors
+type errorList struct {
+	messages []string
+	records  []*pb.RecordError
+}
+
+// add records an error in both forms
+func (l *errorList) add(message string, e *recorderr.Error) {
+	l.messages = append(l.messages, message)
+	l.records = append(l.records, recordErrorToProto(e))
+}
+
+// addTyped records a typed-only entry, such as an informational notice
+// that has no legacy string equivalent
+func (l *errorList) addTyped(e *recorderr.Error) {
+	l.records = append(l.records, recordErrorToProto(e))
+}
+
+// recordError returns the typed form of a failed save result
+func (r saveResult) recordError(lineNumber, recordIndex int) *recorderr.Error {
+	var e *recorderr.Error
+	if r.stage == deadletter.StageValidation {
+		e = recorderr.From(r.err, recorderr.CodeValidationFailed)
+	} else if r.stage == deadletter.StageConversion {
+		cause := recorderr.From(r.err, recorderr.CodeConversionFailed)
+		e = recorderr.Wrap(recorderr.CodeConversionFailed, cause.Field, r.err)
+	} else {
+		e = recorderr.Wrap(recorderr.CodeSaveFailed, "", r.err)
+		e.Retryable = r.class == ErrorClassTransient
+	}
+	return e.At(lineNumber, recordIndex)
+}
+
+// lineNumber returns the CSV line of a record, assuming a header row when the
+// parser did not record one
+func lineNumber(record parser.ActualETCRecord, index int) int {
+	if record.LineNumber > 0 {
+		return record.LineNumber
+	}
+	return index + 2 // +2 for header and 1-based indexing
+}
+
+// duplicateNotice returns an info-level error pointing at the first occurrence
+// of a duplicated record
+func duplicateNotice(records []parser.ActualETCRecord, index, first int) *recorderr.Error {
+	at := fmt.Sprintf("line %d", lineNumber(records[first], first))
+	return matchNotice(dedup.MatchDuplicate, records[first], records[index], at).At(lineNumber(records[index], index), index)
+}
+
+// recordErrorToProto converts a structured record error to its API form
+func recordErrorToProto(e *recorderr.Error) *pb.RecordError {
+	return &pb.RecordError{
+		Code:        pb.ErrorCode(e.Code),
+		LineNumber:  int32(e.LineNumber),
+		RecordIndex: int32(e.RecordIndex),
+		Field:       e.Field,
+		Severity:    pb.Severity(e.Severity),
+		Message:     e.Message,
+		Retryable:   e.Retryable,
+		Rule:        e.Rule,
+	}
+}
+
+// invalidArgument returns an InvalidArgument status that carries a RecordError
+// detail naming the offending request field
+func invalidArgument(field, message string) error {
+	st := status.New(codes.InvalidArgument, message)
+	detail := recordErrorToProto(recorderr.New(recorderr.CodeInvalidRequest, field, message))
+	if withDetails, err := st.WithDetails(detail); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
\ No newline at end of file
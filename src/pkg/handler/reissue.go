@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+)
+
+// Upserter is implemented by DB clients that can replace a stored record.
+// When the DB client supports it, records are saved by match key so that
+// rows re-issued by the card issuer update the stored trip instead of
+// adding a new one.
+type Upserter interface {
+	UpsertETCData(matchKey string, data interface{}) (replaced bool, err error)
+}
+
+// rowChecker classifies each record of one import against the earlier ones,
+// combining the exact duplicate policy with optional fuzzy re-issue matching
+type rowChecker struct {
+	policy  *dedup.Policy
+	tracker *dedup.Tracker
+	fuzzy   *dedup.FuzzyIndex
+}
+
+// newRowChecker creates a checker for an account's records. When they are
+// saved, re-issued rows are only matched if the DB client can replace the
+// earlier row; otherwise they would be inserted next to it, so they are left
+// to the duplicate policy.
+func (s *DataProcessorService) newRowChecker(accountID string, saving bool) *rowChecker {
+	policy := s.duplicates.For(accountID)
+	c := &rowChecker{policy: policy, tracker: dedup.NewTracker(policy)}
+	if s.fuzzy != nil && policy.Fuzzy() && (!saving || s.upserts()) {
+		c.fuzzy = dedup.NewFuzzyIndex(s.fuzzy)
+	}
+	return c
+}
+
+// upserts reports whether the DB client can replace a stored record
+func (s *DataProcessorService) upserts() bool {
+	switch s.dbClient.(type) {
+	case Upserter, ContextUpserter:
+		return true
+	}
+	return false
+}
+
+// check returns how a record relates to earlier records. A correction or
+// status change takes precedence over an exact duplicate, so a re-issued
+// row is never skipped when fuzzy matching is enabled. Fuzzy matching only
+// applies when the account's duplicate strategy allows it.
+func (c *rowChecker) check(record parser.ActualETCRecord, index int) dedup.Match {
+	first, dup := c.tracker.Observe(record, index)
+
+	match := dedup.Match{Index: index, Kind: dedup.MatchNone, Key: c.policy.MatchKey(record)}
+	if c.fuzzy != nil {
+		match = c.fuzzy.Observe(record, index)
+	}
+
+	if dup && match.Kind == dedup.MatchNone {
+		match.Index = first
+		match.Kind = dedup.MatchDuplicate
+	}
+	return match
+}
+
+// recordCheck is a record's match against earlier records of the same import,
+// with the notice reported for it (nil when the record matched nothing)
+type recordCheck struct {
+	match  dedup.Match
+	notice *recorderr.Error
+}
+
+// classify checks records[index] against the earlier records
+func (c *rowChecker) classify(records []parser.ActualETCRecord, index int) recordCheck {
+	match := c.check(records[index], index)
+	switch match.Kind {
+	case dedup.MatchNone:
+		return recordCheck{match: match}
+	case dedup.MatchDuplicate:
+		return recordCheck{match: match, notice: duplicateNotice(records, index, match.Index)}
+	default:
+		return recordCheck{match: match, notice: reissueNotice(records, index, match)}
+	}
+}
+
+// reissueNotice returns an info-level error describing a correction or status
+// change of an earlier record
+func reissueNotice(records []parser.ActualETCRecord, index int, match dedup.Match) *recorderr.Error {
+	earlier := records[match.Index]
+	at := fmt.Sprintf("line %d", lineNumber(earlier, match.Index))
+	return matchNotice(match.Kind, earlier, records[index], at).At(lineNumber(records[index], index), index)
+}
+
+// matchNotice returns an unlocated info-level error describing how a record
+// relates to an earlier one; earlierAt says where the earlier record is
+func matchNotice(kind dedup.MatchKind, earlier, record parser.ActualETCRecord, earlierAt string) *recorderr.Error {
+	code := recorderr.CodeDuplicate
+	switch kind {
+	case dedup.MatchCorrection:
+		code = recorderr.CodeCorrection
+	case dedup.MatchStatusChange:
+		code = recorderr.CodeStatusChange
+	}
+
+	message := fmt.Sprintf("%s of record at %s", kind, earlierAt)
+	if kind == dedup.MatchCorrection {
+		message = fmt.Sprintf("%s (amount %d -> %d)", message, earlier.ETCAmount, record.ETCAmount)
+	}
+
+	notice := recorderr.New(code, "", message)
+	notice.Severity = recorderr.SeverityInfo
+	return notice
+}
\ No newline at end of file
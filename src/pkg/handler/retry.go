@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorClass labels a failure as transient (worth retrying) or permanent
+type ErrorClass int
+
+const (
+	// ErrorClassPermanent means retrying will not help
+	ErrorClassPermanent ErrorClass = iota
+	// ErrorClassTransient means the operation may succeed if retried
+	ErrorClassTransient
+)
+
+// String returns the label used in error messages
+func (c ErrorClass) String() string {
+	if c == ErrorClassTransient {
+		return "transient"
+	}
+	return "permanent"
+}
+
+// RetryPolicy controls how failed database saves are retried
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	RetryableCodes map[codes.Code]bool
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2.0,
+		RetryableCodes: map[codes.Code]bool{
+			codes.Unavailable:       true,
+			codes.DeadlineExceeded:  true,
+			codes.ResourceExhausted: true,
+			codes.Aborted:           true,
+		},
+	}
+}
+
+// NewRetryPolicy creates a retry policy from configuration values.
+// Zero values and an empty code list fall back to DefaultRetryPolicy.
+func NewRetryPolicy(maxAttempts int, initialBackoff, maxBackoff time.Duration, multiplier float64, retryableCodes []string) (*RetryPolicy, error) {
+	policy := DefaultRetryPolicy()
+
+	if maxAttempts > 0 {
+		policy.MaxAttempts = maxAttempts
+	}
+	if initialBackoff > 0 {
+		policy.InitialBackoff = initialBackoff
+	}
+	if maxBackoff > 0 {
+		policy.MaxBackoff = maxBackoff
+	}
+	if multiplier > 0 {
+		policy.Multiplier = multiplier
+	}
+
+	if len(retryableCodes) > 0 {
+		policy.RetryableCodes = make(map[codes.Code]bool)
+		for _, name := range retryableCodes {
+			code, err := ParseCode(name)
+			if err != nil {
+				return nil, err
+			}
+			policy.RetryableCodes[code] = true
+		}
+	}
+
+	return policy, nil
+}
+
+// ParseCode parses a gRPC code name such as "UNAVAILABLE" or "DeadlineExceeded"
+func ParseCode(name string) (codes.Code, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.ToLower(c.String()) == normalized {
+			return c, nil
+		}
+	}
+	return codes.Unknown, fmt.Errorf("unknown gRPC code: %s", name)
+}
+
+// Classify labels an error as transient or permanent based on its gRPC code
+func (p *RetryPolicy) Classify(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassPermanent
+	}
+	if p.RetryableCodes[status.Code(err)] {
+		return ErrorClassTransient
+	}
+	return ErrorClassPermanent
+}
+
+// Backoff returns the wait time before the given retry (1-based)
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := float64(p.InitialBackoff)
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || time.Duration(backoff) < p.MaxBackoff); i++ {
+		backoff *= p.Multiplier
+	}
+	if p.MaxBackoff > 0 && time.Duration(backoff) > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// Do runs fn until it succeeds, fails permanently, or attempts are exhausted.
+// It returns the number of attempts made and the classification of the last error.
+func (p *RetryPolicy) Do(ctx context.Context, fn func() error) (int, ErrorClass, error) {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	attempts := 0
+	for {
+		attempts++
+		err := fn()
+		if err == nil {
+			return attempts, ErrorClassPermanent, nil
+		}
+
+		class := p.Classify(err)
+		if class == ErrorClassPermanent || attempts >= maxAttempts {
+			return attempts, class, err
+		}
+
+		timer := time.NewTimer(p.Backoff(attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, class, err
+		case <-timer.C:
+		}
+	}
+}
\ No newline at end of file
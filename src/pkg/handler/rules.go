@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+)
+
+// ruleSetName returns the name of the rule set applied to an account, or ""
+// when the rules engine is disabled
+func (s *DataProcessorService) ruleSetName(accountID string) string {
+	reg := s.rules.Load()
+	if reg == nil {
+		return ""
+	}
+	return reg.For(accountID).Name
+}
+
+// SetRules replaces the validation rules, as WithRules does, while the
+// service is running. Requests already checking records may finish with the
+// previous rules.
+func (s *DataProcessorService) SetRules(reg *rules.Registry) {
+	s.rules.Store(reg)
+}
+
+// checkRules evaluates the account's rule set against a record. It returns the
+// violations, located at the record, and a failed result when any of them is
+// error-severity. Records are not checked when the rules engine is disabled.
+func (s *DataProcessorService) checkRules(record parser.ActualETCRecord, index int, accountID string) ([]*recorderr.Error, *saveResult) {
+	reg := s.rules.Load()
+	if reg == nil {
+		return nil, nil
+	}
+
+	var violations []*recorderr.Error
+	var failed *saveResult
+	for _, v := range reg.For(accountID).Evaluate(record) {
+		v = v.At(lineNumber(record, index), index)
+		violations = append(violations, v)
+		if v.Severity == recorderr.SeverityError && failed == nil {
+			failed = &saveResult{stage: deadletter.StageValidation, class: ErrorClassPermanent, err: v}
+		}
+	}
+	return violations, failed
+}
+
+// addRuleViolations records rule violations; error-severity ones also get a
+// legacy error string
+func (l *errorList) addRuleViolations(recordNumber int, violations []*recorderr.Error) {
+	for _, v := range violations {
+		if v.Severity == recorderr.SeverityError {
+			l.add(fmt.Sprintf("Record %d: validation failed (%s): %v", recordNumber, v.Rule, v), v)
+		} else {
+			l.addTyped(v)
+		}
+	}
+}
\ No newline at end of file
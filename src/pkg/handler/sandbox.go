@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"os"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// confinePath checks a client-supplied file path against the sandbox and
+// returns the path to open. Violations are PermissionDenied. Without a
+// sandbox the path is returned unchanged.
+func (s *DataProcessorService) confinePath(path string) (string, error) {
+	if s.sandbox == nil {
+		return path, nil
+	}
+	resolved, err := s.sandbox.Resolve(path)
+	return resolved, sandboxError("csv_file_path", path, err)
+}
+
+// confineDir checks a client-supplied directory against the sandbox before it
+// is listed. Without a sandbox the path is returned unchanged.
+func (s *DataProcessorService) confineDir(field, path string) (string, error) {
+	if s.sandbox == nil {
+		return path, nil
+	}
+	resolved, err := s.sandbox.ResolveDir(path)
+	return resolved, sandboxError(field, path, err)
+}
+
+// sandboxError converts a sandbox error into a gRPC status
+func sandboxError(field, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var violation *sandbox.Violation
+	if errors.As(err, &violation) {
+		return status.Errorf(codes.PermissionDenied, "%s rejected: %s", field, violation.Reason)
+	}
+	if os.IsNotExist(err) {
+		return status.Errorf(codes.NotFound, "file not found: %s", path)
+	}
+	return status.Error(codes.Internal, "failed to check file")
+}
\ No newline at end of file
@@ -4,11 +4,24 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/health"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
 	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -33,48 +46,111 @@ type Parser interface {
 // DataProcessorService implements the gRPC service
 type DataProcessorService struct {
 	pb.UnimplementedDataProcessorServiceServer
-	dbClient  DBClient
-	parser    Parser
-	validator Validator
+	dbClient    DBClient
+	parser      Parser
+	validator   Validator
+	retryPolicy *RetryPolicy
+	deadLetters deadletter.Store
+	duplicates  *dedup.PolicySet
+	fuzzy       *dedup.FuzzyMatcher
+	rules       atomic.Pointer[rules.Registry]
+	sandbox     *sandbox.Sandbox
+	batch       BatchLimits
+	archives    archive.Limits
+	health      *health.Monitor
+	metrics     *metrics.Metrics
+	tracer      *tracing.Tracer
+	limits      *ratelimit.Limiter
+	started     time.Time
+	jobs        atomic.Int64
 }
 
 // NewDataProcessorService creates a new service instance
 func NewDataProcessorService(dbClient DBClient) *DataProcessorService {
 	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    parser.NewETCCSVParser(),
-		validator: NewDefaultValidator(),
+		dbClient:    dbClient,
+		parser:      parser.NewETCCSVParser(),
+		validator:   NewDefaultValidator(),
+		retryPolicy: DefaultRetryPolicy(),
+		duplicates:  dedup.DefaultPolicySet(),
+		started:     time.Now(),
 	}
 }
 
 // NewDataProcessorServiceWithValidator creates a service with custom validator
 func NewDataProcessorServiceWithValidator(dbClient DBClient, validator Validator) *DataProcessorService {
 	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    parser.NewETCCSVParser(),
-		validator: validator,
+		dbClient:    dbClient,
+		parser:      parser.NewETCCSVParser(),
+		validator:   validator,
+		retryPolicy: DefaultRetryPolicy(),
+		duplicates:  dedup.DefaultPolicySet(),
+		started:     time.Now(),
 	}
 }
 
 // NewDataProcessorServiceWithDependencies creates a service with custom dependencies
 func NewDataProcessorServiceWithDependencies(dbClient DBClient, csvParser Parser, validator Validator) *DataProcessorService {
 	return &DataProcessorService{
-		dbClient:  dbClient,
-		parser:    csvParser,
-		validator: validator,
+		dbClient:    dbClient,
+		parser:      csvParser,
+		validator:   validator,
+		retryPolicy: DefaultRetryPolicy(),
+		duplicates:  dedup.DefaultPolicySet(),
+		started:     time.Now(),
 	}
 }
 
 // ProcessCSVFile processes a CSV file from filesystem
 func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.ProcessCSVFileRequest) (*pb.ProcessCSVFileResponse, error) {
+	// Only keys allowed for the account may import for it
+	if err := auth.Authorize(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	// Confine the path to the sandbox before anything touches the filesystem
+	filePath := req.GetCsvFilePath()
+	if filePath != "" {
+		confined, err := s.confinePath(filePath)
+		if err != nil {
+			return nil, err
+		}
+		filePath = confined
+	}
+
 	// Validate request using validator
 	if err := ValidateProcessCSVFileRequest(req, s.validator); err != nil {
 		return nil, err
 	}
 
+	return s.processFile(ctx, req, filePath)
+}
+
+// processFile parses and imports a checked file. req.CsvFilePath is only used
+// for reporting; filePath is the file that is read.
+func (s *DataProcessorService) processFile(ctx context.Context, req *pb.ProcessCSVFileRequest, filePath string) (*pb.ProcessCSVFileResponse, error) {
+	done, err := s.startJob(ctx, req.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	// ZIP archives are imported entry by entry
+	if archive.IsZip(filePath) {
+		return s.processArchive(ctx, req, filePath)
+	}
+
+	ctx, span := s.startImport(ctx, "etc.import", req.CsvFilePath, metrics.FormatCSV, req.AccountId)
+	defer span.End()
+
 	// Parse CSV file
-	records, err := s.parser.ParseFile(req.CsvFilePath)
+	_, parseSpan := s.tracer.Start(ctx, "etc.parse", slog.String("etc.format", metrics.FormatCSV))
+	records, err := s.parser.ParseFile(filePath)
+	endParse(parseSpan, len(records), err)
 	if err != nil {
+		span.RecordError(err)
+		s.metrics.ParseFailed(metrics.FormatCSV)
+		logParseFailure(ctx, req.CsvFilePath, metrics.FormatCSV, req.AccountId, err)
 		return &pb.ProcessCSVFileResponse{
 			Success: false,
 			Message: fmt.Sprintf("Failed to parse CSV file: %v", err),
@@ -82,58 +158,124 @@ func (s *DataProcessorService) ProcessCSVFile(ctx context.Context, req *pb.Proce
 				TotalRecords: 0,
 			},
 			Errors: []string{err.Error()},
+			RecordErrors: []*pb.RecordError{
+				recordErrorToProto(recorderr.Wrap(recorderr.CodeParseFailed, "csv", err)),
+			},
 		}, nil
 	}
 
+	// Count the records against the daily quotas
+	if err := s.limits.ReserveRecords(ctx, req.AccountId, len(records)); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	// Process records
-	stats, errors := s.processRecords(ctx, records, req.AccountId, req.SkipDuplicates)
+	importID := deadletter.NewID()
+	stats, errors := s.processRecords(ctx, records, importID, req.AccountId, req.SkipDuplicates)
+	s.observeImport(metrics.FormatCSV, stats)
+	logImport(ctx, req.CsvFilePath, metrics.FormatCSV, req.AccountId, importID, stats)
+	endImport(span, importID, stats)
 
 	return &pb.ProcessCSVFileResponse{
-		Success: stats.SavedRecords > 0,
-		Message: fmt.Sprintf("Processed %d records from file", stats.TotalRecords),
-		Stats:   stats,
-		Errors:  errors,
+		Success:           stats.SavedRecords > 0,
+		Message:           fmt.Sprintf("Processed %d records from file", stats.TotalRecords),
+		Stats:             stats,
+		Errors:            errors.messages,
+		ImportId:          importID,
+		RecordErrors:      errors.records,
+		DuplicateStrategy: string(s.duplicates.For(req.AccountId).Strategy),
+		RuleSet:           s.ruleSetName(req.AccountId),
 	}, nil
 }
 
 // ProcessCSVData processes CSV data directly
 func (s *DataProcessorService) ProcessCSVData(ctx context.Context, req *pb.ProcessCSVDataRequest) (*pb.ProcessCSVDataResponse, error) {
+	if err := auth.Authorize(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	// Decode csv_bytes if the raw file was sent
+	csvData, validated, err := csvPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate request using validator
-	if err := ValidateProcessCSVDataRequest(req, s.validator); err != nil {
+	if err := ValidateProcessCSVDataRequest(validated, s.validator); err != nil {
+		return nil, err
+	}
+
+	done, err := s.startJob(ctx, req.AccountId)
+	if err != nil {
 		return nil, err
 	}
+	defer done()
+
+	ctx, span := s.startImport(ctx, "etc.import", "", metrics.FormatCSV, req.AccountId)
+	defer span.End()
 
 	// Parse CSV data
-	reader := strings.NewReader(req.CsvData)
+	_, parseSpan := s.tracer.Start(ctx, "etc.parse", slog.String("etc.format", metrics.FormatCSV))
+	reader := strings.NewReader(csvData)
 	records, err := s.parser.Parse(reader)
+	endParse(parseSpan, len(records), err)
 	if err != nil {
 		// All parsing errors should be treated as invalid format for API
+		span.RecordError(err)
+		s.metrics.ParseFailed(metrics.FormatCSV)
+		logParseFailure(ctx, "", metrics.FormatCSV, req.AccountId, err)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid CSV format: %v", err)
 	}
 
+	// Count the records against the daily quotas
+	if err := s.limits.ReserveRecords(ctx, req.AccountId, len(records)); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
 	// Process records
-	stats, errors := s.processRecords(ctx, records, req.AccountId, req.SkipDuplicates)
+	importID := deadletter.NewID()
+	stats, errors := s.processRecords(ctx, records, importID, req.AccountId, req.SkipDuplicates)
+	s.observeImport(metrics.FormatCSV, stats)
+	logImport(ctx, "", metrics.FormatCSV, req.AccountId, importID, stats)
+	endImport(span, importID, stats)
 
 	return &pb.ProcessCSVDataResponse{
-		Success: stats.SavedRecords > 0,
-		Message: fmt.Sprintf("Processed %d records", stats.TotalRecords),
-		Stats:   stats,
-		Errors:  errors,
+		Success:           stats.SavedRecords > 0,
+		Message:           fmt.Sprintf("Processed %d records", stats.TotalRecords),
+		Stats:             stats,
+		Errors:            errors.messages,
+		ImportId:          importID,
+		RecordErrors:      errors.records,
+		DuplicateStrategy: string(s.duplicates.For(req.AccountId).Strategy),
+		RuleSet:           s.ruleSetName(req.AccountId),
 	}, nil
 }
 
 // ValidateCSVData validates CSV data without saving
 func (s *DataProcessorService) ValidateCSVData(ctx context.Context, req *pb.ValidateCSVDataRequest) (*pb.ValidateCSVDataResponse, error) {
+	if err := auth.Authorize(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	// Decode csv_bytes if the raw file was sent
+	csvData, validated, err := csvPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate request using validator
-	if err := ValidateValidateCSVDataRequest(req, s.validator); err != nil {
+	if err := ValidateValidateCSVDataRequest(validated, s.validator); err != nil {
 		return nil, err
 	}
 
 	// Parse CSV data
-	reader := strings.NewReader(req.CsvData)
+	reader := strings.NewReader(csvData)
 	records, err := s.parser.Parse(reader)
 
 	var validationErrors []*pb.ValidationError
+	var recordErrors []*pb.RecordError
 
 	if err != nil {
 		// Parse error means invalid CSV
@@ -147,61 +289,94 @@ func (s *DataProcessorService) ValidateCSVData(ctx context.Context, req *pb.Vali
 				},
 			},
 			TotalRecords: 0,
+			RecordErrors: []*pb.RecordError{
+				recordErrorToProto(recorderr.Wrap(recorderr.CodeParseFailed, "csv", err)),
+			},
 		}, nil
 	}
 
-	// Validate each record
-	duplicateMap := make(map[string]int)
+	// Validate each record, detecting duplicates and re-issued rows the same way
+	// as processing into a DB client that can replace records
+	policy := s.duplicates.For(req.AccountId)
+	checker := s.newRowChecker(req.AccountId, false)
 	duplicateCount := int32(0)
+	correctionCount := int32(0)
+	statusChangeCount := int32(0)
 
 	for i, record := range records {
-		// Create a unique key for duplicate detection
-		key := fmt.Sprintf("%s_%s_%s_%s_%d",
-			record.EntryDate, record.EntryTime,
-			record.ExitDate, record.ExitTime,
-			record.ETCAmount)
-
-		if _, exists := duplicateMap[key]; exists {
+		switch match := checker.check(record, i); match.Kind {
+		case dedup.MatchDuplicate:
 			duplicateCount++
-			duplicateMap[key]++
-		} else {
-			duplicateMap[key] = 1
+			recordErrors = append(recordErrors, recordErrorToProto(duplicateNotice(records, i, match.Index)))
+		case dedup.MatchCorrection:
+			correctionCount++
+			recordErrors = append(recordErrors, recordErrorToProto(reissueNotice(records, i, match)))
+		case dedup.MatchStatusChange:
+			statusChangeCount++
+			recordErrors = append(recordErrors, recordErrorToProto(reissueNotice(records, i, match)))
 		}
 
 		// Validate record
+		var parseErr *recorderr.Error
 		if err := s.parser.ValidateRecord(record); err != nil {
+			parseErr = recorderr.From(err, recorderr.CodeValidationFailed).At(lineNumber(record, i), i)
 			validationErrors = append(validationErrors, &pb.ValidationError{
-				LineNumber:  int32(i + 2), // +2 for header and 1-based indexing
-				Field:       "",
-				Message:     err.Error(),
-				RecordData:  fmt.Sprintf("%v", record),
+				LineNumber: int32(parseErr.LineNumber),
+				Field:      parseErr.Field,
+				Message:    err.Error(),
+				RecordData: fmt.Sprintf("%v", record),
 			})
+			recordErrors = append(recordErrors, recordErrorToProto(parseErr))
+		}
+
+		// Apply the account's validation rules; only error-severity violations
+		// make the data invalid
+		violations, _ := s.checkRules(record, i, req.AccountId)
+		for _, v := range violations {
+			if parseErr != nil && v.Field == parseErr.Field {
+				continue // already reported by the parser
+			}
+			if v.Severity == recorderr.SeverityError {
+				validationErrors = append(validationErrors, &pb.ValidationError{
+					LineNumber: int32(v.LineNumber),
+					Field:      v.Field,
+					Message:    v.Message,
+					RecordData: fmt.Sprintf("%v", record),
+				})
+			}
+			recordErrors = append(recordErrors, recordErrorToProto(v))
 		}
 	}
 
 	return &pb.ValidateCSVDataResponse{
-		IsValid:        len(validationErrors) == 0,
-		Errors:         validationErrors,
-		DuplicateCount: duplicateCount,
-		TotalRecords:   int32(len(records)),
+		IsValid:           len(validationErrors) == 0,
+		Errors:            validationErrors,
+		DuplicateCount:    duplicateCount,
+		TotalRecords:      int32(len(records)),
+		RecordErrors:      recordErrors,
+		DuplicateStrategy: string(policy.Strategy),
+		CorrectionCount:   correctionCount,
+		StatusChangeCount: statusChangeCount,
+		RuleSet:           s.ruleSetName(req.AccountId),
 	}, nil
 }
 
-// HealthCheck returns the service health status
-func (s *DataProcessorService) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
-	return &pb.HealthCheckResponse{
-		Status:    "healthy",
-		Version:   version,
-		Timestamp: time.Now().Unix(),
-		Details: map[string]string{
-			"service": "etc_data_processor",
-			"uptime":  "running",
-		},
-	}, nil
+// processRecords processes parsed records and saves to database
+func (s *DataProcessorService) processRecords(ctx context.Context, records []parser.ActualETCRecord, importID, accountID string, skipDuplicates bool) (*pb.ProcessingStats, *errorList) {
+	_, span := s.tracer.Start(ctx, "etc.dedup", slog.Int("etc.records", len(records)))
+	checker := s.newRowChecker(accountID, true)
+	checks := make([]recordCheck, len(records))
+	for i := range records {
+		checks[i] = checker.classify(records, i)
+	}
+	span.End()
+	return s.importRecords(ctx, records, checks, importID, accountID, skipDuplicates)
 }
 
-// processRecords processes parsed records and saves to database
-func (s *DataProcessorService) processRecords(ctx context.Context, records []parser.ActualETCRecord, accountID string, skipDuplicates bool) (*pb.ProcessingStats, []string) {
+// importRecords saves records whose duplicate checks were already made. The
+// records are handled in DB batches: each batch is checked against the rules,
+// converted, then saved, and each step is traced as its own span.
+func (s *DataProcessorService) importRecords(ctx context.Context, records []parser.ActualETCRecord, checks []recordCheck, importID, accountID string, skipDuplicates bool) (*pb.ProcessingStats, *errorList) {
 	stats := &pb.ProcessingStats{
 		TotalRecords:   int32(len(records)),
 		SavedRecords:   0,
@@ -209,61 +384,245 @@ func (s *DataProcessorService) processRecords(ctx context.Context, records []par
 		ErrorRecords:   0,
 	}
 
-	var errors []string
-	processedKeys := make(map[string]bool)
+	errors := &errorList{}
+
+	size := s.batchLimits().SaveBatchSize
+	for start := 0; start < len(records); start += size {
+		end := min(start+size, len(records))
+		if !s.importBatchRecords(ctx, records, checks, start, end, importID, accountID, skipDuplicates, stats, errors) {
+			break
+		}
+	}
+
+	return stats, errors
+}
+
+// preparedRecord is a record checked against the rules and converted for saving
+type preparedRecord struct {
+	skip       bool
+	violations []*recorderr.Error
+	failed     *saveResult // an error-severity rule violation or failed conversion
+	data       map[string]interface{}
+}
+
+// importBatchRecords imports records[start:end] into stats and errors. It
+// returns false when the import was cancelled.
+func (s *DataProcessorService) importBatchRecords(ctx context.Context, records []parser.ActualETCRecord, checks []recordCheck, start, end int, importID, accountID string, skipDuplicates bool, stats *pb.ProcessingStats, errors *errorList) bool {
+	prepared := s.prepareRecords(ctx, records, checks, start, end, accountID, skipDuplicates)
+
+	saveCtx, span := s.tracer.Start(ctx, "etc.db.save", slog.Int("etc.records", end-start))
+	saved, failed, retried := stats.SavedRecords, stats.ErrorRecords, stats.RetriedRecords
+	defer func() {
+		endSave(span, stats.SavedRecords-saved, stats.ErrorRecords-failed, stats.RetriedRecords-retried)
+	}()
+
+	for i := start; i < end; i++ {
+		record := records[i]
 
-	for i, record := range records {
 		// Check context cancellation
 		if ctx.Err() != nil {
-			errors = append(errors, fmt.Sprintf("Processing cancelled at record %d", i))
+			cancelled := recorderr.Wrap(recorderr.CodeCancelled, "", ctx.Err())
+			errors.add(fmt.Sprintf("Processing cancelled at record %d", i), cancelled.At(lineNumber(record, i), i))
 			stats.ErrorRecords = int32(len(records) - i)
-			break
+			return false
 		}
 
-		// Create unique key for duplicate detection
-		key := fmt.Sprintf("%s_%s_%s_%s_%d_%s",
-			record.EntryDate, record.EntryTime,
-			record.ExitDate, record.ExitTime,
-			record.ETCAmount, record.CardNumber)
+		// Skip duplicates if requested; re-issued rows replace the earlier row
+		match := checks[i].match
+		switch match.Kind {
+		case dedup.MatchDuplicate:
+			if skipDuplicates {
+				stats.SkippedRecords++
+				errors.addTyped(checks[i].notice)
+				continue
+			}
+		case dedup.MatchCorrection:
+			stats.CorrectedRecords++
+			errors.addTyped(checks[i].notice)
+		case dedup.MatchStatusChange:
+			stats.StatusChanges++
+			errors.addTyped(checks[i].notice)
+		}
 
-		// Skip duplicates if requested
-		if skipDuplicates && processedKeys[key] {
-			stats.SkippedRecords++
+		// Apply the account's validation rules
+		p := prepared[i-start]
+		errors.addRuleViolations(i+1, p.violations)
+		if p.failed != nil && p.failed.stage == deadletter.StageValidation {
+			p.failed.addErrorStats(stats)
+			s.deadLetter(importID, accountID, record, match.Key, *p.failed)
 			continue
 		}
 
-		// Convert to simple format for saving
-		simpleRecord, err := s.parser.ConvertToSimpleRecord(record)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("Record %d: conversion failed: %v", i+1, err))
-			stats.ErrorRecords++
+		// Save the converted record, retrying transient failures
+		result := saveResult{}
+		if p.failed != nil {
+			result = *p.failed
+		} else {
+			result = s.saveData(saveCtx, p.data, match.Key)
+		}
+		result.addRetryStats(stats)
+		if result.err != nil {
+			errors.add(result.message(i+1), result.recordError(lineNumber(record, i), i))
+			result.addErrorStats(stats)
+			s.deadLetter(importID, accountID, record, match.Key, result)
 			continue
 		}
 
-		// Add account ID
-		dataToSave := map[string]interface{}{
-			"account_id":   accountID,
-			"date":        simpleRecord.Date,
-			"entry_ic":    simpleRecord.EntryIC,
-			"exit_ic":     simpleRecord.ExitIC,
-			"route":       simpleRecord.Route,
-			"vehicle_type": simpleRecord.VehicleType,
-			"amount":      simpleRecord.Amount,
-			"card_number": simpleRecord.CardNumber,
+		if result.replaced {
+			stats.UpdatedRecords++
 		}
+		stats.SavedRecords++
+	}
+	return true
+}
 
-		// Save to database
-		if s.dbClient != nil {
-			if err := s.dbClient.SaveETCData(dataToSave); err != nil {
-				errors = append(errors, fmt.Sprintf("Record %d: save failed: %v", i+1, err))
-				stats.ErrorRecords++
-				continue
-			}
+// prepareRecords checks records[start:end] against the account's rules, then
+// converts the records that passed. Skipped duplicates are neither checked nor
+// converted.
+func (s *DataProcessorService) prepareRecords(ctx context.Context, records []parser.ActualETCRecord, checks []recordCheck, start, end int, accountID string, skipDuplicates bool) []preparedRecord {
+	prepared := make([]preparedRecord, end-start)
+
+	_, span := s.tracer.Start(ctx, "etc.validate", slog.Int("etc.records", end-start))
+	violations, rejected := 0, 0
+	for i := start; i < end; i++ {
+		p := &prepared[i-start]
+		if checks[i].match.Kind == dedup.MatchDuplicate && skipDuplicates {
+			p.skip = true
+			continue
+		}
+		p.violations, p.failed = s.checkRules(records[i], i, accountID)
+		violations += len(p.violations)
+		if p.failed != nil {
+			rejected++
+		}
+	}
+	span.SetAttributes(slog.Int("etc.rules.violations", violations), slog.Int("etc.records.rejected", rejected))
+	span.End()
+
+	_, span = s.tracer.Start(ctx, "etc.convert", slog.Int("etc.records", end-start))
+	failed := 0
+	for i := start; i < end; i++ {
+		p := &prepared[i-start]
+		if p.skip || p.failed != nil {
+			continue
 		}
+		p.data, p.failed = s.convertRecord(records[i], accountID)
+		if p.failed != nil {
+			failed++
+		}
+	}
+	span.SetAttributes(slog.Int("etc.records.failed", failed))
+	span.End()
 
-		processedKeys[key] = true
-		stats.SavedRecords++
+	return prepared
+}
+
+// saveResult describes the outcome of converting and saving one record
+type saveResult struct {
+	stage    string
+	attempts int
+	class    ErrorClass
+	replaced bool
+	err      error
+}
+
+// message formats the per-record error string returned to callers
+func (r saveResult) message(recordNumber int) string {
+	if r.stage == deadletter.StageValidation {
+		return fmt.Sprintf("Record %d: validation failed (%s): %v", recordNumber, recorderr.From(r.err, recorderr.CodeValidationFailed).Rule, r.err)
 	}
+	if r.stage == deadletter.StageConversion {
+		return fmt.Sprintf("Record %d: conversion failed (%s): %v", recordNumber, r.class, r.err)
+	}
+	return fmt.Sprintf("Record %d: save failed (%s, %d attempts): %v", recordNumber, r.class, r.attempts, r.err)
+}
 
-	return stats, errors
+// addRetryStats records retry counters for the result
+func (r saveResult) addRetryStats(stats *pb.ProcessingStats) {
+	if r.attempts > 1 {
+		stats.RetriedRecords++
+		stats.RetryAttempts += int32(r.attempts - 1)
+	}
+}
+
+// addErrorStats records error counters for a failed result
+func (r saveResult) addErrorStats(stats *pb.ProcessingStats) {
+	stats.ErrorRecords++
+	if r.class == ErrorClassTransient {
+		stats.TransientErrors++
+	} else {
+		stats.PermanentErrors++
+	}
+}
+
+// saveRecord converts a parsed record and saves it, retrying transient DB failures
+func (s *DataProcessorService) saveRecord(ctx context.Context, record parser.ActualETCRecord, accountID, matchKey string) saveResult {
+	data, failed := s.convertRecord(record, accountID)
+	if failed != nil {
+		return *failed
+	}
+	return s.saveData(ctx, data, matchKey)
+}
+
+// convertRecord converts a record to the map saved by the DB client
+func (s *DataProcessorService) convertRecord(record parser.ActualETCRecord, accountID string) (map[string]interface{}, *saveResult) {
+	// Convert to simple format for saving
+	simpleRecord, err := s.parser.ConvertToSimpleRecord(record)
+	if err != nil {
+		return nil, &saveResult{stage: deadletter.StageConversion, class: ErrorClassPermanent, err: err}
+	}
+
+	// Add account ID, and the rest of the row for clients storing full records
+	return map[string]interface{}{
+		"account_id":       accountID,
+		"date":             simpleRecord.Date,
+		"entry_ic":         simpleRecord.EntryIC,
+		"exit_ic":          simpleRecord.ExitIC,
+		"route":            simpleRecord.Route,
+		"vehicle_type":     simpleRecord.VehicleType,
+		"amount":           simpleRecord.Amount,
+		"card_number":      simpleRecord.CardNumber,
+		"entry_date":       record.EntryDate,
+		"entry_time":       record.EntryTime,
+		"exit_date":        record.ExitDate,
+		"exit_time":        record.ExitTime,
+		"etc_amount":       record.ETCAmount,
+		"normal_amount":    record.NormalAmount,
+		"discount_applied": record.DiscountApplied,
+		"mileage":          record.Mileage,
+		"vehicle_class":    record.VehicleClass,
+		"vehicle_number":   record.VehicleNumber,
+		"notes":            record.Notes,
+	}, nil
+}
+
+// saveData saves a converted record, retrying transient failures.
+// DB clients that implement Upserter store the record under matchKey; clients
+// that implement ContextSaver or ContextUpserter receive ctx.
+func (s *DataProcessorService) saveData(ctx context.Context, dataToSave map[string]interface{}, matchKey string) saveResult {
+	if s.dbClient == nil {
+		return saveResult{stage: deadletter.StageSave}
+	}
+
+	replaced := false
+	attempts, class, err := s.retryPolicy.Do(ctx, func() error {
+		start := time.Now()
+		var err error
+		switch db := s.dbClient.(type) {
+		case ContextUpserter:
+			replaced, err = db.UpsertETCDataContext(ctx, matchKey, dataToSave)
+			s.metrics.ObserveSave("upsert", time.Since(start), err)
+		case Upserter:
+			replaced, err = db.UpsertETCData(matchKey, dataToSave)
+			s.metrics.ObserveSave("upsert", time.Since(start), err)
+		case ContextSaver:
+			err = db.SaveETCDataContext(ctx, dataToSave)
+			s.metrics.ObserveSave("save", time.Since(start), err)
+		default:
+			err = db.SaveETCData(dataToSave)
+			s.metrics.ObserveSave("save", time.Since(start), err)
+		}
+		return err
+	})
+	return saveResult{stage: deadletter.StageSave, attempts: attempts, class: class, replaced: replaced, err: err}
 }
\ No newline at end of file
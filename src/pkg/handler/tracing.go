@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// ContextSaver is implemented by DB clients that take the import's context,
+// such as a gRPC client to db_service. The context carries the trace of the
+// import; a client built with tracing.UnaryClientInterceptor sends it on as
+// W3C trace context. It is used instead of SaveETCData.
+type ContextSaver interface {
+	SaveETCDataContext(ctx context.Context, data interface{}) error
+}
+
+// ContextUpserter is Upserter taking the import's context, and is used
+// instead of UpsertETCData
+type ContextUpserter interface {
+	UpsertETCDataContext(ctx context.Context, matchKey string, data interface{}) (replaced bool, err error)
+}
+
+// startImport starts the span of one imported file, archive entry or payload.
+// file is empty for inline data.
+func (s *DataProcessorService) startImport(ctx context.Context, name, file, format, accountID string) (context.Context, *tracing.Span) {
+	attrs := []slog.Attr{
+		slog.String("etc.format", format),
+		slog.String("etc.account_id", accountID),
+	}
+	if file != "" {
+		attrs = append(attrs, slog.String("etc.file", file))
+	}
+	return s.tracer.Start(ctx, name, attrs...)
+}
+
+// endImport adds the import's counters to its span and ends it
+func endImport(span *tracing.Span, importID string, stats *pb.ProcessingStats) {
+	span.SetAttributes(
+		slog.String("etc.import_id", importID),
+		slog.Int("etc.records.total", int(stats.GetTotalRecords())),
+		slog.Int("etc.records.saved", int(stats.GetSavedRecords())),
+		slog.Int("etc.records.skipped", int(stats.GetSkippedRecords())),
+		slog.Int("etc.records.errors", int(stats.GetErrorRecords())),
+	)
+	span.End()
+}
+
+// endParse records the parse outcome and ends the span
+func endParse(span *tracing.Span, records int, err error) {
+	span.SetAttributes(slog.Int("etc.records", records))
+	span.RecordError(err)
+	span.End()
+}
+
+// endSave records a DB batch's outcome and ends the span. The span fails when
+// any record could not be saved.
+func endSave(span *tracing.Span, saved, failed, retried int32) {
+	span.SetAttributes(
+		slog.Int("etc.records.saved", int(saved)),
+		slog.Int("etc.records.failed", int(failed)),
+		slog.Int("etc.records.retried", int(retried)),
+	)
+	if failed > 0 {
+		span.SetError(fmt.Sprintf("%d records failed to save", failed))
+	}
+	span.End()
+}
\ No newline at end of file
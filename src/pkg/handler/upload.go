@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ProcessUploadedFile imports a file received over HTTP and spooled to path by
+// the caller. req.CsvFilePath is the client's file name: it selects CSV or ZIP
+// handling and appears in messages. path is not checked against the sandbox,
+// so it must be a file the caller created.
+func (s *DataProcessorService) ProcessUploadedFile(ctx context.Context, req *pb.ProcessCSVFileRequest, path string) (*pb.ProcessCSVFileResponse, error) {
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is nil")
+	}
+	if err := s.validator.ValidateAccountID(req.AccountId); err != nil {
+		return nil, err
+	}
+	if err := auth.Authorize(ctx, req.AccountId); err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(req.CsvFilePath)) {
+	case ".csv", ".zip":
+	default:
+		return nil, invalidArgument("file", "uploaded file must be a .csv or .zip file")
+	}
+	return s.processFile(ctx, req, path)
+}
\ No newline at end of file
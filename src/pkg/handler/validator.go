@@ -27,7 +27,7 @@ func NewDefaultValidator() *DefaultValidator {
 // ValidateCSVFilePath validates CSV file path
 func (v *DefaultValidator) ValidateCSVFilePath(path string) error {
 	if path == "" {
-		return status.Error(codes.InvalidArgument, "csv_file_path is required")
+		return invalidArgument("csv_file_path", "csv_file_path is required")
 	}
 	return nil
 }
@@ -35,11 +35,11 @@ func (v *DefaultValidator) ValidateCSVFilePath(path string) error {
 // ValidateAccountID validates account ID
 func (v *DefaultValidator) ValidateAccountID(accountID string) error {
 	if accountID == "" {
-		return status.Error(codes.InvalidArgument, "account_id is required")
+		return invalidArgument("account_id", "account_id is required")
 	}
 	// Additional validation rules can be added here
 	if len(accountID) < 3 {
-		return status.Error(codes.InvalidArgument, "account_id must be at least 3 characters")
+		return invalidArgument("account_id", "account_id must be at least 3 characters")
 	}
 	return nil
 }
@@ -47,10 +47,10 @@ func (v *DefaultValidator) ValidateAccountID(accountID string) error {
 // ValidateCSVData validates CSV data
 func (v *DefaultValidator) ValidateCSVData(data string) error {
 	if data == "" {
-		return status.Error(codes.InvalidArgument, "csv_data is required")
+		return invalidArgument("csv_data", "csv_data is required")
 	}
 	if len(data) < 10 {
-		return status.Error(codes.InvalidArgument, "csv_data is too short")
+		return invalidArgument("csv_data", "csv_data is too short")
 	}
 	return nil
 }
@@ -146,7 +146,10 @@ func ValidateValidateCSVDataRequest(req interface{}, v Validator) error {
 	return nil
 }
 
-// CreateDuplicateKey creates a unique key for duplicate detection
+// CreateDuplicateKey creates a unique key for duplicate detection.
+//
+// Deprecated: the service uses dedup.Policy so that validation and processing
+// agree; this helper is kept for existing callers.
 func CreateDuplicateKey(entryDate, entryTime, exitDate, exitTime string, amount int, cardNumber string) string {
 	return fmt.Sprintf("%s_%s_%s_%s_%d_%s",
 		entryDate, entryTime, exitDate, exitTime, amount, cardNumber)
@@ -0,0 +1,229 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Service names reported by the standard grpc.health.v1.Health service.
+// Liveness is also reported for the empty name, which health clients ask
+// about when no service is given.
+const (
+	LivenessService  = "liveness"
+	ReadinessService = "readiness"
+)
+
+const (
+	defaultInterval = 10 * time.Second
+	defaultTimeout  = 2 * time.Second
+)
+
+// Check probes one dependency. A nil error means it is usable.
+type Check func(ctx context.Context) error
+
+// Dependency is a named check that readiness depends on
+type Dependency struct {
+	Name  string
+	Check Check
+}
+
+// Options tunes how often dependencies are probed
+type Options struct {
+	Interval time.Duration // time between probes; default 10s
+	Timeout  time.Duration // time one check may take; default 2s
+	// Services are further service names that follow readiness, such as the
+	// full name of the gRPC service being served
+	Services []string
+}
+
+// Status is the result of the latest check of one dependency
+type Status struct {
+	Name      string
+	Healthy   bool
+	Error     string
+	Latency   time.Duration
+	CheckedAt time.Time
+}
+
+// Monitor probes dependencies and publishes liveness and readiness through
+// the standard gRPC health service. The process is live until Shutdown; it
+// is ready once every dependency passed its latest check.
+type Monitor struct {
+	deps   []Dependency
+	opts   Options
+	server *grpchealth.Server
+
+	mu       sync.RWMutex
+	statuses []Status
+	probed   bool
+	stopped  bool
+}
+
+// NewMonitor creates a monitor. Liveness is serving straight away; readiness
+// is not serving until the first Probe succeeds.
+func NewMonitor(deps []Dependency, opts Options) *Monitor {
+	if opts.Interval <= 0 {
+		opts.Interval = defaultInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+
+	m := &Monitor{
+		deps:   append([]Dependency(nil), deps...),
+		opts:   opts,
+		server: grpchealth.NewServer(),
+	}
+	m.server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	m.server.SetServingStatus(LivenessService, healthpb.HealthCheckResponse_SERVING)
+	m.setReadiness(healthpb.HealthCheckResponse_NOT_SERVING)
+	return m
+}
+
+// Register adds the grpc.health.v1.Health service to a gRPC server
+func (m *Monitor) Register(s grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(s, m.server)
+}
+
+// Run probes immediately, then at every interval until the context is
+// cancelled
+func (m *Monitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Probe checks every dependency concurrently, updates readiness and returns
+// the results in dependency order
+func (m *Monitor) Probe(ctx context.Context) []Status {
+	statuses := make([]Status, len(m.deps))
+	var wg sync.WaitGroup
+	for i, dep := range m.deps {
+		wg.Add(1)
+		go func(i int, dep Dependency) {
+			defer wg.Done()
+			statuses[i] = m.check(ctx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses = statuses
+	m.probed = true
+	if !m.stopped {
+		if ready(statuses) {
+			m.setReadiness(healthpb.HealthCheckResponse_SERVING)
+		} else {
+			m.setReadiness(healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	}
+	return append([]Status(nil), statuses...)
+}
+
+// Statuses returns the results of the latest probe, or nil before the first
+func (m *Monitor) Statuses() []Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]Status(nil), m.statuses...)
+}
+
+// Ready reports whether the latest probe passed and Shutdown was not called
+func (m *Monitor) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.probed && !m.stopped && ready(m.statuses)
+}
+
+// Shutdown marks every service as not serving, so load balancers stop
+// sending requests while in-flight ones finish. Later probes do not change it.
+func (m *Monitor) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	m.server.Shutdown()
+}
+
+// check runs one dependency check with the per-check timeout. A check that
+// panics or ignores the timeout is reported as failed.
+func (m *Monitor) check(ctx context.Context, dep Dependency) Status {
+	ctx, cancel := context.WithTimeout(ctx, m.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("check panicked: %v", r)
+			}
+		}()
+		done <- dep.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", m.opts.Timeout)
+	}
+
+	st := Status{Name: dep.Name, Healthy: err == nil, Latency: time.Since(start), CheckedAt: start}
+	if err != nil {
+		st.Error = err.Error()
+	}
+	return st
+}
+
+// setReadiness sets the readiness service and the services that follow it
+func (m *Monitor) setReadiness(status healthpb.HealthCheckResponse_ServingStatus) {
+	m.server.SetServingStatus(ReadinessService, status)
+	for _, name := range m.opts.Services {
+		m.server.SetServingStatus(name, status)
+	}
+}
+
+// ready reports whether every status is healthy
+func ready(statuses []Status) bool {
+	for _, st := range statuses {
+		if !st.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Writable returns a check that creates and removes a file in dir
+func Writable(dir string) Check {
+	return func(ctx context.Context) error {
+		f, err := os.CreateTemp(dir, ".health-*")
+		if err != nil {
+			return fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		name := f.Name()
+		_, werr := f.Write([]byte("ok"))
+		cerr := f.Close()
+		rerr := os.Remove(name)
+		for _, err := range []error{werr, cerr, rerr} {
+			if err != nil {
+				return fmt.Errorf("%s is not writable: %w", dir, err)
+			}
+		}
+		return nil
+	}
+}
\ No newline at end of file
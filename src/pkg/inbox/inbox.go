@@ -0,0 +1,458 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+)
+
+// Outcome is what an import produced for one file
+type Outcome struct {
+	ImportID string         `json:"import_id,omitempty"`
+	Success  bool           `json:"success"`
+	Message  string         `json:"message,omitempty"`
+	Stats    map[string]int `json:"stats,omitempty"`
+	Errors   []string       `json:"errors,omitempty"`
+}
+
+// Importer imports one stable file for an account. An error means the file
+// could not be imported at all; an unsuccessful Outcome means it was read but
+// nothing was saved. Either way the file is moved to the failed directory.
+type Importer func(ctx context.Context, path, accountID string) (Outcome, error)
+
+// Result is written as a JSON sidecar next to each archived or failed file
+type Result struct {
+	File       string    `json:"file"`
+	AccountID  string    `json:"account_id,omitempty"`
+	MovedTo    string    `json:"moved_to"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+	Outcome
+}
+
+// Dir configures one watched inbox directory
+type Dir struct {
+	Path string
+	// AccountPattern matches the file path relative to Path, using "/" as the
+	// separator. "*" matches within one path element and "{account}" captures
+	// the account ID, e.g. "{account}/*.csv" or "etc_{account}_*.csv".
+	AccountPattern string
+	// AccountID is used when the pattern has no {account} placeholder
+	AccountID  string
+	ArchiveDir string // default: <Path>/archive
+	FailedDir  string // default: <Path>/failed
+}
+
+// Options tunes how the watcher polls
+type Options struct {
+	PollInterval time.Duration // how often directories are scanned
+	StableFor    time.Duration // how long size and mtime must be unchanged
+	Extensions   []string      // file extensions to import; default .csv and .zip
+}
+
+// Watcher polls inbox directories and imports files once they are stable
+type Watcher struct {
+	dirs     []*watchedDir
+	importer Importer
+	opts     Options
+	seen     map[string]observation
+	now      func() time.Time
+
+	// Progress for Check: when a scan last started or finished a file, and
+	// whether a file is being imported now
+	lastActive atomic.Int64
+	importing  atomic.Bool
+}
+
+type watchedDir struct {
+	Dir
+	pattern *regexp.Regexp
+}
+
+type observation struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+	handled bool // imported but could not be moved away; never import it again
+}
+
+// New creates a watcher for the given directories
+func New(dirs []Dir, importer Importer, opts Options) (*Watcher, error) {
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("at least one inbox directory is required")
+	}
+	if importer == nil {
+		return nil, fmt.Errorf("importer is required")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.StableFor <= 0 {
+		opts.StableFor = 5 * time.Second
+	}
+	if len(opts.Extensions) == 0 {
+		opts.Extensions = []string{".csv", ".zip"}
+	}
+
+	w := &Watcher{
+		importer: importer,
+		opts:     opts,
+		seen:     make(map[string]observation),
+		now:      time.Now,
+	}
+
+	for _, d := range dirs {
+		if d.Path == "" {
+			return nil, fmt.Errorf("inbox path is required")
+		}
+		abs, err := filepath.Abs(d.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inbox path %s: %w", d.Path, err)
+		}
+		d.Path = abs
+		if d.ArchiveDir == "" {
+			d.ArchiveDir = filepath.Join(abs, "archive")
+		}
+		if d.FailedDir == "" {
+			d.FailedDir = filepath.Join(abs, "failed")
+		}
+		if d.AccountPattern == "" {
+			d.AccountPattern = "*"
+		}
+
+		pattern, err := compilePattern(d.AccountPattern)
+		if err != nil {
+			return nil, fmt.Errorf("inbox %s: %w", d.Path, err)
+		}
+		if pattern.SubexpIndex("account") < 0 && d.AccountID == "" {
+			return nil, fmt.Errorf("inbox %s: account_pattern has no {account} and no account_id is set", d.Path)
+		}
+
+		for _, dir := range []string{d.Path, d.ArchiveDir, d.FailedDir} {
+			if err := os.MkdirAll(dir, 0o700); err != nil {
+				return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+		}
+		w.dirs = append(w.dirs, &watchedDir{Dir: d, pattern: pattern})
+	}
+	return w, nil
+}
+
+// Paths returns the absolute inbox directories
+func (w *Watcher) Paths() []string {
+	var paths []string
+	for _, d := range w.dirs {
+		paths = append(paths, d.Path)
+	}
+	return paths
+}
+
+// Dirs returns the inbox directories with their defaults filled in
+func (w *Watcher) Dirs() []Dir {
+	var dirs []Dir
+	for _, d := range w.dirs {
+		dirs = append(dirs, d.Dir)
+	}
+	return dirs
+}
+
+// Check reports whether the watcher can keep importing: every inbox, archive
+// and failed directory must still exist, and once Run has started it must
+// have scanned within the last three poll intervals. A long import counts as
+// progress.
+func (w *Watcher) Check(ctx context.Context) error {
+	for _, d := range w.dirs {
+		for _, dir := range []string{d.Path, d.ArchiveDir, d.FailedDir} {
+			info, err := os.Stat(dir)
+			if err != nil {
+				return fmt.Errorf("inbox directory unavailable: %w", err)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("inbox directory %s is not a directory", dir)
+			}
+		}
+	}
+
+	last := w.lastActive.Load()
+	if last == 0 || w.importing.Load() {
+		return nil
+	}
+	if idle := w.now().Sub(time.Unix(0, last)); idle > 3*w.opts.PollInterval {
+		return fmt.Errorf("inbox has not been scanned for %s", idle.Truncate(time.Second))
+	}
+	return nil
+}
+
+// Run scans immediately, so files left over from before a restart are picked
+// up, then keeps polling until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.Scan(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan imports every stable file once and returns how many were handled
+func (w *Watcher) Scan(ctx context.Context) int {
+	w.lastActive.Store(w.now().UnixNano())
+	handled := 0
+	present := make(map[string]bool)
+
+	for _, d := range w.dirs {
+		for _, path := range w.candidates(d) {
+			present[path] = true
+			if ctx.Err() != nil {
+				return handled
+			}
+			if !w.stable(path) {
+				continue
+			}
+			delete(w.seen, path)
+			w.importing.Store(true)
+			w.handle(ctx, d, path)
+			w.importing.Store(false)
+			w.lastActive.Store(w.now().UnixNano())
+			handled++
+		}
+	}
+
+	// Forget files that disappeared before becoming stable
+	for path := range w.seen {
+		if !present[path] {
+			delete(w.seen, path)
+		}
+	}
+	return handled
+}
+
+// candidates lists importable files below an inbox, skipping the archive and
+// failed directories, hidden files and sidecars
+func (w *Watcher) candidates(d *watchedDir) []string {
+	var files []string
+	filepath.WalkDir(d.Path, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path != d.Path && (path == d.ArchiveDir || path == d.FailedDir || strings.HasPrefix(entry.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(entry.Name(), ".") || !entry.Type().IsRegular() || !w.hasExtension(path) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+// stable reports whether a file's size and mtime have not changed for StableFor.
+// Files last modified longer ago than StableFor are stable on first sight, so
+// a startup rescan imports them without waiting. A handled file left in the
+// inbox is never stable until it changes.
+func (w *Watcher) stable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(w.seen, path)
+		return false
+	}
+
+	now := w.now()
+	prev, ok := w.seen[path]
+	if !ok || prev.size != info.Size() || !prev.modTime.Equal(info.ModTime()) {
+		since := now
+		if now.Sub(info.ModTime()) >= w.opts.StableFor {
+			since = info.ModTime()
+		}
+		prev = observation{size: info.Size(), modTime: info.ModTime(), since: since}
+		w.seen[path] = prev
+	}
+	return !prev.handled && now.Sub(prev.since) >= w.opts.StableFor
+}
+
+// handle imports one file and moves it, with its result sidecar, to the
+// archive or failed directory
+func (w *Watcher) handle(ctx context.Context, d *watchedDir, path string) {
+	rel, _ := filepath.Rel(d.Path, path)
+	rel = filepath.ToSlash(rel)
+	result := Result{File: rel, StartedAt: w.now()}
+
+	// Each file gets its own request ID, so the import's log lines can be
+	// matched with the inbox line below
+	ctx = logging.WithRequestID(ctx, logging.NewRequestID())
+	logger := logging.FromContext(ctx)
+
+	accountID, ok := d.accountFor(rel)
+	if !ok {
+		result.Error = fmt.Sprintf("path does not match account pattern %q", d.AccountPattern)
+	} else {
+		result.AccountID = accountID
+		outcome, err := w.importer(ctx, path, accountID)
+		result.Outcome = outcome
+		if err != nil {
+			result.Error = err.Error()
+		}
+	}
+	result.FinishedAt = w.now()
+
+	destDir := d.ArchiveDir
+	if result.Error != "" || !result.Success {
+		destDir = d.FailedDir
+	}
+
+	dest, err := w.move(path, destDir, rel)
+	if err != nil {
+		logger.Error("inbox: failed to move file", "file", path, "error", err)
+		// Set the file aside in place so no later scan imports it again, or
+		// failing that remember it until it changes
+		dest = path + ".failed"
+		if err := os.Rename(path, dest); err != nil {
+			logger.Error("inbox: failed to set file aside", "file", path, "error", err)
+			w.markHandled(path)
+			return
+		}
+	}
+	result.MovedTo = dest
+
+	if err := writeSidecar(dest+".result.json", result); err != nil {
+		logger.Error("inbox: failed to write result", "file", dest, "error", err)
+	}
+	logger.Info("inbox: file handled", "file", rel, "account_id", accountID, "moved_to", dest, "success", result.Error == "" && result.Success)
+}
+
+// accountFor returns the account ID for a path relative to the inbox
+func (d *watchedDir) accountFor(rel string) (string, bool) {
+	m := d.pattern.FindStringSubmatch(rel)
+	if m == nil {
+		return "", false
+	}
+	if i := d.pattern.SubexpIndex("account"); i >= 0 && m[i] != "" {
+		return m[i], true
+	}
+	return d.AccountID, d.AccountID != ""
+}
+
+// markHandled remembers a file that was imported but is still in the inbox
+func (w *Watcher) markHandled(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	w.seen[path] = observation{size: info.Size(), modTime: info.ModTime(), since: w.now(), handled: true}
+}
+
+// move renames a file into destDir, keeping its relative path and adding a
+// timestamp when the destination already exists. Moved files hold card
+// numbers, so new directories are owner-only. A destination on another
+// filesystem gets a copy and the original is removed.
+func (w *Watcher) move(path, destDir, rel string) (string, error) {
+	dest := filepath.Join(destDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dest), 0o700); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(dest)
+		dest = fmt.Sprintf("%s.%s%s", strings.TrimSuffix(dest, ext), w.now().Format("20060102T150405.000000000"), ext)
+	}
+	err := os.Rename(path, dest)
+	if errors.Is(err, syscall.EXDEV) {
+		err = moveAcross(path, dest)
+	}
+	if err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// moveAcross copies a file to dest on another filesystem and removes it
+func moveAcross(path, dest string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, src)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// hasExtension reports whether a file has an importable extension
+func (w *Watcher) hasExtension(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, allowed := range w.opts.Extensions {
+		if ext == strings.ToLower(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// compilePattern turns an account pattern into an anchored regular expression
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	rest := pattern
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "{account}"):
+			sb.WriteString(`(?P<account>[^/]+?)`)
+			rest = rest[len("{account}"):]
+		case rest[0] == '*':
+			sb.WriteString(`[^/]*`)
+			rest = rest[1:]
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			sb.WriteString(regexp.QuoteMeta(rest[:size]))
+			rest = rest[size:]
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// writeSidecar writes a result as indented JSON
+func writeSidecar(path string, result Result) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
\ No newline at end of file
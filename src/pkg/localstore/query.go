@@ -0,0 +1,111 @@
+package localstore
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Query selects records; empty fields match every record
+type Query struct {
+	AccountID  string
+	CardNumber string
+	From       time.Time // first date, inclusive
+	To         time.Time // last date, exclusive
+	Limit      int       // 0 returns every match
+}
+
+// matches reports whether r is selected by q
+func (q Query) matches(r *Record) bool {
+	return (q.AccountID == "" || r.AccountID == q.AccountID) &&
+		(q.CardNumber == "" || r.CardNumber == q.CardNumber) &&
+		(q.From.IsZero() || !r.Date.Before(q.From)) &&
+		(q.To.IsZero() || r.Date.Before(q.To))
+}
+
+// Find returns the records selected by q, ordered by date and then by ID.
+// The card, account or date index narrows the records read, in that order
+// of preference.
+func (s *Store) Find(q Query) ([]Record, error) {
+	var out []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		ids := candidates(tx, q)
+		for _, id := range ids {
+			r, err := get(tx, id)
+			if err != nil {
+				return err
+			}
+			if q.matches(r) {
+				out = append(out, *r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.Before(out[j].Date)
+		}
+		return out[i].ID < out[j].ID
+	})
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+// candidates returns the IDs of the records that may match q
+func candidates(tx *bolt.Tx, q Query) [][]byte {
+	switch {
+	case q.CardNumber != "":
+		return scanPrefix(tx.Bucket(bucketByCard), indexKey(q.CardNumber))
+	case q.AccountID != "":
+		return scanPrefix(tx.Bucket(bucketByAccount), indexKey(q.AccountID))
+	case !q.From.IsZero() || !q.To.IsZero():
+		return scanDates(tx.Bucket(bucketByDate), q.From, q.To)
+	}
+	var ids [][]byte
+	tx.Bucket(bucketRecords).ForEach(func(k, _ []byte) error {
+		ids = append(ids, bytes.Clone(k))
+		return nil
+	})
+	return ids
+}
+
+// scanPrefix returns the IDs ending the index keys that start with prefix
+func scanPrefix(b *bolt.Bucket, prefix []byte) [][]byte {
+	var ids [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		ids = append(ids, bytes.Clone(k[len(prefix):]))
+	}
+	return ids
+}
+
+// scanDates returns the IDs in the date index from the day of from up to and
+// including the day of to
+func scanDates(b *bolt.Bucket, from, to time.Time) [][]byte {
+	var ids [][]byte
+	c := b.Cursor()
+	k, _ := c.First()
+	if !from.IsZero() {
+		k, _ = c.Seek([]byte(from.Format(dateLayout)))
+	}
+	last := ""
+	if !to.IsZero() {
+		last = to.Format(dateLayout)
+	}
+	for ; k != nil; k, _ = c.Next() {
+		day := string(k[:len(dateLayout)])
+		if last != "" && day > last {
+			break
+		}
+		ids = append(ids, bytes.Clone(k[len(dateLayout):]))
+	}
+	return ids
+}
\ No newline at end of file
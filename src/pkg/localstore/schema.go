@@ -0,0 +1,114 @@
+package localstore
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Buckets. Records are JSON keyed by their big-endian ID; the indexes map
+// account\x00ID, card\x00ID and YYYY-MM-DD+ID to nothing, and
+// account\x00matchKey to the ID.
+var (
+	bucketMeta      = []byte("meta")
+	bucketRecords   = []byte("records")
+	bucketByAccount = []byte("by_account")
+	bucketByCard    = []byte("by_card")
+	bucketByDate    = []byte("by_date")
+	bucketByMatch   = []byte("by_match")
+
+	keySchemaVersion = []byte("schema_version")
+)
+
+const dateLayout = "2006-01-02"
+
+// migration upgrades the schema from the previous version
+type migration struct {
+	name string
+	up   func(tx *bolt.Tx) error
+}
+
+// migrations are applied in order; the schema version is the number applied.
+// Append new ones, never change released ones.
+var migrations = []migration{
+	{"records with account, card, date and match-key indexes", func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketRecords, bucketByAccount, bucketByCard, bucketByDate, bucketByMatch} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+// SchemaVersion is the schema version of databases opened by this build
+var SchemaVersion = len(migrations)
+
+// migrate applies the migrations a database has not had yet
+func migrate(tx *bolt.Tx) error {
+	meta, err := tx.CreateBucketIfNotExists(bucketMeta)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if v := meta.Get(keySchemaVersion); v != nil {
+		version = int(btoi(v))
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than this build supports (%d)", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		if err := migrations[i].up(tx); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", i+1, migrations[i].name, err)
+		}
+	}
+	return meta.Put(keySchemaVersion, itob(uint64(len(migrations))))
+}
+
+// Version returns the schema version of the database
+func (s *Store) Version() (int, error) {
+	version := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(bucketMeta).Get(keySchemaVersion); v != nil {
+			version = int(btoi(v))
+		}
+		return nil
+	})
+	return version, err
+}
+
+// indexEntry is one key of an index bucket
+type indexEntry struct {
+	bucket []byte
+	key    []byte
+	value  []byte
+}
+
+// indexEntries returns the index keys of a record
+func indexEntries(r *Record) []indexEntry {
+	id := itob(r.ID)
+	entries := []indexEntry{
+		{bucketByAccount, withID(indexKey(r.AccountID), id), []byte{}},
+		{bucketByCard, withID(indexKey(r.CardNumber), id), []byte{}},
+		{bucketByDate, withID([]byte(r.Date.Format(dateLayout)), id), []byte{}},
+	}
+	if r.MatchKey != "" {
+		entries = append(entries, indexEntry{bucketByMatch, indexKey(r.AccountID, r.MatchKey), id})
+	}
+	return entries
+}
+
+// indexKey joins parts with NUL separators, followed by a NUL
+func indexKey(parts ...string) []byte {
+	var b bytes.Buffer
+	for _, p := range parts {
+		b.WriteString(p)
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+func withID(prefix, id []byte) []byte {
+	return append(prefix, id...)
+}
\ No newline at end of file
@@ -0,0 +1,229 @@
+package localstore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Record is a stored ETC trip
+type Record struct {
+	ID              uint64    `json:"id"`
+	AccountID       string    `json:"account_id"`
+	Date            time.Time `json:"date"` // exit date, or entry date if the exit date is invalid
+	EntryDate       string    `json:"entry_date"`
+	EntryTime       string    `json:"entry_time"`
+	ExitDate        string    `json:"exit_date"`
+	ExitTime        string    `json:"exit_time"`
+	EntryIC         string    `json:"entry_ic"`
+	ExitIC          string    `json:"exit_ic"`
+	Route           string    `json:"route"`
+	Amount          int       `json:"amount"` // amount charged, as a positive number
+	ETCAmount       int       `json:"etc_amount"`
+	NormalAmount    int       `json:"normal_amount"`
+	DiscountApplied int       `json:"discount_applied"`
+	Mileage         int       `json:"mileage"`
+	VehicleType     string    `json:"vehicle_type"`
+	VehicleClass    int       `json:"vehicle_class"`
+	VehicleNumber   string    `json:"vehicle_number"`
+	CardNumber      string    `json:"card_number"`
+	Notes           string    `json:"notes"`
+	MatchKey        string    `json:"match_key,omitempty"` // duplicate key of upserted records
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Store is a DB client keeping records in an embedded bbolt database file.
+// Records are indexed by account, card number, date and duplicate key. It
+// implements handler.DBClient, handler.Upserter and handler.Pinger, and is
+// safe for concurrent use; only one process can open a file at a time.
+type Store struct {
+	db  *bolt.DB
+	now func() time.Time
+}
+
+// Open opens the database at path, creating it and its directory if needed,
+// and migrates it to the current schema
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		return nil, err
+	}
+	if err := db.Update(migrate); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	return &Store{db: db, now: time.Now}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveETCData stores data, the map of a record built by the service, as a new
+// record
+func (s *Store) SaveETCData(data interface{}) error {
+	r, err := toRecord(data)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return s.insert(tx, r)
+	})
+}
+
+// UpsertETCData stores data under the account's matchKey, replacing the
+// record stored under it before
+func (s *Store) UpsertETCData(matchKey string, data interface{}) (bool, error) {
+	r, err := toRecord(data)
+	if err != nil {
+		return false, err
+	}
+	r.MatchKey = matchKey
+	replaced := false
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		id := tx.Bucket(bucketByMatch).Get(indexKey(r.AccountID, matchKey))
+		if id == nil {
+			return s.insert(tx, r)
+		}
+		old, err := get(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := unindex(tx, old); err != nil {
+			return err
+		}
+		r.ID, r.CreatedAt, r.UpdatedAt = old.ID, old.CreatedAt, s.now()
+		replaced = true
+		return put(tx, r)
+	})
+	return replaced, err
+}
+
+// Ping checks that the database can be read
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketRecords) == nil {
+			return errors.New("records bucket is missing")
+		}
+		return nil
+	})
+}
+
+// Get returns the record with id
+func (s *Store) Get(id uint64) (*Record, error) {
+	var r *Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		r, err = get(tx, itob(id))
+		return err
+	})
+	return r, err
+}
+
+// Len returns the number of stored records
+func (s *Store) Len() (int, error) {
+	n := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(bucketRecords).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// insert stores a new record and its index entries
+func (s *Store) insert(tx *bolt.Tx, r *Record) error {
+	id, err := tx.Bucket(bucketRecords).NextSequence()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	r.CreatedAt = s.now()
+	r.UpdatedAt = r.CreatedAt
+	return put(tx, r)
+}
+
+// put writes a record and its index entries
+func put(tx *bolt.Tx, r *Record) error {
+	value, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	id := itob(r.ID)
+	if err := tx.Bucket(bucketRecords).Put(id, value); err != nil {
+		return err
+	}
+	for _, e := range indexEntries(r) {
+		if err := tx.Bucket(e.bucket).Put(e.key, e.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindex removes the index entries of a record
+func unindex(tx *bolt.Tx, r *Record) error {
+	for _, e := range indexEntries(r) {
+		if err := tx.Bucket(e.bucket).Delete(e.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get reads the record with the big-endian id
+func get(tx *bolt.Tx, id []byte) (*Record, error) {
+	value := tx.Bucket(bucketRecords).Get(id)
+	if value == nil {
+		return nil, fmt.Errorf("record %d not found", btoi(id))
+	}
+	var r Record
+	if err := json.Unmarshal(value, &r); err != nil {
+		return nil, fmt.Errorf("record %d: %w", btoi(id), err)
+	}
+	return &r, nil
+}
+
+// toRecord converts the data passed to the DB client, a map or struct with
+// the JSON names of Record
+func toRecord(data interface{}) (*Record, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var r Record
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("unsupported record %T: %w", data, err)
+	}
+	if r.AccountID == "" {
+		return nil, errors.New("record has no account_id")
+	}
+	r.ID, r.MatchKey = 0, ""
+	return &r, nil
+}
+
+func itob(id uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, id)
+	return b
+}
+
+func btoi(b []byte) uint64 {
+	return binary.BigEndian.Uint64(b)
+}
\ No newline at end of file
@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/grpcstream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor assigns each RPC a request ID, taken from the
+// x-request-id metadata when the client sent a valid one, returns it in the
+// response header and logs the RPC's outcome. Handlers get the request's
+// logger from FromContext. A nil logger uses the default logger.
+func UnaryServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = requestContext(ctx, logger)
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, RequestID(ctx)))
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(ctx, info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func StreamServerInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := requestContext(ss.Context(), logger)
+		ss.SetHeader(metadata.Pairs(RequestIDKey, RequestID(ctx)))
+		start := time.Now()
+		err := handler(srv, grpcstream.WithContext(ss, ctx))
+		logRPC(ctx, info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+// requestContext adds the logger and the propagated or a new request ID
+func requestContext(ctx context.Context, logger *slog.Logger) context.Context {
+	if logger != nil {
+		ctx = NewContext(ctx, logger)
+	}
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDKey); len(values) > 0 && validRequestID(values[0]) {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = NewRequestID()
+	}
+	return WithRequestID(ctx, id)
+}
+
+// logRPC logs a finished RPC. Server-side failures are errors and client
+// errors are warnings; successful health checks are only logged at debug, so
+// probes do not flood the log.
+func logRPC(ctx context.Context, fullMethod string, elapsed time.Duration, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+		if strings.HasPrefix(fullMethod, "/grpc.health.v1.") || strings.HasSuffix(fullMethod, "/HealthCheck") {
+			level = slog.LevelDebug
+		}
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("grpc_method", fullMethod),
+		slog.String("grpc_code", code.String()),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	FromContext(ctx).LogAttrs(ctx, level, "rpc finished", attrs...)
+}
\ No newline at end of file
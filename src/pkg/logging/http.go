@@ -0,0 +1,65 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Middleware assigns each HTTP request a request ID, taken from the
+// X-Request-Id header when the client sent a valid one, and logs the
+// response. The ID is set on the request header, so a gateway forwarding the
+// header passes it on to gRPC, and on the response header. A nil logger uses
+// the default logger.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if logger != nil {
+			ctx = NewContext(ctx, logger)
+		}
+		id := r.Header.Get(RequestIDKey)
+		if !validRequestID(id) {
+			id = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, id)
+		r = r.WithContext(ctx)
+		r.Header.Set(RequestIDKey, id)
+		w.Header().Set(RequestIDKey, id)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= 500:
+			level = slog.LevelError
+		case rec.status >= 400:
+			level = slog.LevelWarn
+		}
+		FromContext(ctx).LogAttrs(ctx, level, "http request finished",
+			slog.String("http_method", r.Method),
+			slog.String("http_path", r.URL.Path),
+			slog.Int("http_status", rec.status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+	})
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush supports streamed responses
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
\ No newline at end of file
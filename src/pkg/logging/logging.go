@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Formats accepted by New
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+// RequestIDKey is the log attribute, gRPC metadata key and HTTP header
+// (case-insensitively) that carries the request ID
+const RequestIDKey = "x-request-id"
+
+// ParseLevel returns the slog level for a config log_level:
+// debug, info, warn or error. "" is info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level: %s", name)
+	}
+}
+
+// New creates a leveled logger writing JSON or logfmt lines to w. Card and
+// vehicle numbers are redacted from every line.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return NewLeveled(w, lvl, format)
+}
+
+// NewLeveled is New with a level that may change while the logger is in use,
+// such as a *slog.LevelVar
+func NewLeveled(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "", FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	case FormatLogfmt:
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+	return slog.New(NewRedactingHandler(h)), nil
+}
+
+type requestIDKey struct{}
+type loggerKey struct{}
+
+// WithRequestID returns a context carrying the request ID and a logger that
+// adds it to every line
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return context.WithValue(ctx, loggerKey{}, FromContext(ctx).With(slog.String("request_id", id)))
+}
+
+// NewContext returns a context whose FromContext is logger
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// RequestID returns the request ID of the context, or ""
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// FromContext returns the request's logger, or the default logger outside a
+// request
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// NewRequestID returns a random 128-bit hex ID
+func NewRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID reports whether a client-supplied ID may be propagated. IDs
+// are limited to 128 printable ASCII characters without spaces, so they cannot
+// forge log fields.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
\ No newline at end of file
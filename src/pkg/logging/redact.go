@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces sensitive values in log lines
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged
+var sensitiveKeys = map[string]bool{
+	"card_number":    true,
+	"card":           true,
+	"etc_card":       true,
+	"vehicle_number": true,
+	"vehicle_no":     true,
+	"car_number":     true,
+	"plate":          true,
+	"plate_number":   true,
+}
+
+var (
+	// cardPattern matches card numbers, masked or not, with optional
+	// separators: "1234567812345678", "********12345678", "1234-5678-1234-5678"
+	cardPattern = regexp.MustCompile(`(?:[0-9*]{4}[ -]?){3}[0-9*]{2,7}`)
+	// platePattern matches Japanese number plates such as "品川 300 あ 12-34"
+	platePattern = regexp.MustCompile(`[\p{Han}\p{Katakana}]{1,4}\s?[0-9]{2,3}\s?[\p{Hiragana}A-Z]\s?(?:[0-9]{1,2}-[0-9]{2}|[0-9]{4}|[・.]{1,3}[0-9]{1,3})`)
+)
+
+// Redact removes card numbers and number plates from free text
+func Redact(s string) string {
+	s = replaceStandalone(cardPattern, s, func(match string) bool {
+		return strings.ContainsAny(match, "0123456789")
+	})
+	return platePattern.ReplaceAllString(s, Redacted)
+}
+
+// replaceStandalone replaces matches that are not part of a longer word,
+// such as a hex ID, and that keep passes
+func replaceStandalone(re *regexp.Regexp, s string, keep func(string) bool) string {
+	matches := re.FindAllStringIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if (m[0] > 0 && isWordByte(s[m[0]-1])) || (m[1] < len(s) && isWordByte(s[m[1]])) || !keep(s[m[0]:m[1]]) {
+			continue
+		}
+		b.WriteString(s[last:m[0]])
+		b.WriteString(Redacted)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+// RedactingHandler removes card and vehicle numbers from messages and
+// attributes before passing records on
+type RedactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps a handler
+func NewRedactingHandler(next slog.Handler) *RedactingHandler {
+	return &RedactingHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles the level
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it on
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs redacts the attributes and passes them on
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup passes the group on
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr hides sensitive keys and redacts text values, recursing into groups
+func redactAttr(a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, Redacted)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Redact(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, g := range group {
+			redacted[i] = redactAttr(g)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, Redact(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, Redact(x.String()))
+		case []string:
+			redacted := make([]string, len(x))
+			for i, s := range x {
+				redacted[i] = Redact(s)
+			}
+			return slog.Any(a.Key, redacted)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor counts and times unary RPCs per method
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		done := m.startRPC(info.FullMethod)
+		resp, err := handler(ctx, req)
+		done(err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor counts and times streaming RPCs per method
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		done := m.startRPC(info.FullMethod)
+		err := handler(srv, ss)
+		done(err)
+		return err
+	}
+}
+
+// startRPC counts an RPC as started; the returned func records its outcome
+func (m *Metrics) startRPC(fullMethod string) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+	service, method := splitMethod(fullMethod)
+	m.rpcStarted.Inc(service, method)
+	start := time.Now()
+	return func(err error) {
+		m.rpcLatency.Observe(time.Since(start).Seconds(), service, method)
+		m.rpcHandled.Inc(service, method, status.Code(err).String())
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into service and method
+func splitMethod(fullMethod string) (string, string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "unknown", name
+}
\ No newline at end of file
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// Record outcomes counted by etc_records_total
+const (
+	OutcomeParsed   = "parsed"   // rows read from the file
+	OutcomeSaved    = "saved"    // rows saved, including updates
+	OutcomeUpdated  = "updated"  // saved rows that replaced a re-issued row
+	OutcomeSkipped  = "skipped"  // duplicates skipped at the caller's request
+	OutcomeRejected = "rejected" // rows failing rules, conversion or saving
+)
+
+// Record formats counted by etc_records_total
+const (
+	FormatCSV = "csv" // a CSV file, upload or inline csv_data/csv_bytes
+	FormatZIP = "zip" // a CSV entry of a ZIP archive
+)
+
+// Metrics is the set of metrics exported by the server. A nil *Metrics
+// records nothing, so services built without metrics need no checks.
+type Metrics struct {
+	registry *Registry
+
+	records       *CounterVec
+	parseFailures *CounterVec
+	saves         *HistogramVec
+	inFlight      *Gauge
+
+	rpcStarted *CounterVec
+	rpcHandled *CounterVec
+	rpcLatency *HistogramVec
+}
+
+// New registers the server metrics in a new registry
+func New() *Metrics {
+	r := NewRegistry()
+	start := float64(time.Now().Unix())
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", func() float64 { return start })
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 { return float64(runtime.NumGoroutine()) })
+
+	return &Metrics{
+		registry:      r,
+		records:       r.NewCounterVec("etc_records_total", "ETC rows processed, by outcome and source format.", "outcome", "format"),
+		parseFailures: r.NewCounterVec("etc_parse_failures_total", "Files or archive entries that could not be parsed, by source format.", "format"),
+		saves:         r.NewHistogramVec("etc_db_save_duration_seconds", "Latency of db_service save calls, one observation per attempt.", nil, "operation", "result"),
+		inFlight:      r.NewGauge("etc_imports_in_flight", "Imports currently running."),
+		rpcStarted:    r.NewCounterVec("grpc_server_started_total", "RPCs started on the server.", "grpc_service", "grpc_method"),
+		rpcHandled:    r.NewCounterVec("grpc_server_handled_total", "RPCs completed on the server, by status code.", "grpc_service", "grpc_method", "grpc_code"),
+		rpcLatency:    r.NewHistogramVec("grpc_server_handling_seconds", "Latency of RPCs handled by the server.", nil, "grpc_service", "grpc_method"),
+	}
+}
+
+// Registry returns the registry holding the metrics
+func (m *Metrics) Registry() *Registry {
+	return m.registry
+}
+
+// AddRecords counts n rows with the given outcome and format
+func (m *Metrics) AddRecords(outcome, format string, n int) {
+	if m == nil || n < 0 {
+		return
+	}
+	m.records.Add(float64(n), outcome, format)
+}
+
+// ParseFailed counts a file or archive entry that could not be parsed
+func (m *Metrics) ParseFailed(format string) {
+	if m == nil {
+		return
+	}
+	m.parseFailures.Inc(format)
+}
+
+// ObserveSave records the latency of one save attempt. operation is "save"
+// or "upsert".
+func (m *Metrics) ObserveSave(operation string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.saves.Observe(d.Seconds(), operation, result)
+}
+
+// ImportStarted counts an import as in flight until the returned func is called
+func (m *Metrics) ImportStarted() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.inFlight.Add(1)
+	return func() { m.inFlight.Add(-1) }
+}
\ No newline at end of file
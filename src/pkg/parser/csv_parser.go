@@ -7,6 +7,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
 )
 
 // ETCRecord represents a single ETC toll record
@@ -114,35 +116,35 @@ func (p *CSVParser) ParseFile(filepath string) ([]ETCRecord, error) {
 func (p *CSVParser) ValidateRecord(record ETCRecord) error {
 	// Check for empty required fields
 	if record.EntryIC == "" {
-		return fmt.Errorf("entry IC cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "entry_ic", "entry IC cannot be empty")
 	}
 	if record.ExitIC == "" {
-		return fmt.Errorf("exit IC cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "exit_ic", "exit IC cannot be empty")
 	}
 	if record.Route == "" {
-		return fmt.Errorf("route cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "route", "route cannot be empty")
 	}
 	if record.VehicleType == "" {
-		return fmt.Errorf("vehicle type cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "vehicle_type", "vehicle type cannot be empty")
 	}
 	if record.CardNumber == "" {
-		return fmt.Errorf("card number cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "card_number", "card number cannot be empty")
 	}
 
 	// Check amount is non-negative
 	if record.Amount < 0 {
-		return fmt.Errorf("amount cannot be negative")
+		return recorderr.New(recorderr.CodeInvalidAmount, "amount", "amount cannot be negative")
 	}
 
 	// Check date is not in the future
 	if record.Date.After(time.Now()) {
-		return fmt.Errorf("date cannot be in the future")
+		return recorderr.New(recorderr.CodeInvalidDate, "date", "date cannot be in the future")
 	}
 
 	// Check date is reasonable (not too old)
 	minDate := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	if record.Date.Before(minDate) {
-		return fmt.Errorf("date is too old (before year 2000)")
+		return recorderr.New(recorderr.CodeInvalidDate, "date", "date is too old (before year 2000)")
 	}
 
 	return nil
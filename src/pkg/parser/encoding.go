@@ -0,0 +1,148 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding is the character encoding of CSV input. Values match the Encoding
+// enum in the proto API.
+type Encoding int
+
+const (
+	EncodingAuto Encoding = iota
+	EncodingShiftJIS
+	EncodingUTF8
+	EncodingUTF16
+)
+
+// String returns the encoding name
+func (e Encoding) String() string {
+	switch e {
+	case EncodingAuto:
+		return "auto"
+	case EncodingShiftJIS:
+		return "shift_jis"
+	case EncodingUTF8:
+		return "utf8"
+	case EncodingUTF16:
+		return "utf16"
+	default:
+		return fmt.Sprintf("encoding(%d)", int(e))
+	}
+}
+
+// ParseEncoding returns the encoding named by String; "sjis", "cp932" and
+// "utf-8"/"utf-16" are accepted too. "" is EncodingAuto.
+func ParseEncoding(name string) (Encoding, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "shift_jis", "sjis", "cp932":
+		return EncodingShiftJIS, nil
+	case "utf8", "utf-8":
+		return EncodingUTF8, nil
+	case "utf16", "utf-16":
+		return EncodingUTF16, nil
+	default:
+		return 0, fmt.Errorf("unknown encoding: %s", name)
+	}
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// DetectEncoding guesses the encoding of CSV input: a byte order mark wins,
+// then the NUL bytes that UTF-16 puts in ASCII text, then UTF-8 validity.
+// Anything else is taken to be Shift-JIS, the encoding of ETC statements.
+func DetectEncoding(data []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, bomUTF16LE), bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16
+	}
+	if even, odd := nulCounts(data); even+odd > 0 && (even+odd)*4 >= len(data) {
+		return EncodingUTF16
+	}
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return EncodingShiftJIS
+}
+
+// Decode converts CSV input to UTF-8, removing any byte order mark.
+// EncodingAuto uses DetectEncoding.
+func Decode(data []byte, enc Encoding) (string, error) {
+	if enc == EncodingAuto {
+		enc = DetectEncoding(data)
+	}
+
+	var decoder *encoding.Decoder
+	switch enc {
+	case EncodingUTF8:
+		data = bytes.TrimPrefix(data, bomUTF8)
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("input is not valid UTF-8")
+		}
+		return string(data), nil
+	case EncodingShiftJIS:
+		decoder = japanese.ShiftJIS.NewDecoder()
+	case EncodingUTF16:
+		if len(data)%2 != 0 {
+			return "", fmt.Errorf("input is not valid UTF-16: odd number of bytes")
+		}
+		bom := unicode.IgnoreBOM
+		if bytes.HasPrefix(data, bomUTF16LE) || bytes.HasPrefix(data, bomUTF16BE) {
+			bom = unicode.ExpectBOM // strips the BOM
+		}
+		decoder = unicode.UTF16(utf16Order(data), bom).NewDecoder()
+	default:
+		return "", fmt.Errorf("unsupported encoding: %s", enc)
+	}
+
+	decoded, err := decoder.Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s input: %w", enc, err)
+	}
+	return string(decoded), nil
+}
+
+// utf16Order returns the byte order from the BOM or, without one, from where
+// the NUL bytes of ASCII characters are
+func utf16Order(data []byte) unicode.Endianness {
+	if bytes.HasPrefix(data, bomUTF16BE) {
+		return unicode.BigEndian
+	}
+	if bytes.HasPrefix(data, bomUTF16LE) {
+		return unicode.LittleEndian
+	}
+	if even, odd := nulCounts(data); even > odd {
+		return unicode.BigEndian
+	}
+	return unicode.LittleEndian
+}
+
+// nulCounts counts NUL bytes at even and odd offsets
+func nulCounts(data []byte) (even, odd int) {
+	for i, b := range data {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	return even, odd
+}
\ No newline at end of file
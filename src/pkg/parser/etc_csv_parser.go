@@ -9,8 +9,7 @@ import (
 	"strings"
 	"time"
 
-	"golang.org/x/text/encoding/japanese"
-	"golang.org/x/text/transform"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
 )
 
 // ActualETCRecord represents the actual ETC record format from the CSV files
@@ -30,8 +29,17 @@ type ActualETCRecord struct {
 	VehicleNumber string // 車両番号
 	CardNumber    string // ETCカード番号
 	Notes         string // 備考
+	LineNumber    int    // 1-based line number in the source CSV
+	RawRow        string // original CSV row as read from the source
+	Format        string // source layout: FormatHeader or FormatPositional
 }
 
+// Source layouts of an ETC CSV
+const (
+	FormatHeader     = "header"     // columns mapped by header names
+	FormatPositional = "positional" // no header row, columns mapped by position
+)
+
 // ETCCSVParser handles actual ETC CSV file parsing
 type ETCCSVParser struct{}
 
@@ -40,18 +48,20 @@ func NewETCCSVParser() *ETCCSVParser {
 	return &ETCCSVParser{}
 }
 
-// ParseFile parses an actual ETC CSV file with Shift-JIS encoding
+// ParseFile parses an actual ETC CSV file. The encoding is detected with
+// DetectEncoding; statements from issuer portals are Shift-JIS.
 func (p *ETCCSVParser) ParseFile(filepath string) ([]ActualETCRecord, error) {
-	file, err := os.Open(filepath)
+	data, err := os.ReadFile(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer file.Close()
 
-	// Convert from Shift-JIS to UTF-8
-	reader := transform.NewReader(file, japanese.ShiftJIS.NewDecoder())
-
-	return p.Parse(reader)
+	// Convert to UTF-8
+	text, err := Decode(data, EncodingAuto)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(strings.NewReader(text))
 }
 
 // Parse parses CSV data from a reader
@@ -81,19 +91,7 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 	// Check if first row is header
 	if len(records) > 0 {
 		firstRow := records[0]
-		isHeader := false
-
-		// Check for known header patterns
-		for _, col := range firstRow {
-			if strings.Contains(col, "利用年月日") || strings.Contains(col, "時刻") ||
-			   strings.Contains(col, "利用IC") || strings.Contains(col, "料金") ||
-			   strings.Contains(col, "カード番号") {
-				isHeader = true
-				break
-			}
-		}
-
-		if isHeader {
+		if IsHeaderRow(firstRow) {
 			// Build header mapping
 			for idx, col := range firstRow {
 				headerMap[col] = idx
@@ -116,6 +114,7 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 		if len(headerMap) > 0 {
 			// Use header-based mapping
 			etcRecord = p.parseWithHeaders(record, headerMap)
+			etcRecord.Format = FormatHeader
 		} else {
 			// Use positional mapping (backward compatibility)
 			// Ensure we have minimum required fields
@@ -133,6 +132,7 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 				ExitIC:        record[5],
 				RouteInfo:     p.getFieldSafe(record, 6),
 				Notes:         "",
+				Format:        FormatPositional,
 			}
 
 			// Parse ETC amount (field 7)
@@ -189,6 +189,9 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 			etcRecord.Notes = p.getFieldSafe(record, 14)
 		}
 
+		etcRecord.LineNumber = i + 1
+		etcRecord.RawRow = p.formatRawRow(record)
+
 		// Validate the record
 		if err := p.ValidateRecord(etcRecord); err != nil {
 			// Skip validation errors silently - continue processing
@@ -201,6 +204,18 @@ func (p *ETCCSVParser) Parse(reader io.Reader) ([]ActualETCRecord, error) {
 	return etcRecords, nil
 }
 
+// IsHeaderRow reports whether a row has known header patterns
+func IsHeaderRow(row []string) bool {
+	for _, col := range row {
+		if strings.Contains(col, "利用年月日") || strings.Contains(col, "時刻") ||
+			strings.Contains(col, "利用IC") || strings.Contains(col, "料金") ||
+			strings.Contains(col, "カード番号") {
+			return true
+		}
+	}
+	return false
+}
+
 // parseAmount parses amount strings that may have negative values
 func (p *ETCCSVParser) parseAmount(s string) (int, error) {
 	// Remove commas
@@ -230,21 +245,21 @@ func (p *ETCCSVParser) ValidateRecord(record ActualETCRecord) error {
 
 	// Check card number is not empty
 	if record.CardNumber == "" {
-		return fmt.Errorf("card number cannot be empty")
+		return recorderr.New(recorderr.CodeMissingField, "card_number", "card number cannot be empty")
 	}
 
 	// Parse and validate dates
 	if record.EntryDate != "" {
 		_, err := p.parseDate(record.EntryDate)
 		if err != nil {
-			return fmt.Errorf("invalid entry date: %w", err)
+			return recorderr.Wrap(recorderr.CodeInvalidDate, "entry_date", fmt.Errorf("invalid entry date: %w", err))
 		}
 	}
 
 	if record.ExitDate != "" {
 		_, err := p.parseDate(record.ExitDate)
 		if err != nil {
-			return fmt.Errorf("invalid exit date: %w", err)
+			return recorderr.Wrap(recorderr.CodeInvalidDate, "exit_date", fmt.Errorf("invalid exit date: %w", err))
 		}
 	}
 
@@ -292,7 +307,7 @@ func (p *ETCCSVParser) ConvertToSimpleRecord(actual ActualETCRecord) (ETCRecord,
 		// Try entry date if exit date fails
 		date, err = p.parseDate(actual.EntryDate)
 		if err != nil {
-			return ETCRecord{}, err
+			return ETCRecord{}, recorderr.Wrap(recorderr.CodeInvalidDate, "exit_date", err)
 		}
 	}
 
@@ -317,6 +332,15 @@ func (p *ETCCSVParser) ConvertToSimpleRecord(actual ActualETCRecord) (ETCRecord,
 	}, nil
 }
 
+// formatRawRow re-encodes a parsed row as a single CSV line
+func (p *ETCCSVParser) formatRawRow(record []string) string {
+	var sb strings.Builder
+	w := csv.NewWriter(&sb)
+	w.Write(record)
+	w.Flush()
+	return strings.TrimRight(sb.String(), "\r\n")
+}
+
 // getFieldSafe safely gets a field from a record slice
 func (p *ETCCSVParser) getFieldSafe(record []string, index int) string {
 	if index < len(record) {
@@ -331,17 +355,17 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 
 	// Map header names to fields - handle different formats
 	// Some files use （自）/（至） while others use （入）/（出）
-	etcRecord.EntryDate = p.getFieldByHeader(record, headerMap, "利用年月日（入）", "利用年月日(入)", "利用年月日（自）", "入口日付")
-	etcRecord.EntryTime = p.getFieldByHeader(record, headerMap, "時刻（入）", "時刻(入)", "時分（自）", "入口時刻")
-	etcRecord.ExitDate = p.getFieldByHeader(record, headerMap, "利用年月日（出）", "利用年月日(出)", "利用年月日（至）", "出口日付")
-	etcRecord.ExitTime = p.getFieldByHeader(record, headerMap, "時刻（出）", "時刻(出)", "時分（至）", "出口時刻")
-	etcRecord.EntryIC = p.getFieldByHeader(record, headerMap, "利用IC（入）", "利用IC(入)", "利用ＩＣ（自）", "入口IC", "入口")
-	etcRecord.ExitIC = p.getFieldByHeader(record, headerMap, "利用IC（出）", "利用IC(出)", "利用ＩＣ（至）", "出口IC", "出口")
-	etcRecord.RouteInfo = p.getFieldByHeader(record, headerMap, "経路情報", "路線", "経路")
+	etcRecord.EntryDate = p.getFieldByHeader(record, headerMap, headerNames["entry_date"]...)
+	etcRecord.EntryTime = p.getFieldByHeader(record, headerMap, headerNames["entry_time"]...)
+	etcRecord.ExitDate = p.getFieldByHeader(record, headerMap, headerNames["exit_date"]...)
+	etcRecord.ExitTime = p.getFieldByHeader(record, headerMap, headerNames["exit_time"]...)
+	etcRecord.EntryIC = p.getFieldByHeader(record, headerMap, headerNames["entry_ic"]...)
+	etcRecord.ExitIC = p.getFieldByHeader(record, headerMap, headerNames["exit_ic"]...)
+	etcRecord.RouteInfo = p.getFieldByHeader(record, headerMap, headerNames["route_info"]...)
 
 	// Parse amounts - handle different header formats
 	// 割引前料金 = Normal amount (before discount)
-	normalAmountStr := p.getFieldByHeader(record, headerMap, "割引前料金", "通行料金", "通常料金")
+	normalAmountStr := p.getFieldByHeader(record, headerMap, headerNames["normal_amount"]...)
 	if normalAmountStr != "" {
 		amount, err := p.parseAmount(normalAmountStr)
 		if err == nil {
@@ -350,7 +374,7 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 	}
 
 	// ＥＴＣ割引額 = Discount amount (negative value)
-	discountStr := p.getFieldByHeader(record, headerMap, "ＥＴＣ割引額", "ETC割引額", "割引額")
+	discountStr := p.getFieldByHeader(record, headerMap, headerNames["discount_applied"]...)
 	if discountStr != "" {
 		amount, err := p.parseAmount(discountStr)
 		if err == nil {
@@ -359,7 +383,7 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 	}
 
 	// 通行料金 = Actual charged amount
-	etcAmountStr := p.getFieldByHeader(record, headerMap, "通行料金", "ETC料金", "料金")
+	etcAmountStr := p.getFieldByHeader(record, headerMap, headerNames["etc_amount"]...)
 	if etcAmountStr != "" {
 		amount, err := p.parseAmount(etcAmountStr)
 		if err == nil {
@@ -368,7 +392,7 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 	}
 
 	// 後納料金 = Post-payment amount (if exists)
-	postPaymentStr := p.getFieldByHeader(record, headerMap, "後納料金", "後払料金")
+	postPaymentStr := p.getFieldByHeader(record, headerMap, headerNames["post_payment"]...)
 	if postPaymentStr != "" {
 		amount, err := p.parseAmount(postPaymentStr)
 		if err == nil && amount != 0 {
@@ -378,7 +402,7 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 	}
 
 	// Parse vehicle info
-	vehicleClassStr := p.getFieldByHeader(record, headerMap, "車種", "車両区分", "車種区分")
+	vehicleClassStr := p.getFieldByHeader(record, headerMap, headerNames["vehicle_class"]...)
 	if vehicleClassStr != "" {
 		class, err := strconv.Atoi(vehicleClassStr)
 		if err == nil {
@@ -386,9 +410,9 @@ func (p *ETCCSVParser) parseWithHeaders(record []string, headerMap map[string]in
 		}
 	}
 
-	etcRecord.VehicleNumber = p.getFieldByHeader(record, headerMap, "車両番号", "ナンバー", "車番")
-	etcRecord.CardNumber = p.getFieldByHeader(record, headerMap, "ＥＴＣカード番号", "ETCカード番号", "カード番号", "カード")
-	etcRecord.Notes = p.getFieldByHeader(record, headerMap, "備考", "メモ", "注記")
+	etcRecord.VehicleNumber = p.getFieldByHeader(record, headerMap, headerNames["vehicle_number"]...)
+	etcRecord.CardNumber = p.getFieldByHeader(record, headerMap, headerNames["card_number"]...)
+	etcRecord.Notes = p.getFieldByHeader(record, headerMap, headerNames["notes"]...)
 
 	return etcRecord
 }
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Fields are the names of the record fields, as used by validation rules, in
+// the column order of FormatPositional
+var Fields = []string{
+	"entry_date", "entry_time", "exit_date", "exit_time", "entry_ic", "exit_ic",
+	"route_info", "etc_amount", "normal_amount", "discount_applied", "mileage",
+	"vehicle_class", "vehicle_number", "card_number", "notes",
+}
+
+// FieldValues returns the record's values in the order of Fields: strings,
+// and ints for amounts, mileage and vehicle class
+func (r ActualETCRecord) FieldValues() []interface{} {
+	return []interface{}{
+		r.EntryDate, r.EntryTime, r.ExitDate, r.ExitTime, r.EntryIC, r.ExitIC,
+		r.RouteInfo, r.ETCAmount, r.NormalAmount, r.DiscountApplied, r.Mileage,
+		r.VehicleClass, r.VehicleNumber, r.CardNumber, r.Notes,
+	}
+}
+
+// headerNames are the header names each field is read from in FormatHeader,
+// in order of preference. Some files use （自）/（至） while others use
+// （入）/（出）. A non-zero post_payment amount replaces etc_amount.
+var headerNames = map[string][]string{
+	"entry_date":       {"利用年月日（入）", "利用年月日(入)", "利用年月日（自）", "入口日付"},
+	"entry_time":       {"時刻（入）", "時刻(入)", "時分（自）", "入口時刻"},
+	"exit_date":        {"利用年月日（出）", "利用年月日(出)", "利用年月日（至）", "出口日付"},
+	"exit_time":        {"時刻（出）", "時刻(出)", "時分（至）", "出口時刻"},
+	"entry_ic":         {"利用IC（入）", "利用IC(入)", "利用ＩＣ（自）", "入口IC", "入口"},
+	"exit_ic":          {"利用IC（出）", "利用IC(出)", "利用ＩＣ（至）", "出口IC", "出口"},
+	"route_info":       {"経路情報", "路線", "経路"},
+	"normal_amount":    {"割引前料金", "通行料金", "通常料金"},
+	"discount_applied": {"ＥＴＣ割引額", "ETC割引額", "割引額"},
+	"etc_amount":       {"通行料金", "ETC料金", "料金"},
+	"post_payment":     {"後納料金", "後払料金"},
+	"vehicle_class":    {"車種", "車両区分", "車種区分"},
+	"vehicle_number":   {"車両番号", "ナンバー", "車番"},
+	"card_number":      {"ＥＴＣカード番号", "ETCカード番号", "カード番号", "カード"},
+	"notes":            {"備考", "メモ", "注記"},
+}
+
+// Column is the CSV column a field is read from. Header is the matched header
+// name in FormatHeader.
+type Column struct {
+	Field  string
+	Index  int
+	Header string
+}
+
+// Inspection describes how Parse reads an ETC CSV
+type Inspection struct {
+	Encoding    Encoding // detected, or as given
+	Format      string   // FormatHeader or FormatPositional
+	Header      []string // header row, in FormatHeader
+	Columns     []Column // fields found, in the order of Fields
+	Missing     []string // fields with no column
+	Unmapped    []string // header columns no field is read from
+	DataRows    int      // rows after the header
+	Records     int      // rows Parse returns; short positional rows are skipped
+	SkippedRows int
+}
+
+// Inspect decodes data and reports its encoding, layout and column mapping
+// without converting any record. EncodingAuto uses DetectEncoding.
+func (p *ETCCSVParser) Inspect(data []byte, enc Encoding) (*Inspection, error) {
+	if enc == EncodingAuto {
+		enc = DetectEncoding(data)
+	}
+	text, err := Decode(data, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.LazyQuotes = true
+	csvReader.FieldsPerRecord = -1
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV file is empty")
+	}
+
+	in := &Inspection{Encoding: enc, Format: FormatPositional, DataRows: len(rows)}
+	if IsHeaderRow(rows[0]) {
+		in.Format = FormatHeader
+		in.Header = rows[0]
+		in.DataRows--
+		in.mapHeader()
+	} else {
+		width := 0
+		for _, row := range rows {
+			width = max(width, len(row))
+		}
+		for i, field := range Fields {
+			if i < width {
+				in.Columns = append(in.Columns, Column{Field: field, Index: i})
+			} else {
+				in.Missing = append(in.Missing, field)
+			}
+		}
+	}
+
+	records, err := p.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+	in.Records = len(records)
+	in.SkippedRows = in.DataRows - in.Records
+	return in, nil
+}
+
+// mapHeader finds the column of each field the way parseWithHeaders does
+func (in *Inspection) mapHeader() {
+	index := make(map[string]int, len(in.Header))
+	for i, col := range in.Header {
+		index[col] = i
+	}
+	used := map[int]bool{}
+	for _, field := range append(append([]string(nil), Fields...), "post_payment") {
+		found := false
+		for _, name := range headerNames[field] {
+			if i, ok := index[name]; ok {
+				in.Columns = append(in.Columns, Column{Field: field, Index: i, Header: name})
+				used[i] = true
+				found = true
+				break
+			}
+		}
+		if !found && field != "post_payment" {
+			in.Missing = append(in.Missing, field)
+		}
+	}
+	for i, col := range in.Header {
+		if !used[i] {
+			in.Unmapped = append(in.Unmapped, col)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,412 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RetryAfterHeader is the response metadata key, and HTTP header, telling a
+// throttled caller how many seconds to wait
+const RetryAfterHeader = "retry-after"
+
+// MaxAccounts is the number of accounts whose use a limiter tracks at once.
+// Idle accounts are dropped to make room; while none is idle, requests for
+// further accounts are refused.
+const MaxAccounts = 10000
+
+// Limits are the limits of one account, or of all accounts together. Zero
+// values are unlimited.
+type Limits struct {
+	RequestsPerSecond float64
+	Burst             int // requests that may arrive at once; 0 rounds RequestsPerSecond up
+	ConcurrentImports int
+	RecordsPerDay     int64
+}
+
+// Usage is the current use of one account's limits, or of the global limits
+// when AccountID is ""
+type Usage struct {
+	AccountID         string
+	Limits            Limits
+	ConcurrentImports int
+	RecordsToday      int64
+	Day               string
+	ThrottledRequests int64
+	RejectedImports   int64
+}
+
+// Limiter enforces request rates, concurrent imports and daily record quotas,
+// per account and globally. A nil *Limiter allows everything.
+type Limiter struct {
+	account  Limits
+	accounts map[string]Limits
+	now      func() time.Time
+
+	mu          sync.Mutex
+	globalState *state
+	states      map[string]*state
+}
+
+// state is the use of one set of limits
+type state struct {
+	limits    Limits
+	tokens    float64
+	refilled  time.Time
+	imports   int
+	day       string
+	records   int64
+	throttled int64
+	rejected  int64
+}
+
+// New creates a limiter with global limits, the default limits of each
+// account, and overrides for some accounts
+func New(global, account Limits, accounts map[string]Limits) *Limiter {
+	return NewWithClock(global, account, accounts, time.Now)
+}
+
+// NewWithClock is New with a clock for tests. Days start at midnight in the
+// clock's location.
+func NewWithClock(global, account Limits, accounts map[string]Limits, now func() time.Time) *Limiter {
+	l := &Limiter{
+		account:  account,
+		accounts: accounts,
+		now:      now,
+		states:   map[string]*state{},
+	}
+	l.globalState = newState(global, now())
+	return l
+}
+
+func newState(limits Limits, now time.Time) *state {
+	return &state{limits: limits, tokens: float64(burst(limits)), refilled: now}
+}
+
+// burst is the bucket size of the limits
+func burst(limits Limits) int {
+	if limits.Burst > 0 {
+		return limits.Burst
+	}
+	return int(math.Ceil(limits.RequestsPerSecond))
+}
+
+// SetLimits replaces the limits. Usage so far, running imports and today's
+// records, counts against the new limits.
+func (l *Limiter) SetLimits(global, account Limits, accounts map[string]Limits) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.account = account
+	l.accounts = accounts
+	l.globalState.setLimits(global)
+	for id, st := range l.states {
+		limits, override := accounts[id]
+		if !override {
+			limits = account
+		}
+		st.setLimits(limits)
+	}
+}
+
+// setLimits changes the limits, keeping no more tokens than the new burst
+func (st *state) setLimits(limits Limits) {
+	st.limits = limits
+	st.tokens = math.Min(st.tokens, float64(burst(limits)))
+}
+
+// stateFor returns the account's state, tracking the account when it is new;
+// l.mu must be held
+func (l *Limiter) stateFor(ctx context.Context, accountID string, now time.Time) (*state, error) {
+	if st, ok := l.states[accountID]; ok {
+		return st, nil
+	}
+	if len(l.states) >= MaxAccounts {
+		l.dropIdle(now)
+	}
+	if len(l.states) >= MaxAccounts {
+		return nil, exhausted(ctx, time.Second, "limits of %d accounts already in use", MaxAccounts)
+	}
+	st := l.newAccountState(accountID, now)
+	l.states[accountID] = st
+	return st, nil
+}
+
+func (l *Limiter) newAccountState(accountID string, now time.Time) *state {
+	limits, override := l.accounts[accountID]
+	if !override {
+		limits = l.account
+	}
+	return newState(limits, now)
+}
+
+// dropIdle forgets the accounts whose state is no different from a new one;
+// l.mu must be held
+func (l *Limiter) dropIdle(now time.Time) {
+	for id, st := range l.states {
+		if st.idle(now) {
+			delete(l.states, id)
+		}
+	}
+}
+
+// idle reports whether the state has no running imports, no records today and
+// a full bucket. Dropping it loses only its throttled and rejected counts.
+func (st *state) idle(now time.Time) bool {
+	st.refill(now)
+	st.rollDay(now)
+	full := st.limits.RequestsPerSecond <= 0 || st.tokens >= float64(burst(st.limits))
+	return full && st.imports == 0 && st.records == 0
+}
+
+// Accounts returns the number of accounts being tracked
+func (l *Limiter) Accounts() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.states)
+}
+
+// Allow takes one request from the global and the account's rate. A request
+// over either rate is refused with ResourceExhausted and takes nothing.
+func (l *Limiter) Allow(ctx context.Context, accountID string) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	account, err := l.stateFor(ctx, accountID, now)
+	if err != nil {
+		return err
+	}
+	for _, st := range []*state{l.globalState, account} {
+		if wait := st.refill(now); wait > 0 {
+			st.throttled++
+			return exhausted(ctx, wait, "rate limit of %s exceeded", describe(st, accountID, l.globalState))
+		}
+	}
+	for _, st := range []*state{l.globalState, account} {
+		if st.limits.RequestsPerSecond > 0 {
+			st.tokens--
+		}
+	}
+	return nil
+}
+
+// refill adds the tokens earned since the last refill and returns how long
+// until a token is available, or 0 when one is
+func (st *state) refill(now time.Time) time.Duration {
+	rate := st.limits.RequestsPerSecond
+	if rate <= 0 {
+		return 0
+	}
+	st.tokens = math.Min(float64(burst(st.limits)), st.tokens+now.Sub(st.refilled).Seconds()*rate)
+	st.refilled = now
+	if st.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - st.tokens) / rate * float64(time.Second))
+}
+
+// StartImport admits an import under the global and the account's concurrent
+// import limits. Call the returned func when the import ends.
+func (l *Limiter) StartImport(ctx context.Context, accountID string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	account, err := l.stateFor(ctx, accountID, l.now())
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range []*state{l.globalState, account} {
+		if max := st.limits.ConcurrentImports; max > 0 && st.imports >= max {
+			st.rejected++
+			return nil, exhausted(ctx, time.Second, "%d concurrent imports of %s already running", max, describe(st, accountID, l.globalState))
+		}
+	}
+	l.globalState.imports++
+	account.imports++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.globalState.imports--
+			account.imports--
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// ReserveRecords counts n records against today's global and account quotas.
+// Records that would go over either quota are refused as a whole with
+// ResourceExhausted, retryable at the start of the next day.
+func (l *Limiter) ReserveRecords(ctx context.Context, accountID string, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	account, err := l.stateFor(ctx, accountID, now)
+	if err != nil {
+		return err
+	}
+	for _, st := range []*state{l.globalState, account} {
+		st.rollDay(now)
+		if max := st.limits.RecordsPerDay; max > 0 && st.records+int64(n) > max {
+			st.rejected++
+			year, month, day := now.Date()
+			tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+			return exhausted(ctx, tomorrow.Sub(now), "daily quota of %d records for %s exceeded: %d used, %d requested", max, describe(st, accountID, l.globalState), st.records, n)
+		}
+	}
+	l.globalState.records += int64(n)
+	account.records += int64(n)
+	return nil
+}
+
+// rollDay resets the record count when the day has changed
+func (st *state) rollDay(now time.Time) {
+	if day := now.Format(time.DateOnly); st.day != day {
+		st.day = day
+		st.records = 0
+	}
+}
+
+// Usage returns the global usage followed by every account seen so far, or
+// only the given account
+func (l *Limiter) Usage(accountID string) []Usage {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	usages := []Usage{l.globalState.usage("", now)}
+	if accountID != "" {
+		st, ok := l.states[accountID]
+		if !ok {
+			st = l.newAccountState(accountID, now)
+		}
+		return append(usages, st.usage(accountID, now))
+	}
+	ids := make([]string, 0, len(l.states))
+	for id := range l.states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		usages = append(usages, l.states[id].usage(id, now))
+	}
+	return usages
+}
+
+func (st *state) usage(accountID string, now time.Time) Usage {
+	st.rollDay(now)
+	limits := st.limits
+	limits.Burst = burst(limits)
+	return Usage{
+		AccountID:         accountID,
+		Limits:            limits,
+		ConcurrentImports: st.imports,
+		RecordsToday:      st.records,
+		Day:               st.day,
+		ThrottledRequests: st.throttled,
+		RejectedImports:   st.rejected,
+	}
+}
+
+// UnaryServerInterceptor applies the request rates to every RPC except the
+// exempt ones, given as full method names or prefixes. The account is the
+// request's account_id; requests for an account the authenticated principal
+// may not act for are refused with PermissionDenied before taking anything.
+func (l *Limiter) UnaryServerInterceptor(exempt ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if l == nil || isExempt(info.FullMethod, exempt) {
+			return handler(ctx, req)
+		}
+		var accountID string
+		if r, ok := req.(interface{ GetAccountId() string }); ok {
+			accountID = r.GetAccountId()
+			if err := auth.Authorize(ctx, accountID); err != nil {
+				return nil, err
+			}
+		}
+		if err := l.Allow(ctx, accountID); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func isExempt(fullMethod string, exempt []string) bool {
+	for _, prefix := range exempt {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe names the limits a state belongs to
+func describe(st *state, accountID string, global *state) string {
+	if st == global {
+		return "all accounts"
+	}
+	return fmt.Sprintf("account %q", accountID)
+}
+
+// RetryAfter returns the retry-after value, in whole seconds, of an error
+// carrying a RetryInfo detail
+func RetryAfter(err error) (string, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return strconv.FormatInt(retrySeconds(info.GetRetryDelay().AsDuration()), 10), true
+		}
+	}
+	return "", false
+}
+
+// retrySeconds rounds a wait up to whole seconds, at least one
+func retrySeconds(wait time.Duration) int64 {
+	if seconds := int64(math.Ceil(wait.Seconds())); seconds > 1 {
+		return seconds
+	}
+	return 1
+}
+
+// exhausted returns a ResourceExhausted error carrying a RetryInfo detail and
+// sets the retry-after response header
+func exhausted(ctx context.Context, wait time.Duration, format string, args ...interface{}) error {
+	seconds := retrySeconds(wait)
+	// Fails outside an RPC, e.g. for inbox imports; the error still says when
+	// to retry
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.FormatInt(seconds, 10)))
+
+	st := status.Newf(codes.ResourceExhausted, format+"; retry after %ds", append(args, seconds)...)
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(wait)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
\ No newline at end of file
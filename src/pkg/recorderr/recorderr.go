@@ -0,0 +1,134 @@
+package recorderr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code is a stable error code. Values match the ErrorCode enum in the proto API.
+type Code int
+
+const (
+	CodeUnspecified Code = iota
+	CodeParseFailed
+	CodeMissingField
+	CodeInvalidDate
+	CodeInvalidAmount
+	CodeInvalidVehicleClass
+	CodeValidationFailed
+	CodeConversionFailed
+	CodeSaveFailed
+	CodeDuplicate
+	CodeCancelled
+	CodeInvalidRequest
+	CodeCorrection
+	CodeStatusChange
+)
+
+var codeNames = map[Code]string{
+	CodeUnspecified:         "UNSPECIFIED",
+	CodeParseFailed:         "PARSE_FAILED",
+	CodeMissingField:        "MISSING_FIELD",
+	CodeInvalidDate:         "INVALID_DATE",
+	CodeInvalidAmount:       "INVALID_AMOUNT",
+	CodeInvalidVehicleClass: "INVALID_VEHICLE_CLASS",
+	CodeValidationFailed:    "VALIDATION_FAILED",
+	CodeConversionFailed:    "CONVERSION_FAILED",
+	CodeSaveFailed:          "SAVE_FAILED",
+	CodeDuplicate:           "DUPLICATE",
+	CodeCancelled:           "CANCELLED",
+	CodeInvalidRequest:      "INVALID_REQUEST",
+	CodeCorrection:          "CORRECTION",
+	CodeStatusChange:        "STATUS_CHANGE",
+}
+
+// String returns the code name
+func (c Code) String() string {
+	if name, ok := codeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("CODE_%d", int(c))
+}
+
+// Severity is how serious an error is. Values match the Severity enum in the proto API.
+type Severity int
+
+const (
+	SeverityUnspecified Severity = iota
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unspecified"
+	}
+}
+
+// Error is a structured error about one CSV record
+type Error struct {
+	Code        Code
+	LineNumber  int // 1-based line in the source CSV, 0 if unknown
+	RecordIndex int // 0-based index among parsed records, -1 if not record-specific
+	Field       string
+	Severity    Severity
+	Message     string
+	Retryable   bool
+	Rule        string // validation rule that reported the error, if any
+	Err         error
+}
+
+// New creates an error-severity record error for a field
+func New(code Code, field, message string) *Error {
+	return &Error{
+		Code:        code,
+		RecordIndex: -1,
+		Field:       field,
+		Severity:    SeverityError,
+		Message:     message,
+	}
+}
+
+// Wrap creates an error-severity record error that wraps a cause
+func Wrap(code Code, field string, err error) *Error {
+	e := New(code, field, err.Error())
+	e.Err = err
+	return e
+}
+
+// Error returns the human-readable message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// At returns a copy of the error located at a record
+func (e *Error) At(lineNumber, recordIndex int) *Error {
+	c := *e
+	c.LineNumber = lineNumber
+	c.RecordIndex = recordIndex
+	return &c
+}
+
+// From returns err as a record error. Errors that are not already
+// structured become the given fallback code with no field.
+func From(err error, fallback Code) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Wrap(fallback, "", err)
+}
\ No newline at end of file
@@ -0,0 +1,137 @@
+package rules
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRulesYAML holds the built-in rule sets
+//
+//go:embed default_rules.yaml
+var defaultRulesYAML []byte
+
+// RuleSet is a named list of compiled rules
+type RuleSet struct {
+	Name  string
+	rules []*compiledRule
+}
+
+// NewRuleSet compiles rules into a rule set
+func NewRuleSet(name string, rules []Rule) (*RuleSet, error) {
+	set := &RuleSet{Name: name}
+	for _, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule set %s: %w", name, err)
+		}
+		set.rules = append(set.rules, c)
+	}
+	return set, nil
+}
+
+// Len returns the number of rules in the set
+func (s *RuleSet) Len() int {
+	return len(s.rules)
+}
+
+// Evaluate checks a record against every rule and returns the violations
+func (s *RuleSet) Evaluate(r parser.ActualETCRecord) []*recorderr.Error {
+	return s.EvaluateAt(r, time.Now())
+}
+
+// EvaluateAt is Evaluate with an explicit current time for "now" bounds
+func (s *RuleSet) EvaluateAt(r parser.ActualETCRecord, now time.Time) []*recorderr.Error {
+	var violations []*recorderr.Error
+	for _, rule := range s.rules {
+		if e := rule.evaluate(r, now); e != nil {
+			violations = append(violations, e)
+		}
+	}
+	return violations
+}
+
+// File is the YAML layout of a rules file
+type File struct {
+	Default  string            `yaml:"default"`
+	Accounts map[string]string `yaml:"accounts"`
+	RuleSets map[string][]Rule `yaml:"rule_sets"`
+}
+
+// Registry resolves the rule set for an account
+type Registry struct {
+	Default  *RuleSet
+	Sets     map[string]*RuleSet
+	Accounts map[string]*RuleSet
+}
+
+// Parse builds a registry from YAML
+func Parse(data []byte) (*Registry, error) {
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	return NewRegistry(f)
+}
+
+// LoadFile builds a registry from a YAML file
+func LoadFile(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+	return Parse(data)
+}
+
+// NewRegistry compiles every rule set of a rules file
+func NewRegistry(f File) (*Registry, error) {
+	reg := &Registry{
+		Sets:     make(map[string]*RuleSet),
+		Accounts: make(map[string]*RuleSet),
+	}
+
+	for name, rules := range f.RuleSets {
+		set, err := NewRuleSet(name, rules)
+		if err != nil {
+			return nil, err
+		}
+		reg.Sets[name] = set
+	}
+
+	def, ok := reg.Sets[f.Default]
+	if !ok {
+		return nil, fmt.Errorf("default rule set %q is not defined", f.Default)
+	}
+	reg.Default = def
+
+	for accountID, name := range f.Accounts {
+		set, ok := reg.Sets[name]
+		if !ok {
+			return nil, fmt.Errorf("account %s: rule set %q is not defined", accountID, name)
+		}
+		reg.Accounts[accountID] = set
+	}
+	return reg, nil
+}
+
+// Default returns the built-in registry
+func Default() *Registry {
+	reg, err := Parse(defaultRulesYAML)
+	if err != nil {
+		panic(fmt.Sprintf("built-in rules are invalid: %v", err))
+	}
+	return reg
+}
+
+// For returns the rule set for an account
+func (r *Registry) For(accountID string) *RuleSet {
+	if set, ok := r.Accounts[accountID]; ok {
+		return set
+	}
+	return r.Default
+}
\ No newline at end of file
@@ -0,0 +1,348 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+)
+
+// Rule types
+const (
+	TypeRequired  = "required"   // the field must not be empty
+	TypeRange     = "range"      // a numeric field must be within min/max
+	TypeAllowed   = "allowed"    // the field must be one of values
+	TypeDateRange = "date_range" // a date field must be within min/max ("YYYY-MM-DD" or "now")
+	TypeAfter     = "after"      // a date field must not be earlier than other
+)
+
+// Rule is one declarative check, as written in YAML
+type Rule struct {
+	Name     string   `yaml:"name" json:"name"`
+	Type     string   `yaml:"type" json:"type"`
+	Field    string   `yaml:"field" json:"field"`
+	Other    string   `yaml:"other,omitempty" json:"other,omitempty"`
+	Min      string   `yaml:"min,omitempty" json:"min,omitempty"`
+	Max      string   `yaml:"max,omitempty" json:"max,omitempty"`
+	Values   []string `yaml:"values,omitempty" json:"values,omitempty"`
+	Formats  []string `yaml:"formats,omitempty" json:"formats,omitempty"`   // source layouts the rule applies to; empty means all
+	Severity string   `yaml:"severity,omitempty" json:"severity,omitempty"` // error (default), warning or info
+	Message  string   `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// stringFields maps rule field names to string columns
+var stringFields = map[string]func(parser.ActualETCRecord) string{
+	"entry_date":     func(r parser.ActualETCRecord) string { return r.EntryDate },
+	"entry_time":     func(r parser.ActualETCRecord) string { return r.EntryTime },
+	"exit_date":      func(r parser.ActualETCRecord) string { return r.ExitDate },
+	"exit_time":      func(r parser.ActualETCRecord) string { return r.ExitTime },
+	"entry_ic":       func(r parser.ActualETCRecord) string { return r.EntryIC },
+	"exit_ic":        func(r parser.ActualETCRecord) string { return r.ExitIC },
+	"route_info":     func(r parser.ActualETCRecord) string { return r.RouteInfo },
+	"vehicle_number": func(r parser.ActualETCRecord) string { return r.VehicleNumber },
+	"card_number":    func(r parser.ActualETCRecord) string { return r.CardNumber },
+	"notes":          func(r parser.ActualETCRecord) string { return r.Notes },
+}
+
+// intFields maps rule field names to numeric columns
+var intFields = map[string]func(parser.ActualETCRecord) int{
+	"etc_amount":       func(r parser.ActualETCRecord) int { return r.ETCAmount },
+	"normal_amount":    func(r parser.ActualETCRecord) int { return r.NormalAmount },
+	"discount_applied": func(r parser.ActualETCRecord) int { return r.DiscountApplied },
+	"mileage":          func(r parser.ActualETCRecord) int { return r.Mileage },
+	"vehicle_class":    func(r parser.ActualETCRecord) int { return r.VehicleClass },
+}
+
+// timeFields pairs each date field with its time-of-day field
+var timeFields = map[string]string{
+	"entry_date": "entry_time",
+	"exit_date":  "exit_time",
+}
+
+// check reports whether a record passes, and a description of the failure
+type check func(r parser.ActualETCRecord, now time.Time) (bool, string)
+
+// compiledRule is a rule ready for evaluation
+type compiledRule struct {
+	Rule
+	code     recorderr.Code
+	severity recorderr.Severity
+	formats  map[string]bool
+	check    check
+}
+
+// compile validates a rule and builds its check
+func compile(rule Rule) (*compiledRule, error) {
+	if rule.Name == "" {
+		rule.Name = rule.Type + ":" + rule.Field
+	}
+
+	severity, err := parseSeverity(rule.Severity)
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+	}
+
+	c := &compiledRule{Rule: rule, severity: severity, code: recorderr.CodeValidationFailed}
+	if len(rule.Formats) > 0 {
+		c.formats = make(map[string]bool)
+		for _, f := range rule.Formats {
+			c.formats[f] = true
+		}
+	}
+
+	switch rule.Type {
+	case TypeRequired:
+		c.check, err = compileRequired(rule)
+		c.code = recorderr.CodeMissingField
+	case TypeRange:
+		c.check, err = compileRange(rule)
+		c.code = numericCode(rule.Field)
+	case TypeAllowed:
+		c.check, err = compileAllowed(rule)
+		c.code = numericCode(rule.Field)
+	case TypeDateRange:
+		c.check, err = compileDateRange(rule)
+		c.code = recorderr.CodeInvalidDate
+	case TypeAfter:
+		c.check, err = compileAfter(rule)
+		c.code = recorderr.CodeInvalidDate
+	default:
+		err = fmt.Errorf("unknown rule type: %s", rule.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+	}
+	return c, nil
+}
+
+// applies reports whether the rule covers records of the given source layout
+func (c *compiledRule) applies(format string) bool {
+	return c.formats == nil || c.formats[format]
+}
+
+// evaluate returns a record error when the record breaks the rule
+func (c *compiledRule) evaluate(r parser.ActualETCRecord, now time.Time) *recorderr.Error {
+	if !c.applies(r.Format) {
+		return nil
+	}
+	ok, detail := c.check(r, now)
+	if ok {
+		return nil
+	}
+
+	message := c.Message
+	if message == "" {
+		message = detail
+	}
+	e := recorderr.New(c.code, c.Field, message)
+	e.Severity = c.severity
+	e.Rule = c.Name
+	return e
+}
+
+func compileRequired(rule Rule) (check, error) {
+	get, ok := stringFields[rule.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown text field: %s", rule.Field)
+	}
+	return func(r parser.ActualETCRecord, _ time.Time) (bool, string) {
+		return strings.TrimSpace(get(r)) != "", fmt.Sprintf("%s is required", rule.Field)
+	}, nil
+}
+
+func compileRange(rule Rule) (check, error) {
+	get, ok := intFields[rule.Field]
+	if !ok {
+		return nil, fmt.Errorf("unknown numeric field: %s", rule.Field)
+	}
+	if rule.Min == "" && rule.Max == "" {
+		return nil, fmt.Errorf("range needs min or max")
+	}
+
+	min, hasMin, err := parseBound(rule.Min)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min: %w", err)
+	}
+	max, hasMax, err := parseBound(rule.Max)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max: %w", err)
+	}
+
+	return func(r parser.ActualETCRecord, _ time.Time) (bool, string) {
+		v := get(r)
+		if hasMin && v < min {
+			return false, fmt.Sprintf("%s must be at least %d (got %d)", rule.Field, min, v)
+		}
+		if hasMax && v > max {
+			return false, fmt.Sprintf("%s must be at most %d (got %d)", rule.Field, max, v)
+		}
+		return true, ""
+	}, nil
+}
+
+func compileAllowed(rule Rule) (check, error) {
+	if len(rule.Values) == 0 {
+		return nil, fmt.Errorf("allowed needs values")
+	}
+
+	var get func(parser.ActualETCRecord) string
+	if s, ok := stringFields[rule.Field]; ok {
+		get = s
+	} else if n, ok := intFields[rule.Field]; ok {
+		get = func(r parser.ActualETCRecord) string { return strconv.Itoa(n(r)) }
+	} else {
+		return nil, fmt.Errorf("unknown field: %s", rule.Field)
+	}
+
+	allowed := make(map[string]bool, len(rule.Values))
+	for _, v := range rule.Values {
+		allowed[v] = true
+	}
+
+	return func(r parser.ActualETCRecord, _ time.Time) (bool, string) {
+		v := get(r)
+		return allowed[v], fmt.Sprintf("%s %q is not one of %s", rule.Field, v, strings.Join(rule.Values, ", "))
+	}, nil
+}
+
+func compileDateRange(rule Rule) (check, error) {
+	if _, ok := timeFields[rule.Field]; !ok {
+		return nil, fmt.Errorf("unknown date field: %s", rule.Field)
+	}
+	if rule.Min == "" && rule.Max == "" {
+		return nil, fmt.Errorf("date_range needs min or max")
+	}
+
+	min, err := parseDateBound(rule.Min)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min: %w", err)
+	}
+	max, err := parseDateBound(rule.Max)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max: %w", err)
+	}
+
+	return func(r parser.ActualETCRecord, now time.Time) (bool, string) {
+		if stringFields[rule.Field](r) == "" {
+			return true, "" // missing dates are the job of a required rule
+		}
+		date, err := recordDate(r, rule.Field, false)
+		if err != nil {
+			return false, fmt.Sprintf("%s: %v", rule.Field, err)
+		}
+		if lo, ok := min(now); ok && date.Before(lo) {
+			return false, fmt.Sprintf("%s %s is before %s", rule.Field, date.Format("2006-01-02"), lo.Format("2006-01-02"))
+		}
+		if hi, ok := max(now); ok && date.After(hi) {
+			return false, fmt.Sprintf("%s %s is after %s", rule.Field, date.Format("2006-01-02"), hi.Format("2006-01-02"))
+		}
+		return true, ""
+	}, nil
+}
+
+func compileAfter(rule Rule) (check, error) {
+	if _, ok := timeFields[rule.Field]; !ok {
+		return nil, fmt.Errorf("unknown date field: %s", rule.Field)
+	}
+	if _, ok := timeFields[rule.Other]; !ok {
+		return nil, fmt.Errorf("unknown date field: %s", rule.Other)
+	}
+
+	return func(r parser.ActualETCRecord, _ time.Time) (bool, string) {
+		later, err1 := recordDate(r, rule.Field, true)
+		earlier, err2 := recordDate(r, rule.Other, true)
+		if err1 != nil || err2 != nil {
+			return true, "" // unparseable or missing dates are reported by other rules
+		}
+		if later.Before(earlier) {
+			return false, fmt.Sprintf("%s is before %s", rule.Field, rule.Other)
+		}
+		return true, ""
+	}, nil
+}
+
+// numericCode picks the error code for a numeric field
+func numericCode(field string) recorderr.Code {
+	switch {
+	case field == "vehicle_class":
+		return recorderr.CodeInvalidVehicleClass
+	case strings.HasSuffix(field, "_amount") || field == "discount_applied":
+		return recorderr.CodeInvalidAmount
+	default:
+		return recorderr.CodeValidationFailed
+	}
+}
+
+// parseSeverity parses a severity name; empty means error
+func parseSeverity(name string) (recorderr.Severity, error) {
+	switch strings.ToLower(name) {
+	case "", "error":
+		return recorderr.SeverityError, nil
+	case "warning", "warn":
+		return recorderr.SeverityWarning, nil
+	case "info":
+		return recorderr.SeverityInfo, nil
+	default:
+		return recorderr.SeverityUnspecified, fmt.Errorf("unknown severity: %s", name)
+	}
+}
+
+// parseBound parses an optional integer bound
+func parseBound(s string) (int, bool, error) {
+	if s == "" {
+		return 0, false, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+// parseDateBound parses an optional "YYYY-MM-DD" or "now" bound
+func parseDateBound(s string) (func(now time.Time) (time.Time, bool), error) {
+	switch s {
+	case "":
+		return func(time.Time) (time.Time, bool) { return time.Time{}, false }, nil
+	case "now":
+		return func(now time.Time) (time.Time, bool) { return now, true }, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return func(time.Time) (time.Time, bool) { return t, true }, nil
+}
+
+// recordDate parses a date field ("YY/MM/DD" or "YYYY/MM/DD"), optionally
+// adding its time-of-day field ("HH:MM") when present
+func recordDate(r parser.ActualETCRecord, field string, withTime bool) (time.Time, error) {
+	value := strings.TrimSpace(stringFields[field](r))
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date format: %s", value)
+	}
+
+	nums := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date format: %s", value)
+		}
+		nums[i] = n
+	}
+	if nums[0] < 100 {
+		nums[0] += 2000
+	}
+	date := time.Date(nums[0], time.Month(nums[1]), nums[2], 0, 0, 0, 0, time.UTC)
+
+	if withTime {
+		if clock, err := time.Parse("15:04", strings.TrimSpace(stringFields[timeFields[field]](r))); err == nil {
+			date = date.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+		}
+	}
+	return date, nil
+}
\ No newline at end of file
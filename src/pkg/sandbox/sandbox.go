@@ -0,0 +1,190 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Violation is returned when a path breaks the sandbox rules. Its message is
+// safe to return to clients: it never includes file contents or resolved paths.
+type Violation struct {
+	Reason string
+}
+
+// Error returns the violation reason
+func (v *Violation) Error() string {
+	return v.Reason
+}
+
+// Sandbox restricts which files may be read on behalf of clients
+type Sandbox struct {
+	roots      []string // resolved roots, checked against resolved paths
+	lexical    []string // roots as configured, checked against paths as written
+	maxSize    int64
+	extensions map[string]bool
+}
+
+// New creates a sandbox. Roots are resolved to absolute paths with symlinks
+// evaluated, so they must exist. A maxSize of 0 means no size limit, and an
+// empty extension list allows every extension.
+func New(roots []string, maxSize int64, extensions []string) (*Sandbox, error) {
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("at least one allowed root is required")
+	}
+	if maxSize < 0 {
+		return nil, fmt.Errorf("invalid max file size: %d", maxSize)
+	}
+
+	s := &Sandbox{maxSize: maxSize}
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %s: %w", root, err)
+		}
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed root %s: %w", root, err)
+		}
+		s.roots = append(s.roots, resolved)
+		s.lexical = append(s.lexical, abs, resolved)
+	}
+
+	if len(extensions) > 0 {
+		s.extensions = make(map[string]bool)
+		for _, ext := range extensions {
+			s.extensions[normalizeExt(ext)] = true
+		}
+	}
+	return s, nil
+}
+
+// Roots returns the resolved allowed roots
+func (s *Sandbox) Roots() []string {
+	return append([]string(nil), s.roots...)
+}
+
+// Resolve checks a client-supplied path and returns the resolved path to open.
+// The path must be inside an allowed root both as written (after cleaning "..")
+// and after symlinks are followed, must be a regular file within the size limit,
+// and must have an allowed extension. Nothing is opened.
+func (s *Sandbox) Resolve(path string) (string, error) {
+	if path == "" {
+		return "", &Violation{Reason: "path is empty"}
+	}
+	if strings.ContainsRune(path, 0) {
+		return "", &Violation{Reason: "path contains a NUL byte"}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", &Violation{Reason: "path cannot be resolved"}
+	}
+
+	// Reject lexically before touching the filesystem, so probing paths
+	// outside the roots does not reveal whether they exist
+	if !s.allowedExt(abs) {
+		return "", &Violation{Reason: "file extension is not allowed"}
+	}
+	if !within(s.lexical, abs) {
+		return "", &Violation{Reason: "path is outside the allowed directories"}
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", err
+		}
+		return "", &Violation{Reason: "path cannot be resolved"}
+	}
+	if !within(s.roots, resolved) {
+		return "", &Violation{Reason: "path is outside the allowed directories"}
+	}
+	if !s.allowedExt(resolved) {
+		return "", &Violation{Reason: "file extension is not allowed"}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", &Violation{Reason: "path is not a regular file"}
+	}
+	if s.maxSize > 0 && info.Size() > s.maxSize {
+		return "", &Violation{Reason: fmt.Sprintf("file is larger than %d bytes", s.maxSize)}
+	}
+	return resolved, nil
+}
+
+// ResolveDir checks a client-supplied directory, such as the base of a glob,
+// before it is listed. The same root checks as Resolve apply; files found in
+// it must still be checked with Resolve.
+func (s *Sandbox) ResolveDir(path string) (string, error) {
+	if path == "" {
+		return "", &Violation{Reason: "path is empty"}
+	}
+	if strings.ContainsRune(path, 0) {
+		return "", &Violation{Reason: "path contains a NUL byte"}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", &Violation{Reason: "path cannot be resolved"}
+	}
+	if !within(s.lexical, abs) {
+		return "", &Violation{Reason: "path is outside the allowed directories"}
+	}
+
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", err
+		}
+		return "", &Violation{Reason: "path cannot be resolved"}
+	}
+	if !within(s.roots, resolved) {
+		return "", &Violation{Reason: "path is outside the allowed directories"}
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", &Violation{Reason: "path is not a directory"}
+	}
+	return resolved, nil
+}
+
+// within reports whether a cleaned absolute path is one of roots or below one
+func within(roots []string, path string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedExt reports whether a path has an allowed extension
+func (s *Sandbox) allowedExt(path string) bool {
+	if s.extensions == nil {
+		return true
+	}
+	return s.extensions[normalizeExt(filepath.Ext(path))]
+}
+
+// normalizeExt lowercases an extension and ensures a leading dot
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
\ No newline at end of file
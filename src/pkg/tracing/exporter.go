@@ -0,0 +1,135 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+)
+
+// Exporter receives finished, sampled spans
+type Exporter interface {
+	ExportSpan(span SpanData) error
+}
+
+// WriterExporter writes each span as one JSON line, for stdout or a file. It
+// lets traces be inspected without a collector. Card and vehicle numbers are
+// redacted from attribute values and status messages.
+type WriterExporter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterExporter writes spans to w
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{w: w}
+}
+
+// NewFileExporter appends spans to the file at path, creating it if needed
+func NewFileExporter(path string) (*WriterExporter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %w", err)
+	}
+	return &WriterExporter{w: f, closer: f}, nil
+}
+
+// spanJSON is the exported line. Field names follow the OpenTelemetry data
+// model, flattened to one span per line.
+type spanJSON struct {
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	StartTime    time.Time              `json:"start_time"`
+	EndTime      time.Time              `json:"end_time"`
+	DurationMs   float64                `json:"duration_ms"`
+	Status       statusJSON             `json:"status"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Events       []eventJSON            `json:"events,omitempty"`
+	Resource     map[string]interface{} `json:"resource"`
+}
+
+type statusJSON struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type eventJSON struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// ExportSpan writes the span
+func (e *WriterExporter) ExportSpan(span SpanData) error {
+	out := spanJSON{
+		Name:       span.Name,
+		Kind:       span.Kind.String(),
+		TraceID:    span.SpanContext.TraceID.String(),
+		SpanID:     span.SpanContext.SpanID.String(),
+		StartTime:  span.Start,
+		EndTime:    span.End,
+		DurationMs: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+		Status:     statusJSON{Code: "unset"},
+		Attributes: attrMap(span.Attributes),
+		Resource:   map[string]interface{}{"service.name": span.Service},
+	}
+	if span.Parent.IsValid() {
+		out.ParentSpanID = span.Parent.String()
+	}
+	if span.Error {
+		out.Status = statusJSON{Code: "error", Message: logging.Redact(span.StatusMessage)}
+	}
+	for _, ev := range span.Events {
+		out.Events = append(out.Events, eventJSON{Name: ev.Name, Time: ev.Time, Attributes: attrMap(ev.Attributes)})
+	}
+
+	line, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file of a file exporter
+func (e *WriterExporter) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+	return e.closer.Close()
+}
+
+// attrMap converts attributes to JSON values, redacting strings
+func attrMap(attrs []slog.Attr) map[string]interface{} {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		switch v.Kind() {
+		case slog.KindString:
+			m[a.Key] = logging.Redact(v.String())
+		case slog.KindDuration:
+			m[a.Key] = v.Duration().Seconds()
+		case slog.KindTime:
+			m[a.Key] = v.Time()
+		case slog.KindAny, slog.KindGroup, slog.KindLogValuer:
+			m[a.Key] = logging.Redact(v.String())
+		default:
+			m[a.Key] = v.Any()
+		}
+	}
+	return m
+}
\ No newline at end of file
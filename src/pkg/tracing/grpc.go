@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/grpcstream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor starts a server span for each RPC, continuing the
+// trace of an incoming traceparent. Handlers start child spans from the RPC
+// context.
+func (t *Tracer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := t.startServer(ctx, info.FullMethod)
+		resp, err := handler(ctx, req)
+		endRPC(span, err, serverError)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming RPCs
+func (t *Tracer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := t.startServer(ss.Context(), info.FullMethod)
+		err := handler(srv, grpcstream.WithContext(ss, ctx))
+		endRPC(span, err, serverError)
+		return err
+	}
+}
+
+// UnaryClientInterceptor starts a client span for each outgoing RPC and sends
+// its traceparent, so the called service (such as db_service) continues the
+// trace
+func (t *Tracer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, span := t.startClient(ctx, method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		endRPC(span, err, clientError)
+		return err
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming RPCs. The
+// span ends when the stream is established.
+func (t *Tracer) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := t.startClient(ctx, method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		endRPC(span, err, clientError)
+		return stream, err
+	}
+}
+
+func (t *Tracer) startServer(ctx context.Context, fullMethod string) (context.Context, *Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = Extract(ctx, func(key string) string {
+			if values := md.Get(key); len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		})
+	}
+	return t.start(ctx, strings.TrimPrefix(fullMethod, "/"), SpanKindServer, rpcAttrs(fullMethod))
+}
+
+func (t *Tracer) startClient(ctx context.Context, fullMethod string) (context.Context, *Span) {
+	ctx, span := t.start(ctx, strings.TrimPrefix(fullMethod, "/"), SpanKindClient, rpcAttrs(fullMethod))
+	if span == nil {
+		return ctx, nil
+	}
+	Inject(ctx, func(key, value string) {
+		ctx = metadata.AppendToOutgoingContext(ctx, key, value)
+	})
+	return ctx, span
+}
+
+// rpcAttrs returns the OpenTelemetry RPC attributes of "/package.Service/Method"
+func rpcAttrs(fullMethod string) []slog.Attr {
+	service, method := "", strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		service, method = method[:i], method[i+1:]
+	}
+	return []slog.Attr{
+		slog.String("rpc.system", "grpc"),
+		slog.String("rpc.service", service),
+		slog.String("rpc.method", method),
+	}
+}
+
+// endRPC records the status code and ends the span. isError decides which
+// codes mark the span as failed.
+func endRPC(span *Span, err error, isError func(codes.Code) bool) {
+	if span == nil {
+		return
+	}
+	code := status.Code(err)
+	span.SetAttributes(slog.Int("rpc.grpc.status_code", int(code)))
+	if isError(code) {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// serverError reports server-side failures; client errors such as
+// InvalidArgument do not fail a server span
+func serverError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// clientError reports any failed call
+func clientError(code codes.Code) bool {
+	return code != codes.OK
+}
\ No newline at end of file
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// Middleware starts a server span for each HTTP request, continuing the trace
+// of an incoming traceparent header. The request's traceparent is replaced by
+// the new span's, so a gateway forwarding the header to gRPC continues the
+// same trace. A nil tracer passes requests through.
+func (t *Tracer) Middleware(next http.Handler) http.Handler {
+	if t == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := Extract(r.Context(), r.Header.Get)
+		ctx, span := t.start(ctx, r.Method+" "+r.URL.Path, SpanKindServer, []slog.Attr{
+			slog.String("http.request.method", r.Method),
+			slog.String("url.path", r.URL.Path),
+		})
+		r = r.WithContext(ctx)
+		r.Header.Del(TracestateHeader)
+		Inject(ctx, r.Header.Set)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		span.SetAttributes(slog.Int("http.response.status_code", rec.status))
+		if rec.status >= 500 {
+			span.SetError(http.StatusText(rec.status))
+		}
+		span.End()
+	})
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush supports streamed responses
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
\ No newline at end of file
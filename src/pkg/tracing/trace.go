@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// W3C trace-context header names, also used as gRPC metadata keys
+const (
+	TraceparentHeader = "traceparent"
+	TracestateHeader  = "tracestate"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// String returns the ID as 32 lower-case hex characters
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID is not all zeros
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the ID as 16 lower-case hex characters
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid reports whether the ID is not all zeros
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// SpanContext is the part of a span that is propagated to other processes
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Sampled    bool
+	TraceState string // passed on unchanged
+	Remote     bool   // extracted from an incoming request
+}
+
+// IsValid reports whether both IDs are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent formats the span context as a W3C traceparent header value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses a W3C traceparent header value. Versions other
+// than 00 are accepted as long as they start with the version 00 fields.
+func ParseTraceparent(value string) (SpanContext, bool) {
+	var sc SpanContext
+	var version, flags [1]byte
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || !decodeHex(version[:], parts[0]) || version[0] == 0xff {
+		return sc, false
+	}
+	if version[0] == 0 && len(parts) != 4 {
+		return sc, false
+	}
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return sc, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	sc.Remote = true
+	return sc, sc.IsValid()
+}
+
+// decodeHex decodes lower-case hex of exactly len(dst) bytes
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// Inject writes the span context of ctx as traceparent and tracestate with
+// set. It writes nothing outside a trace.
+func Inject(ctx context.Context, set func(key, value string)) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		set(TracestateHeader, sc.TraceState)
+	}
+}
+
+// Extract returns ctx with the remote span context read with get, if it
+// carries a valid traceparent
+func Extract(ctx context.Context, get func(key string) string) context.Context {
+	sc, ok := ParseTraceparent(get(TraceparentHeader))
+	if !ok {
+		return ctx
+	}
+	sc.TraceState = get(TracestateHeader)
+	return ContextWithSpanContext(ctx, sc)
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a context whose spans are children of sc
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the current span context, which is invalid
+// outside a trace
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
\ No newline at end of file
@@ -0,0 +1,197 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// SpanKind says whether a span handles a request, makes one, or is an internal
+// step of the process
+type SpanKind int
+
+// Span kinds, as in OpenTelemetry
+const (
+	SpanKindInternal SpanKind = iota
+	SpanKindServer
+	SpanKindClient
+)
+
+// String returns the lower-case kind name
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindServer:
+		return "server"
+	case SpanKindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Event is a timestamped annotation of a span, such as a recorded error
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []slog.Attr
+}
+
+// SpanData is a finished span as handed to the exporter
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID // invalid for a root span
+	Start         time.Time
+	End           time.Time
+	Attributes    []slog.Attr
+	Events        []Event
+	Error         bool
+	StatusMessage string
+	Service       string
+}
+
+// Tracer starts spans and hands the sampled ones to an exporter when they end.
+// A nil *Tracer starts no spans, so services built without tracing need no
+// checks.
+type Tracer struct {
+	service   string
+	exporter  Exporter
+	threshold uint64 // sampled when the low 8 trace ID bytes are below it
+	always    bool
+}
+
+// NewTracer creates a tracer for a service. sampleRatio is the fraction of
+// new traces that are exported (0..1); spans continuing an incoming trace
+// follow the caller's sampling decision.
+func NewTracer(service string, exporter Exporter, sampleRatio float64) *Tracer {
+	t := &Tracer{service: service, exporter: exporter}
+	switch {
+	case sampleRatio >= 1:
+		t.always = true
+	case sampleRatio > 0:
+		t.threshold = uint64(sampleRatio * math.MaxUint64)
+	}
+	return t
+}
+
+// Start starts an internal span as a child of the current span of ctx, or
+// a new trace. The returned context carries the span; call End on it.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, *Span) {
+	return t.start(ctx, name, SpanKindInternal, attrs)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind SpanKind, attrs []slog.Attr) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID = parent.TraceID
+		sc.Sampled = parent.Sampled
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+
+	span := &Span{tracer: t}
+	span.data = SpanData{
+		Name:        name,
+		Kind:        kind,
+		SpanContext: sc,
+		Parent:      parent.SpanID,
+		Start:       time.Now(),
+		Attributes:  attrs,
+		Service:     t.service,
+	}
+	return ContextWithSpanContext(ctx, sc), span
+}
+
+// sample decides whether a new trace is exported
+func (t *Tracer) sample(id TraceID) bool {
+	return t.always || binary.BigEndian.Uint64(id[8:]) < t.threshold
+}
+
+// Span is one timed operation of a trace. A nil *Span ignores all calls.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SpanContext returns the IDs propagated for the span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed and adds an exception event. A nil
+// error is ignored.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = true
+	s.data.StatusMessage = err.Error()
+	s.data.Events = append(s.data.Events, Event{
+		Name:       "exception",
+		Time:       time.Now(),
+		Attributes: []slog.Attr{slog.String("exception.message", err.Error())},
+	})
+}
+
+// SetError marks the span as failed without an exception event
+func (s *Span) SetError(message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = true
+	s.data.StatusMessage = message
+}
+
+// End finishes the span and exports it if its trace is sampled. Later calls
+// do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if !data.SpanContext.Sampled || s.tracer.exporter == nil {
+		return
+	}
+	if err := s.tracer.exporter.ExportSpan(data); err != nil {
+		slog.Warn("tracing: failed to export span", "span", data.Name, "error", err)
+	}
+}
\ No newline at end of file
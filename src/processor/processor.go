@@ -0,0 +1,178 @@
+// Package processor parses, validates and optionally saves ETC statement CSV
+// files in process, without running the server. Its types are independent of
+// the server's packages and change only in backward compatible ways.
+//
+//	res, err := processor.Process(ctx, file, processor.Options{AccountID: "acct-1"})
+//	for _, r := range res.Records {
+//		if r.Valid { ... }
+//	}
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultAccountID is used when Options.AccountID is empty
+const DefaultAccountID = "local"
+
+// Encoding is the character encoding of the input
+type Encoding int
+
+const (
+	EncodingAuto     Encoding = iota // a BOM, then UTF-16 NUL patterns, then UTF-8 validity, else Shift-JIS
+	EncodingShiftJIS                 // CP932, as exported by issuer portals
+	EncodingUTF8
+	EncodingUTF16
+)
+
+// String returns the encoding name: auto, shift_jis, utf8 or utf16
+func (e Encoding) String() string {
+	return parser.Encoding(e).String()
+}
+
+// MarshalText encodes the encoding as its name
+func (e Encoding) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+// Saver stores accepted records. Each record is passed as a
+// map[string]interface{} with the keys account_id, date (time.Time),
+// entry_ic, exit_ic, route, vehicle_type, amount (int) and card_number, plus
+// the row's fields named as in Record's JSON tags, as the server saves it.
+// A Saver that also implements
+// UpsertETCData(matchKey string, data interface{}) (replaced bool, err error)
+// stores records under their duplicate key, so a re-issued row replaces the
+// earlier one.
+type Saver interface {
+	SaveETCData(data interface{}) error
+}
+
+// Options configure Process. The zero value detects the encoding, applies
+// the default duplicate strategy without validation rules and saves nothing.
+type Options struct {
+	// AccountID selects the rule set and duplicate strategy of the account
+	// and is saved with each record; DefaultAccountID when empty
+	AccountID string
+
+	// Encoding of the input; EncodingAuto detects it
+	Encoding Encoding
+
+	// DuplicateStrategy is the key rows are compared by: exact_row,
+	// card_exit_amount (the default) or ignore_status
+	DuplicateStrategy string
+
+	// SkipDuplicates skips rows that duplicate an earlier row of the input
+	// instead of accepting them
+	SkipDuplicates bool
+
+	// Rules is a validation rules file in YAML, in the format of the
+	// server's rules.file; nil applies no rules
+	Rules []byte
+
+	// Saver stores the accepted records; nil only checks them
+	Saver Saver
+
+	// Logger receives a summary of each run; nil logs nothing
+	Logger *slog.Logger
+}
+
+// Process reads an ETC statement CSV from r, checks every record and, with
+// Options.Saver, saves the accepted ones. Problems with records are reported
+// as diagnostics; an error means the input could not be processed at all.
+func Process(ctx context.Context, r io.Reader, opts Options) (*Result, error) {
+	if r == nil {
+		return nil, errors.New("reader cannot be nil")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	enc := parser.Encoding(opts.Encoding)
+	if enc == parser.EncodingAuto {
+		enc = parser.DetectEncoding(data)
+	}
+	text, err := parser.Decode(data, enc)
+	if err != nil {
+		return nil, err
+	}
+	records, err := parser.NewETCCSVParser().Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := newService(opts)
+	if err != nil {
+		return nil, err
+	}
+	accountID := opts.AccountID
+	if accountID == "" {
+		accountID = DefaultAccountID
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	resp, err := service.ProcessCSVData(logging.NewContext(ctx, logger), &pb.ProcessCSVDataRequest{
+		CsvData:        text,
+		AccountId:      accountID,
+		SkipDuplicates: opts.SkipDuplicates,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			err = errors.New(st.Message())
+		}
+		return nil, err
+	}
+	return newResult(Encoding(enc), records, resp), nil
+}
+
+// ProcessFile processes the file at path, see Process
+func ProcessFile(ctx context.Context, path string, opts Options) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Process(ctx, f, opts)
+}
+
+// newService builds the service pipeline for the options
+func newService(opts Options) (*handler.DataProcessorService, error) {
+	strategy := opts.DuplicateStrategy
+	if strategy == "" {
+		strategy = string(dedup.DefaultStrategy)
+	}
+	duplicates, err := dedup.NewPolicySet(strategy, nil)
+	if err != nil {
+		return nil, err
+	}
+	var ruleSets *rules.Registry
+	if opts.Rules != nil {
+		if ruleSets, err = rules.Parse(opts.Rules); err != nil {
+			return nil, fmt.Errorf("invalid rules: %w", err)
+		}
+	}
+
+	var db handler.DBClient
+	if opts.Saver != nil {
+		db = opts.Saver
+	}
+	return handler.NewDataProcessorServiceWithOptions(db,
+		handler.WithDuplicatePolicies(duplicates),
+		handler.WithRules(ruleSets),
+	), nil
+}
\ No newline at end of file
@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"strings"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// Severity of a diagnostic. Only errors reject a record.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Result is the outcome of Process
+type Result struct {
+	Encoding          Encoding     `json:"encoding"` // as detected, or as given
+	Records           []Record     `json:"records"`  // every record read, in file order
+	Diagnostics       []Diagnostic `json:"diagnostics"`
+	Stats             Stats        `json:"stats"`
+	DuplicateStrategy string       `json:"duplicate_strategy"`
+	RuleSet           string       `json:"rule_set"` // empty when no rules applied
+}
+
+// Record is one row of the statement
+type Record struct {
+	Line            int    `json:"line"` // 1-based line in the file
+	EntryDate       string `json:"entry_date"`
+	EntryTime       string `json:"entry_time"`
+	ExitDate        string `json:"exit_date"`
+	ExitTime        string `json:"exit_time"`
+	EntryIC         string `json:"entry_ic"`
+	ExitIC          string `json:"exit_ic"`
+	RouteInfo       string `json:"route_info"`
+	ETCAmount       int    `json:"etc_amount"`
+	NormalAmount    int    `json:"normal_amount"`
+	DiscountApplied int    `json:"discount_applied"`
+	Mileage         int    `json:"mileage"`
+	VehicleClass    int    `json:"vehicle_class"`
+	VehicleNumber   string `json:"vehicle_number"`
+	CardNumber      string `json:"card_number"`
+	Notes           string `json:"notes"`
+	Valid           bool   `json:"valid"` // no error diagnostic refers to the record
+}
+
+// Diagnostic is a problem with a record, or with the whole input when
+// RecordIndex is -1
+type Diagnostic struct {
+	Line        int      `json:"line"`         // 1-based line in the file, 0 if unknown
+	RecordIndex int      `json:"record_index"` // index in Result.Records
+	Severity    Severity `json:"severity"`
+	Code        string   `json:"code"`  // e.g. INVALID_DATE, DUPLICATE
+	Field       string   `json:"field"` // e.g. exit_date
+	Rule        string   `json:"rule"`  // validation rule, if one reported it
+	Message     string   `json:"message"`
+}
+
+// Stats counts the records by outcome
+type Stats struct {
+	Records       int `json:"records"`
+	Accepted      int `json:"accepted"` // passed every check; saved when Options.Saver is set
+	Skipped       int `json:"skipped"`  // duplicates skipped by Options.SkipDuplicates
+	Failed        int `json:"failed"`
+	Updated       int `json:"updated"`        // saves that replaced a stored row
+	Corrected     int `json:"corrected"`      // re-issued rows with a corrected amount
+	StatusChanges int `json:"status_changes"` // re-issued rows whose notes/status changed
+	Warnings      int `json:"warnings"`
+}
+
+// newResult combines the parsed records with the service's response
+func newResult(enc Encoding, records []parser.ActualETCRecord, resp *pb.ProcessCSVDataResponse) *Result {
+	res := &Result{
+		Encoding:          enc,
+		DuplicateStrategy: resp.DuplicateStrategy,
+		RuleSet:           resp.RuleSet,
+		Stats: Stats{
+			Records:       int(resp.Stats.GetTotalRecords()),
+			Accepted:      int(resp.Stats.GetSavedRecords()),
+			Skipped:       int(resp.Stats.GetSkippedRecords()),
+			Failed:        int(resp.Stats.GetErrorRecords()),
+			Updated:       int(resp.Stats.GetUpdatedRecords()),
+			Corrected:     int(resp.Stats.GetCorrectedRecords()),
+			StatusChanges: int(resp.Stats.GetStatusChanges()),
+		},
+	}
+
+	rejected := map[int]bool{}
+	for _, e := range resp.RecordErrors {
+		d := Diagnostic{
+			Line:        int(e.LineNumber),
+			RecordIndex: int(e.RecordIndex),
+			Severity:    Severity(strings.ToLower(strings.TrimPrefix(e.Severity.String(), "SEVERITY_"))),
+			Code:        strings.TrimPrefix(e.Code.String(), "ERROR_CODE_"),
+			Field:       e.Field,
+			Rule:        e.Rule,
+			Message:     e.Message,
+		}
+		switch d.Severity {
+		case SeverityError:
+			rejected[d.RecordIndex] = true
+		case SeverityWarning:
+			res.Stats.Warnings++
+		}
+		res.Diagnostics = append(res.Diagnostics, d)
+	}
+
+	for i, r := range records {
+		res.Records = append(res.Records, Record{
+			Line:            r.LineNumber,
+			EntryDate:       r.EntryDate,
+			EntryTime:       r.EntryTime,
+			ExitDate:        r.ExitDate,
+			ExitTime:        r.ExitTime,
+			EntryIC:         r.EntryIC,
+			ExitIC:          r.ExitIC,
+			RouteInfo:       r.RouteInfo,
+			ETCAmount:       r.ETCAmount,
+			NormalAmount:    r.NormalAmount,
+			DiscountApplied: r.DiscountApplied,
+			Mileage:         r.Mileage,
+			VehicleClass:    r.VehicleClass,
+			VehicleNumber:   r.VehicleNumber,
+			CardNumber:      r.CardNumber,
+			Notes:           r.Notes,
+			Valid:           !rejected[i],
+		})
+	}
+	return res
+}
\ No newline at end of file
@@ -22,6 +22,195 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Stable error codes for record-level problems
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED           ErrorCode = 0
+	ErrorCode_ERROR_CODE_PARSE_FAILED          ErrorCode = 1 // the CSV could not be read
+	ErrorCode_ERROR_CODE_MISSING_FIELD         ErrorCode = 2 // a required field is empty
+	ErrorCode_ERROR_CODE_INVALID_DATE          ErrorCode = 3
+	ErrorCode_ERROR_CODE_INVALID_AMOUNT        ErrorCode = 4
+	ErrorCode_ERROR_CODE_INVALID_VEHICLE_CLASS ErrorCode = 5
+	ErrorCode_ERROR_CODE_VALIDATION_FAILED     ErrorCode = 6 // any other validation failure
+	ErrorCode_ERROR_CODE_CONVERSION_FAILED     ErrorCode = 7
+	ErrorCode_ERROR_CODE_SAVE_FAILED           ErrorCode = 8
+	ErrorCode_ERROR_CODE_DUPLICATE             ErrorCode = 9
+	ErrorCode_ERROR_CODE_CANCELLED             ErrorCode = 10
+	ErrorCode_ERROR_CODE_INVALID_REQUEST       ErrorCode = 11 // the request itself is invalid
+	ErrorCode_ERROR_CODE_CORRECTION            ErrorCode = 12 // a re-issued row corrects an earlier row's amount
+	ErrorCode_ERROR_CODE_STATUS_CHANGE         ErrorCode = 13 // a re-issued row changes an earlier row's notes/status
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:  "ERROR_CODE_UNSPECIFIED",
+		1:  "ERROR_CODE_PARSE_FAILED",
+		2:  "ERROR_CODE_MISSING_FIELD",
+		3:  "ERROR_CODE_INVALID_DATE",
+		4:  "ERROR_CODE_INVALID_AMOUNT",
+		5:  "ERROR_CODE_INVALID_VEHICLE_CLASS",
+		6:  "ERROR_CODE_VALIDATION_FAILED",
+		7:  "ERROR_CODE_CONVERSION_FAILED",
+		8:  "ERROR_CODE_SAVE_FAILED",
+		9:  "ERROR_CODE_DUPLICATE",
+		10: "ERROR_CODE_CANCELLED",
+		11: "ERROR_CODE_INVALID_REQUEST",
+		12: "ERROR_CODE_CORRECTION",
+		13: "ERROR_CODE_STATUS_CHANGE",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":           0,
+		"ERROR_CODE_PARSE_FAILED":          1,
+		"ERROR_CODE_MISSING_FIELD":         2,
+		"ERROR_CODE_INVALID_DATE":          3,
+		"ERROR_CODE_INVALID_AMOUNT":        4,
+		"ERROR_CODE_INVALID_VEHICLE_CLASS": 5,
+		"ERROR_CODE_VALIDATION_FAILED":     6,
+		"ERROR_CODE_CONVERSION_FAILED":     7,
+		"ERROR_CODE_SAVE_FAILED":           8,
+		"ERROR_CODE_DUPLICATE":             9,
+		"ERROR_CODE_CANCELLED":             10,
+		"ERROR_CODE_INVALID_REQUEST":       11,
+		"ERROR_CODE_CORRECTION":            12,
+		"ERROR_CODE_STATUS_CHANGE":         13,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[0]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{0}
+}
+
+// Character encoding of csv_bytes. AUTO detects it the same way as files are
+// read: a BOM, then UTF-16 NUL patterns, then UTF-8 validity, else Shift-JIS.
+type Encoding int32
+
+const (
+	Encoding_ENCODING_AUTO      Encoding = 0
+	Encoding_ENCODING_SHIFT_JIS Encoding = 1 // CP932, as exported by issuer portals
+	Encoding_ENCODING_UTF8      Encoding = 2
+	Encoding_ENCODING_UTF16     Encoding = 3 // byte order from the BOM, else detected
+)
+
+// Enum value maps for Encoding.
+var (
+	Encoding_name = map[int32]string{
+		0: "ENCODING_AUTO",
+		1: "ENCODING_SHIFT_JIS",
+		2: "ENCODING_UTF8",
+		3: "ENCODING_UTF16",
+	}
+	Encoding_value = map[string]int32{
+		"ENCODING_AUTO":      0,
+		"ENCODING_SHIFT_JIS": 1,
+		"ENCODING_UTF8":      2,
+		"ENCODING_UTF16":     3,
+	}
+)
+
+func (x Encoding) Enum() *Encoding {
+	p := new(Encoding)
+	*p = x
+	return p
+}
+
+func (x Encoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Encoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[1].Descriptor()
+}
+
+func (Encoding) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[1]
+}
+
+func (x Encoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Encoding.Descriptor instead.
+func (Encoding) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{1}
+}
+
+type Severity int32
+
+const (
+	Severity_SEVERITY_UNSPECIFIED Severity = 0
+	Severity_SEVERITY_INFO        Severity = 1
+	Severity_SEVERITY_WARNING     Severity = 2
+	Severity_SEVERITY_ERROR       Severity = 3
+)
+
+// Enum value maps for Severity.
+var (
+	Severity_name = map[int32]string{
+		0: "SEVERITY_UNSPECIFIED",
+		1: "SEVERITY_INFO",
+		2: "SEVERITY_WARNING",
+		3: "SEVERITY_ERROR",
+	}
+	Severity_value = map[string]int32{
+		"SEVERITY_UNSPECIFIED": 0,
+		"SEVERITY_INFO":        1,
+		"SEVERITY_WARNING":     2,
+		"SEVERITY_ERROR":       3,
+	}
+)
+
+func (x Severity) Enum() *Severity {
+	p := new(Severity)
+	*p = x
+	return p
+}
+
+func (x Severity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Severity) Descriptor() protoreflect.EnumDescriptor {
+	return file_src_proto_data_processor_proto_enumTypes[2].Descriptor()
+}
+
+func (Severity) Type() protoreflect.EnumType {
+	return &file_src_proto_data_processor_proto_enumTypes[2]
+}
+
+func (x Severity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Severity.Descriptor instead.
+func (Severity) EnumDescriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{2}
+}
+
 type ProcessCSVFileRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	CsvFilePath    string                 `protobuf:"bytes,1,opt,name=csv_file_path,json=csvFilePath,proto3" json:"csv_file_path,omitempty"`
@@ -83,13 +272,18 @@ func (x *ProcessCSVFileRequest) GetSkipDuplicates() bool {
 }
 
 type ProcessCSVFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Stats         *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
-	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Stats             *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors            []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`                     // legacy free-form messages, kept for compatibility
+	ImportId          string                 `protobuf:"bytes,5,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"` // identifies failed records in the dead-letter store
+	RecordErrors      []*RecordError         `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DuplicateStrategy string                 `protobuf:"bytes,7,opt,name=duplicate_strategy,json=duplicateStrategy,proto3" json:"duplicate_strategy,omitempty"` // duplicate-key strategy applied to this account
+	RuleSet           string                 `protobuf:"bytes,8,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`                               // validation rule set applied to this account
+	Entries           []*FileResult          `protobuf:"bytes,9,rep,name=entries,proto3" json:"entries,omitempty"`                                              // one per CSV entry when the file is a ZIP archive
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *ProcessCSVFileResponse) Reset() {
@@ -150,29 +344,67 @@ func (x *ProcessCSVFileResponse) GetErrors() []string {
 	return nil
 }
 
-type ProcessCSVDataRequest struct {
+func (x *ProcessCSVFileResponse) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *ProcessCSVFileResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ProcessCSVFileResponse) GetDuplicateStrategy() string {
+	if x != nil {
+		return x.DuplicateStrategy
+	}
+	return ""
+}
+
+func (x *ProcessCSVFileResponse) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+func (x *ProcessCSVFileResponse) GetEntries() []*FileResult {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type ProcessCSVFilesRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	CsvData        string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
-	AccountId      string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	SkipDuplicates bool                   `protobuf:"varint,3,opt,name=skip_duplicates,json=skipDuplicates,proto3" json:"skip_duplicates,omitempty"`
+	CsvFilePaths   []string               `protobuf:"bytes,1,rep,name=csv_file_paths,json=csvFilePaths,proto3" json:"csv_file_paths,omitempty"`
+	Directory      string                 `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"` // every *.csv file directly in this directory
+	Glob           string                 `protobuf:"bytes,3,opt,name=glob,proto3" json:"glob,omitempty"`           // e.g. /data/2025/*.csv
+	AccountId      string                 `protobuf:"bytes,4,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	SkipDuplicates bool                   `protobuf:"varint,5,opt,name=skip_duplicates,json=skipDuplicates,proto3" json:"skip_duplicates,omitempty"` // duplicates are detected across all files
+	Concurrency    int32                  `protobuf:"varint,6,opt,name=concurrency,proto3" json:"concurrency,omitempty"`                             // files processed at once; 0 uses the server default
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ProcessCSVDataRequest) Reset() {
-	*x = ProcessCSVDataRequest{}
+func (x *ProcessCSVFilesRequest) Reset() {
+	*x = ProcessCSVFilesRequest{}
 	mi := &file_src_proto_data_processor_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessCSVDataRequest) String() string {
+func (x *ProcessCSVFilesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessCSVDataRequest) ProtoMessage() {}
+func (*ProcessCSVFilesRequest) ProtoMessage() {}
 
-func (x *ProcessCSVDataRequest) ProtoReflect() protoreflect.Message {
+func (x *ProcessCSVFilesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_src_proto_data_processor_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -184,56 +416,79 @@ func (x *ProcessCSVDataRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessCSVDataRequest.ProtoReflect.Descriptor instead.
-func (*ProcessCSVDataRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ProcessCSVFilesRequest.ProtoReflect.Descriptor instead.
+func (*ProcessCSVFilesRequest) Descriptor() ([]byte, []int) {
 	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *ProcessCSVDataRequest) GetCsvData() string {
+func (x *ProcessCSVFilesRequest) GetCsvFilePaths() []string {
 	if x != nil {
-		return x.CsvData
+		return x.CsvFilePaths
+	}
+	return nil
+}
+
+func (x *ProcessCSVFilesRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
 	}
 	return ""
 }
 
-func (x *ProcessCSVDataRequest) GetAccountId() string {
+func (x *ProcessCSVFilesRequest) GetGlob() string {
+	if x != nil {
+		return x.Glob
+	}
+	return ""
+}
+
+func (x *ProcessCSVFilesRequest) GetAccountId() string {
 	if x != nil {
 		return x.AccountId
 	}
 	return ""
 }
 
-func (x *ProcessCSVDataRequest) GetSkipDuplicates() bool {
+func (x *ProcessCSVFilesRequest) GetSkipDuplicates() bool {
 	if x != nil {
 		return x.SkipDuplicates
 	}
 	return false
 }
 
-type ProcessCSVDataResponse struct {
+func (x *ProcessCSVFilesRequest) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
+	}
+	return 0
+}
+
+type FileResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Stats         *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
-	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	CsvFilePath   string                 `protobuf:"bytes,1,opt,name=csv_file_path,json=csvFilePath,proto3" json:"csv_file_path,omitempty"`
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	Stats         *ProcessingStats       `protobuf:"bytes,4,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors        []string               `protobuf:"bytes,5,rep,name=errors,proto3" json:"errors,omitempty"`
+	RecordErrors  []*RecordError         `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProcessCSVDataResponse) Reset() {
-	*x = ProcessCSVDataResponse{}
+func (x *FileResult) Reset() {
+	*x = FileResult{}
 	mi := &file_src_proto_data_processor_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessCSVDataResponse) String() string {
+func (x *FileResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessCSVDataResponse) ProtoMessage() {}
+func (*FileResult) ProtoMessage() {}
 
-func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
+func (x *FileResult) ProtoReflect() protoreflect.Message {
 	mi := &file_src_proto_data_processor_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -245,61 +500,81 @@ func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessCSVDataResponse.ProtoReflect.Descriptor instead.
-func (*ProcessCSVDataResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FileResult.ProtoReflect.Descriptor instead.
+func (*FileResult) Descriptor() ([]byte, []int) {
 	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *ProcessCSVDataResponse) GetSuccess() bool {
+func (x *FileResult) GetCsvFilePath() string {
+	if x != nil {
+		return x.CsvFilePath
+	}
+	return ""
+}
+
+func (x *FileResult) GetSuccess() bool {
 	if x != nil {
 		return x.Success
 	}
 	return false
 }
 
-func (x *ProcessCSVDataResponse) GetMessage() string {
+func (x *FileResult) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *ProcessCSVDataResponse) GetStats() *ProcessingStats {
+func (x *FileResult) GetStats() *ProcessingStats {
 	if x != nil {
 		return x.Stats
 	}
 	return nil
 }
 
-func (x *ProcessCSVDataResponse) GetErrors() []string {
+func (x *FileResult) GetErrors() []string {
 	if x != nil {
 		return x.Errors
 	}
 	return nil
 }
 
-type ValidateCSVDataRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	CsvData       string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
-	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *FileResult) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
 }
 
-func (x *ValidateCSVDataRequest) Reset() {
-	*x = ValidateCSVDataRequest{}
+type ProcessCSVFilesResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Totals            *ProcessingStats       `protobuf:"bytes,3,opt,name=totals,proto3" json:"totals,omitempty"`                               // sum over all files
+	Files             []*FileResult          `protobuf:"bytes,4,rep,name=files,proto3" json:"files,omitempty"`                                 // in the order the files were resolved
+	ImportId          string                 `protobuf:"bytes,5,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"`           // shared by every file of the batch
+	FailedFiles       int32                  `protobuf:"varint,6,opt,name=failed_files,json=failedFiles,proto3" json:"failed_files,omitempty"` // files that could not be read or saved nothing
+	DuplicateStrategy string                 `protobuf:"bytes,7,opt,name=duplicate_strategy,json=duplicateStrategy,proto3" json:"duplicate_strategy,omitempty"`
+	RuleSet           string                 `protobuf:"bytes,8,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ProcessCSVFilesResponse) Reset() {
+	*x = ProcessCSVFilesResponse{}
 	mi := &file_src_proto_data_processor_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ValidateCSVDataRequest) String() string {
+func (x *ProcessCSVFilesResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ValidateCSVDataRequest) ProtoMessage() {}
+func (*ProcessCSVFilesResponse) ProtoMessage() {}
 
-func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
+func (x *ProcessCSVFilesResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_src_proto_data_processor_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -311,49 +586,92 @@ func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ValidateCSVDataRequest.ProtoReflect.Descriptor instead.
-func (*ValidateCSVDataRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ProcessCSVFilesResponse.ProtoReflect.Descriptor instead.
+func (*ProcessCSVFilesResponse) Descriptor() ([]byte, []int) {
 	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ValidateCSVDataRequest) GetCsvData() string {
+func (x *ProcessCSVFilesResponse) GetSuccess() bool {
 	if x != nil {
-		return x.CsvData
+		return x.Success
+	}
+	return false
+}
+
+func (x *ProcessCSVFilesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-func (x *ValidateCSVDataRequest) GetAccountId() string {
+func (x *ProcessCSVFilesResponse) GetTotals() *ProcessingStats {
 	if x != nil {
-		return x.AccountId
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *ProcessCSVFilesResponse) GetFiles() []*FileResult {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *ProcessCSVFilesResponse) GetImportId() string {
+	if x != nil {
+		return x.ImportId
 	}
 	return ""
 }
 
-type ValidateCSVDataResponse struct {
+func (x *ProcessCSVFilesResponse) GetFailedFiles() int32 {
+	if x != nil {
+		return x.FailedFiles
+	}
+	return 0
+}
+
+func (x *ProcessCSVFilesResponse) GetDuplicateStrategy() string {
+	if x != nil {
+		return x.DuplicateStrategy
+	}
+	return ""
+}
+
+func (x *ProcessCSVFilesResponse) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+type ProcessCSVDataRequest struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
-	IsValid        bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
-	Errors         []*ValidationError     `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
-	DuplicateCount int32                  `protobuf:"varint,3,opt,name=duplicate_count,json=duplicateCount,proto3" json:"duplicate_count,omitempty"`
-	TotalRecords   int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	CsvData        string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
+	AccountId      string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	SkipDuplicates bool                   `protobuf:"varint,3,opt,name=skip_duplicates,json=skipDuplicates,proto3" json:"skip_duplicates,omitempty"`
+	CsvBytes       []byte                 `protobuf:"bytes,4,opt,name=csv_bytes,json=csvBytes,proto3" json:"csv_bytes,omitempty"`                    // raw file bytes, instead of csv_data
+	Encoding       Encoding               `protobuf:"varint,5,opt,name=encoding,proto3,enum=etcdataprocessor.v1.Encoding" json:"encoding,omitempty"` // encoding of csv_bytes
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
-func (x *ValidateCSVDataResponse) Reset() {
-	*x = ValidateCSVDataResponse{}
+func (x *ProcessCSVDataRequest) Reset() {
+	*x = ProcessCSVDataRequest{}
 	mi := &file_src_proto_data_processor_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ValidateCSVDataResponse) String() string {
+func (x *ProcessCSVDataRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ValidateCSVDataResponse) ProtoMessage() {}
+func (*ProcessCSVDataRequest) ProtoMessage() {}
 
-func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
+func (x *ProcessCSVDataRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_src_proto_data_processor_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -365,100 +683,1128 @@ func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ValidateCSVDataResponse.ProtoReflect.Descriptor instead.
-func (*ValidateCSVDataResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ProcessCSVDataRequest.ProtoReflect.Descriptor instead.
+func (*ProcessCSVDataRequest) Descriptor() ([]byte, []int) {
 	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ValidateCSVDataResponse) GetIsValid() bool {
+func (x *ProcessCSVDataRequest) GetCsvData() string {
 	if x != nil {
-		return x.IsValid
+		return x.CsvData
 	}
-	return false
+	return ""
 }
 
-func (x *ValidateCSVDataResponse) GetErrors() []*ValidationError {
+func (x *ProcessCSVDataRequest) GetAccountId() string {
 	if x != nil {
-		return x.Errors
+		return x.AccountId
 	}
-	return nil
+	return ""
 }
 
-func (x *ValidateCSVDataResponse) GetDuplicateCount() int32 {
+func (x *ProcessCSVDataRequest) GetSkipDuplicates() bool {
 	if x != nil {
-		return x.DuplicateCount
+		return x.SkipDuplicates
 	}
-	return 0
+	return false
 }
 
-func (x *ValidateCSVDataResponse) GetTotalRecords() int32 {
+func (x *ProcessCSVDataRequest) GetCsvBytes() []byte {
 	if x != nil {
-		return x.TotalRecords
+		return x.CsvBytes
 	}
-	return 0
+	return nil
 }
 
-type HealthCheckRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ProcessCSVDataRequest) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_AUTO
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
+type ProcessCSVDataResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Success           bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Stats             *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors            []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`                     // legacy free-form messages, kept for compatibility
+	ImportId          string                 `protobuf:"bytes,5,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"` // identifies failed records in the dead-letter store
+	RecordErrors      []*RecordError         `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DuplicateStrategy string                 `protobuf:"bytes,7,opt,name=duplicate_strategy,json=duplicateStrategy,proto3" json:"duplicate_strategy,omitempty"` // duplicate-key strategy applied to this account
+	RuleSet           string                 `protobuf:"bytes,8,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`                               // validation rule set applied to this account
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ProcessCSVDataResponse) Reset() {
+	*x = ProcessCSVDataResponse{}
 	mi := &file_src_proto_data_processor_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *ProcessCSVDataResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*ProcessCSVDataResponse) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+func (x *ProcessCSVDataResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_src_proto_data_processor_proto_msgTypes[6]
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessCSVDataResponse.ProtoReflect.Descriptor instead.
+func (*ProcessCSVDataResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProcessCSVDataResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ProcessCSVDataResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataResponse) GetStats() *ProcessingStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ProcessCSVDataResponse) GetDuplicateStrategy() string {
+	if x != nil {
+		return x.DuplicateStrategy
+	}
+	return ""
+}
+
+func (x *ProcessCSVDataResponse) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+type ValidateCSVDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CsvData       string                 `protobuf:"bytes,1,opt,name=csv_data,json=csvData,proto3" json:"csv_data,omitempty"`
+	AccountId     string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	CsvBytes      []byte                 `protobuf:"bytes,3,opt,name=csv_bytes,json=csvBytes,proto3" json:"csv_bytes,omitempty"`                    // raw file bytes, instead of csv_data
+	Encoding      Encoding               `protobuf:"varint,4,opt,name=encoding,proto3,enum=etcdataprocessor.v1.Encoding" json:"encoding,omitempty"` // encoding of csv_bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateCSVDataRequest) Reset() {
+	*x = ValidateCSVDataRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSVDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSVDataRequest) ProtoMessage() {}
+
+func (x *ValidateCSVDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSVDataRequest.ProtoReflect.Descriptor instead.
+func (*ValidateCSVDataRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ValidateCSVDataRequest) GetCsvData() string {
+	if x != nil {
+		return x.CsvData
+	}
+	return ""
+}
+
+func (x *ValidateCSVDataRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ValidateCSVDataRequest) GetCsvBytes() []byte {
+	if x != nil {
+		return x.CsvBytes
+	}
+	return nil
+}
+
+func (x *ValidateCSVDataRequest) GetEncoding() Encoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return Encoding_ENCODING_AUTO
+}
+
+type ValidateCSVDataResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	IsValid           bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
+	Errors            []*ValidationError     `protobuf:"bytes,2,rep,name=errors,proto3" json:"errors,omitempty"`
+	DuplicateCount    int32                  `protobuf:"varint,3,opt,name=duplicate_count,json=duplicateCount,proto3" json:"duplicate_count,omitempty"`
+	TotalRecords      int32                  `protobuf:"varint,4,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	RecordErrors      []*RecordError         `protobuf:"bytes,5,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	DuplicateStrategy string                 `protobuf:"bytes,6,opt,name=duplicate_strategy,json=duplicateStrategy,proto3" json:"duplicate_strategy,omitempty"`    // duplicate-key strategy applied to this account
+	CorrectionCount   int32                  `protobuf:"varint,7,opt,name=correction_count,json=correctionCount,proto3" json:"correction_count,omitempty"`         // re-issued rows with a corrected amount
+	StatusChangeCount int32                  `protobuf:"varint,8,opt,name=status_change_count,json=statusChangeCount,proto3" json:"status_change_count,omitempty"` // re-issued rows whose notes/status changed
+	RuleSet           string                 `protobuf:"bytes,9,opt,name=rule_set,json=ruleSet,proto3" json:"rule_set,omitempty"`                                  // validation rule set applied to this account
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ValidateCSVDataResponse) Reset() {
+	*x = ValidateCSVDataResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateCSVDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateCSVDataResponse) ProtoMessage() {}
+
+func (x *ValidateCSVDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateCSVDataResponse.ProtoReflect.Descriptor instead.
+func (*ValidateCSVDataResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ValidateCSVDataResponse) GetIsValid() bool {
+	if x != nil {
+		return x.IsValid
+	}
+	return false
+}
+
+func (x *ValidateCSVDataResponse) GetErrors() []*ValidationError {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ValidateCSVDataResponse) GetDuplicateCount() int32 {
+	if x != nil {
+		return x.DuplicateCount
+	}
+	return 0
+}
+
+func (x *ValidateCSVDataResponse) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+func (x *ValidateCSVDataResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+func (x *ValidateCSVDataResponse) GetDuplicateStrategy() string {
+	if x != nil {
+		return x.DuplicateStrategy
+	}
+	return ""
+}
+
+func (x *ValidateCSVDataResponse) GetCorrectionCount() int32 {
+	if x != nil {
+		return x.CorrectionCount
+	}
+	return 0
+}
+
+func (x *ValidateCSVDataResponse) GetStatusChangeCount() int32 {
+	if x != nil {
+		return x.StatusChangeCount
+	}
+	return 0
+}
+
+func (x *ValidateCSVDataResponse) GetRuleSet() string {
+	if x != nil {
+		return x.RuleSet
+	}
+	return ""
+}
+
+type HealthCheckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{9}
+}
+
+type HealthCheckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Details       map[string]string      `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HealthCheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetDetails() map[string]string {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type ProcessingStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	TotalRecords     int32                  `protobuf:"varint,1,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
+	SavedRecords     int32                  `protobuf:"varint,2,opt,name=saved_records,json=savedRecords,proto3" json:"saved_records,omitempty"`
+	SkippedRecords   int32                  `protobuf:"varint,3,opt,name=skipped_records,json=skippedRecords,proto3" json:"skipped_records,omitempty"`
+	ErrorRecords     int32                  `protobuf:"varint,4,opt,name=error_records,json=errorRecords,proto3" json:"error_records,omitempty"`
+	RetriedRecords   int32                  `protobuf:"varint,5,opt,name=retried_records,json=retriedRecords,proto3" json:"retried_records,omitempty"`       // records that needed more than one save attempt
+	RetryAttempts    int32                  `protobuf:"varint,6,opt,name=retry_attempts,json=retryAttempts,proto3" json:"retry_attempts,omitempty"`          // total extra save attempts across all records
+	TransientErrors  int32                  `protobuf:"varint,7,opt,name=transient_errors,json=transientErrors,proto3" json:"transient_errors,omitempty"`    // records that failed with a retryable error
+	PermanentErrors  int32                  `protobuf:"varint,8,opt,name=permanent_errors,json=permanentErrors,proto3" json:"permanent_errors,omitempty"`    // records that failed with a non-retryable error
+	CorrectedRecords int32                  `protobuf:"varint,9,opt,name=corrected_records,json=correctedRecords,proto3" json:"corrected_records,omitempty"` // re-issued rows with a corrected amount
+	StatusChanges    int32                  `protobuf:"varint,10,opt,name=status_changes,json=statusChanges,proto3" json:"status_changes,omitempty"`         // re-issued rows whose notes/status changed
+	UpdatedRecords   int32                  `protobuf:"varint,11,opt,name=updated_records,json=updatedRecords,proto3" json:"updated_records,omitempty"`      // saves that replaced an already stored row
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ProcessingStats) Reset() {
+	*x = ProcessingStats{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessingStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessingStats) ProtoMessage() {}
+
+func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessingStats.ProtoReflect.Descriptor instead.
+func (*ProcessingStats) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ProcessingStats) GetTotalRecords() int32 {
+	if x != nil {
+		return x.TotalRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetSavedRecords() int32 {
+	if x != nil {
+		return x.SavedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetSkippedRecords() int32 {
+	if x != nil {
+		return x.SkippedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetErrorRecords() int32 {
+	if x != nil {
+		return x.ErrorRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetRetriedRecords() int32 {
+	if x != nil {
+		return x.RetriedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetRetryAttempts() int32 {
+	if x != nil {
+		return x.RetryAttempts
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetTransientErrors() int32 {
+	if x != nil {
+		return x.TransientErrors
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetPermanentErrors() int32 {
+	if x != nil {
+		return x.PermanentErrors
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetCorrectedRecords() int32 {
+	if x != nil {
+		return x.CorrectedRecords
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetStatusChanges() int32 {
+	if x != nil {
+		return x.StatusChanges
+	}
+	return 0
+}
+
+func (x *ProcessingStats) GetUpdatedRecords() int32 {
+	if x != nil {
+		return x.UpdatedRecords
+	}
+	return 0
+}
+
+type RecordError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=etcdataprocessor.v1.ErrorCode" json:"code,omitempty"`
+	LineNumber    int32                  `protobuf:"varint,2,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`    // 1-based line in the CSV, 0 if unknown
+	RecordIndex   int32                  `protobuf:"varint,3,opt,name=record_index,json=recordIndex,proto3" json:"record_index,omitempty"` // 0-based record index, -1 if not record-specific
+	Field         string                 `protobuf:"bytes,4,opt,name=field,proto3" json:"field,omitempty"`
+	Severity      Severity               `protobuf:"varint,5,opt,name=severity,proto3,enum=etcdataprocessor.v1.Severity" json:"severity,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Retryable     bool                   `protobuf:"varint,7,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	Rule          string                 `protobuf:"bytes,8,opt,name=rule,proto3" json:"rule,omitempty"` // name of the validation rule that reported the error, if any
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordError) Reset() {
+	*x = RecordError{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordError) ProtoMessage() {}
+
+func (x *RecordError) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordError.ProtoReflect.Descriptor instead.
+func (*RecordError) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RecordError) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+func (x *RecordError) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *RecordError) GetRecordIndex() int32 {
+	if x != nil {
+		return x.RecordIndex
+	}
+	return 0
+}
+
+func (x *RecordError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *RecordError) GetSeverity() Severity {
+	if x != nil {
+		return x.Severity
+	}
+	return Severity_SEVERITY_UNSPECIFIED
+}
+
+func (x *RecordError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RecordError) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *RecordError) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+type ValidationError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LineNumber    int32                  `protobuf:"varint,1,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	RecordData    string                 `protobuf:"bytes,4,opt,name=record_data,json=recordData,proto3" json:"record_data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidationError) Reset() {
+	*x = ValidationError{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidationError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidationError) ProtoMessage() {}
+
+func (x *ValidationError) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidationError.ProtoReflect.Descriptor instead.
+func (*ValidationError) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ValidationError) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
+}
+
+func (x *ValidationError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *ValidationError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ValidationError) GetRecordData() string {
+	if x != nil {
+		return x.RecordData
+	}
+	return ""
+}
+
+type ListFailedRecordsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	ImportId      string                 `protobuf:"bytes,2,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"` // optional: only failures from this import
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                      // optional: maximum number of records (0 = all)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFailedRecordsRequest) Reset() {
+	*x = ListFailedRecordsRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFailedRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFailedRecordsRequest) ProtoMessage() {}
+
+func (x *ListFailedRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFailedRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ListFailedRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListFailedRecordsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ListFailedRecordsRequest) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *ListFailedRecordsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListFailedRecordsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*FailedRecord        `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFailedRecordsResponse) Reset() {
+	*x = ListFailedRecordsResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFailedRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFailedRecordsResponse) ProtoMessage() {}
+
+func (x *ListFailedRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFailedRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ListFailedRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListFailedRecordsResponse) GetRecords() []*FailedRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ListFailedRecordsResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type ReprocessFailedRecordsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	ImportId      string                 `protobuf:"bytes,2,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"` // optional: only failures from this import
+	Ids           []string               `protobuf:"bytes,3,rep,name=ids,proto3" json:"ids,omitempty"`                           // optional: only these failed records
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReprocessFailedRecordsRequest) Reset() {
+	*x = ReprocessFailedRecordsRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReprocessFailedRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReprocessFailedRecordsRequest) ProtoMessage() {}
+
+func (x *ReprocessFailedRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReprocessFailedRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ReprocessFailedRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReprocessFailedRecordsRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *ReprocessFailedRecordsRequest) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *ReprocessFailedRecordsRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type ReprocessFailedRecordsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Stats          *ProcessingStats       `protobuf:"bytes,3,opt,name=stats,proto3" json:"stats,omitempty"`
+	Errors         []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	RemainingCount int32                  `protobuf:"varint,5,opt,name=remaining_count,json=remainingCount,proto3" json:"remaining_count,omitempty"` // failed records still in the store after the replay
+	RecordErrors   []*RecordError         `protobuf:"bytes,6,rep,name=record_errors,json=recordErrors,proto3" json:"record_errors,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReprocessFailedRecordsResponse) Reset() {
+	*x = ReprocessFailedRecordsResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReprocessFailedRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReprocessFailedRecordsResponse) ProtoMessage() {}
+
+func (x *ReprocessFailedRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReprocessFailedRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ReprocessFailedRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ReprocessFailedRecordsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ReprocessFailedRecordsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ReprocessFailedRecordsResponse) GetStats() *ProcessingStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+func (x *ReprocessFailedRecordsResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *ReprocessFailedRecordsResponse) GetRemainingCount() int32 {
+	if x != nil {
+		return x.RemainingCount
+	}
+	return 0
+}
+
+func (x *ReprocessFailedRecordsResponse) GetRecordErrors() []*RecordError {
+	if x != nil {
+		return x.RecordErrors
+	}
+	return nil
+}
+
+type FailedRecord struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ImportId          string                 `protobuf:"bytes,2,opt,name=import_id,json=importId,proto3" json:"import_id,omitempty"`
+	AccountId         string                 `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	LineNumber        int32                  `protobuf:"varint,4,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
+	RawRow            string                 `protobuf:"bytes,5,opt,name=raw_row,json=rawRow,proto3" json:"raw_row,omitempty"`
+	Record            *ETCRecordData         `protobuf:"bytes,6,opt,name=record,proto3" json:"record,omitempty"`
+	Stage             string                 `protobuf:"bytes,7,opt,name=stage,proto3" json:"stage,omitempty"` // conversion or save
+	Error             string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorClass        string                 `protobuf:"bytes,9,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"` // transient or permanent
+	ReprocessAttempts int32                  `protobuf:"varint,10,opt,name=reprocess_attempts,json=reprocessAttempts,proto3" json:"reprocess_attempts,omitempty"`
+	CreatedAt         int64                  `protobuf:"varint,11,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         int64                  `protobuf:"varint,12,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *FailedRecord) Reset() {
+	*x = FailedRecord{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailedRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedRecord) ProtoMessage() {}
+
+func (x *FailedRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedRecord.ProtoReflect.Descriptor instead.
+func (*FailedRecord) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *FailedRecord) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetImportId() string {
+	if x != nil {
+		return x.ImportId
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{6}
+func (x *FailedRecord) GetLineNumber() int32 {
+	if x != nil {
+		return x.LineNumber
+	}
+	return 0
 }
 
-type HealthCheckResponse struct {
+func (x *FailedRecord) GetRawRow() string {
+	if x != nil {
+		return x.RawRow
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetRecord() *ETCRecordData {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *FailedRecord) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *FailedRecord) GetReprocessAttempts() int32 {
+	if x != nil {
+		return x.ReprocessAttempts
+	}
+	return 0
+}
+
+func (x *FailedRecord) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *FailedRecord) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type GetQuotaUsageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	Details       map[string]string      `protobuf:"bytes,4,rep,name=details,proto3" json:"details,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"` // optional: only this account (required for keys limited to some accounts)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+func (x *GetQuotaUsageRequest) Reset() {
+	*x = GetQuotaUsageRequest{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *GetQuotaUsageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*GetQuotaUsageRequest) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[7]
+func (x *GetQuotaUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -469,64 +1815,102 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use GetQuotaUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageRequest) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{19}
 }
 
-func (x *HealthCheckResponse) GetStatus() string {
+func (x *GetQuotaUsageRequest) GetAccountId() string {
 	if x != nil {
-		return x.Status
+		return x.AccountId
 	}
 	return ""
 }
 
-func (x *HealthCheckResponse) GetVersion() string {
-	if x != nil {
-		return x.Version
-	}
-	return ""
+type GetQuotaUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Global        *QuotaUsage            `protobuf:"bytes,1,opt,name=global,proto3" json:"global,omitempty"` // all accounts together
+	Accounts      []*QuotaUsage          `protobuf:"bytes,2,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) GetTimestamp() int64 {
+func (x *GetQuotaUsageResponse) Reset() {
+	*x = GetQuotaUsageResponse{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotaUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotaUsageResponse) ProtoMessage() {}
+
+func (x *GetQuotaUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[20]
 	if x != nil {
-		return x.Timestamp
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-func (x *HealthCheckResponse) GetDetails() map[string]string {
+// Deprecated: Use GetQuotaUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotaUsageResponse) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetQuotaUsageResponse) GetGlobal() *QuotaUsage {
 	if x != nil {
-		return x.Details
+		return x.Global
 	}
 	return nil
 }
 
-type ProcessingStats struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	TotalRecords   int32                  `protobuf:"varint,1,opt,name=total_records,json=totalRecords,proto3" json:"total_records,omitempty"`
-	SavedRecords   int32                  `protobuf:"varint,2,opt,name=saved_records,json=savedRecords,proto3" json:"saved_records,omitempty"`
-	SkippedRecords int32                  `protobuf:"varint,3,opt,name=skipped_records,json=skippedRecords,proto3" json:"skipped_records,omitempty"`
-	ErrorRecords   int32                  `protobuf:"varint,4,opt,name=error_records,json=errorRecords,proto3" json:"error_records,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *GetQuotaUsageResponse) GetAccounts() []*QuotaUsage {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
 }
 
-func (x *ProcessingStats) Reset() {
-	*x = ProcessingStats{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+// Limits of 0 are unlimited
+type QuotaUsage struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	AccountId              string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	RequestsPerSecondLimit float64                `protobuf:"fixed64,2,opt,name=requests_per_second_limit,json=requestsPerSecondLimit,proto3" json:"requests_per_second_limit,omitempty"`
+	BurstLimit             int32                  `protobuf:"varint,3,opt,name=burst_limit,json=burstLimit,proto3" json:"burst_limit,omitempty"`
+	ConcurrentImports      int32                  `protobuf:"varint,4,opt,name=concurrent_imports,json=concurrentImports,proto3" json:"concurrent_imports,omitempty"`
+	ConcurrentImportsLimit int32                  `protobuf:"varint,5,opt,name=concurrent_imports_limit,json=concurrentImportsLimit,proto3" json:"concurrent_imports_limit,omitempty"`
+	RecordsToday           int64                  `protobuf:"varint,6,opt,name=records_today,json=recordsToday,proto3" json:"records_today,omitempty"`
+	RecordsPerDayLimit     int64                  `protobuf:"varint,7,opt,name=records_per_day_limit,json=recordsPerDayLimit,proto3" json:"records_per_day_limit,omitempty"`
+	Day                    string                 `protobuf:"bytes,8,opt,name=day,proto3" json:"day,omitempty"`                                                       // the day records_today counts, YYYY-MM-DD
+	ThrottledRequests      int64                  `protobuf:"varint,9,opt,name=throttled_requests,json=throttledRequests,proto3" json:"throttled_requests,omitempty"` // requests refused by the rate limit
+	RejectedImports        int64                  `protobuf:"varint,10,opt,name=rejected_imports,json=rejectedImports,proto3" json:"rejected_imports,omitempty"`      // imports refused by the concurrency limit or the daily quota
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProcessingStats) String() string {
+func (x *QuotaUsage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProcessingStats) ProtoMessage() {}
+func (*QuotaUsage) ProtoMessage() {}
 
-func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[8]
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -537,64 +1921,117 @@ func (x *ProcessingStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProcessingStats.ProtoReflect.Descriptor instead.
-func (*ProcessingStats) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *ProcessingStats) GetTotalRecords() int32 {
+func (x *QuotaUsage) GetAccountId() string {
 	if x != nil {
-		return x.TotalRecords
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetRequestsPerSecondLimit() float64 {
+	if x != nil {
+		return x.RequestsPerSecondLimit
 	}
 	return 0
 }
 
-func (x *ProcessingStats) GetSavedRecords() int32 {
+func (x *QuotaUsage) GetBurstLimit() int32 {
 	if x != nil {
-		return x.SavedRecords
+		return x.BurstLimit
 	}
 	return 0
 }
 
-func (x *ProcessingStats) GetSkippedRecords() int32 {
+func (x *QuotaUsage) GetConcurrentImports() int32 {
 	if x != nil {
-		return x.SkippedRecords
+		return x.ConcurrentImports
 	}
 	return 0
 }
 
-func (x *ProcessingStats) GetErrorRecords() int32 {
+func (x *QuotaUsage) GetConcurrentImportsLimit() int32 {
 	if x != nil {
-		return x.ErrorRecords
+		return x.ConcurrentImportsLimit
 	}
 	return 0
 }
 
-type ValidationError struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	LineNumber    int32                  `protobuf:"varint,1,opt,name=line_number,json=lineNumber,proto3" json:"line_number,omitempty"`
-	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	RecordData    string                 `protobuf:"bytes,4,opt,name=record_data,json=recordData,proto3" json:"record_data,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *QuotaUsage) GetRecordsToday() int64 {
+	if x != nil {
+		return x.RecordsToday
+	}
+	return 0
 }
 
-func (x *ValidationError) Reset() {
-	*x = ValidationError{}
-	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+func (x *QuotaUsage) GetRecordsPerDayLimit() int64 {
+	if x != nil {
+		return x.RecordsPerDayLimit
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *QuotaUsage) GetThrottledRequests() int64 {
+	if x != nil {
+		return x.ThrottledRequests
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetRejectedImports() int64 {
+	if x != nil {
+		return x.RejectedImports
+	}
+	return 0
+}
+
+type ETCRecordData struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EntryDate       string                 `protobuf:"bytes,1,opt,name=entry_date,json=entryDate,proto3" json:"entry_date,omitempty"`
+	EntryTime       string                 `protobuf:"bytes,2,opt,name=entry_time,json=entryTime,proto3" json:"entry_time,omitempty"`
+	ExitDate        string                 `protobuf:"bytes,3,opt,name=exit_date,json=exitDate,proto3" json:"exit_date,omitempty"`
+	ExitTime        string                 `protobuf:"bytes,4,opt,name=exit_time,json=exitTime,proto3" json:"exit_time,omitempty"`
+	EntryIc         string                 `protobuf:"bytes,5,opt,name=entry_ic,json=entryIc,proto3" json:"entry_ic,omitempty"`
+	ExitIc          string                 `protobuf:"bytes,6,opt,name=exit_ic,json=exitIc,proto3" json:"exit_ic,omitempty"`
+	RouteInfo       string                 `protobuf:"bytes,7,opt,name=route_info,json=routeInfo,proto3" json:"route_info,omitempty"`
+	EtcAmount       int32                  `protobuf:"varint,8,opt,name=etc_amount,json=etcAmount,proto3" json:"etc_amount,omitempty"`
+	NormalAmount    int32                  `protobuf:"varint,9,opt,name=normal_amount,json=normalAmount,proto3" json:"normal_amount,omitempty"`
+	DiscountApplied int32                  `protobuf:"varint,10,opt,name=discount_applied,json=discountApplied,proto3" json:"discount_applied,omitempty"`
+	Mileage         int32                  `protobuf:"varint,11,opt,name=mileage,proto3" json:"mileage,omitempty"`
+	VehicleClass    int32                  `protobuf:"varint,12,opt,name=vehicle_class,json=vehicleClass,proto3" json:"vehicle_class,omitempty"`
+	VehicleNumber   string                 `protobuf:"bytes,13,opt,name=vehicle_number,json=vehicleNumber,proto3" json:"vehicle_number,omitempty"`
+	CardNumber      string                 `protobuf:"bytes,14,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Notes           string                 `protobuf:"bytes,15,opt,name=notes,proto3" json:"notes,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ETCRecordData) Reset() {
+	*x = ETCRecordData{}
+	mi := &file_src_proto_data_processor_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ValidationError) String() string {
+func (x *ETCRecordData) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ValidationError) ProtoMessage() {}
+func (*ETCRecordData) ProtoMessage() {}
 
-func (x *ValidationError) ProtoReflect() protoreflect.Message {
-	mi := &file_src_proto_data_processor_proto_msgTypes[9]
+func (x *ETCRecordData) ProtoReflect() protoreflect.Message {
+	mi := &file_src_proto_data_processor_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -605,35 +2042,112 @@ func (x *ValidationError) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ValidationError.ProtoReflect.Descriptor instead.
-func (*ValidationError) Descriptor() ([]byte, []int) {
-	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ETCRecordData.ProtoReflect.Descriptor instead.
+func (*ETCRecordData) Descriptor() ([]byte, []int) {
+	return file_src_proto_data_processor_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *ValidationError) GetLineNumber() int32 {
+func (x *ETCRecordData) GetEntryDate() string {
 	if x != nil {
-		return x.LineNumber
+		return x.EntryDate
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetEntryTime() string {
+	if x != nil {
+		return x.EntryTime
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetExitDate() string {
+	if x != nil {
+		return x.ExitDate
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetExitTime() string {
+	if x != nil {
+		return x.ExitTime
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetEntryIc() string {
+	if x != nil {
+		return x.EntryIc
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetExitIc() string {
+	if x != nil {
+		return x.ExitIc
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetRouteInfo() string {
+	if x != nil {
+		return x.RouteInfo
+	}
+	return ""
+}
+
+func (x *ETCRecordData) GetEtcAmount() int32 {
+	if x != nil {
+		return x.EtcAmount
 	}
 	return 0
 }
 
-func (x *ValidationError) GetField() string {
+func (x *ETCRecordData) GetNormalAmount() int32 {
 	if x != nil {
-		return x.Field
+		return x.NormalAmount
+	}
+	return 0
+}
+
+func (x *ETCRecordData) GetDiscountApplied() int32 {
+	if x != nil {
+		return x.DiscountApplied
+	}
+	return 0
+}
+
+func (x *ETCRecordData) GetMileage() int32 {
+	if x != nil {
+		return x.Mileage
+	}
+	return 0
+}
+
+func (x *ETCRecordData) GetVehicleClass() int32 {
+	if x != nil {
+		return x.VehicleClass
+	}
+	return 0
+}
+
+func (x *ETCRecordData) GetVehicleNumber() string {
+	if x != nil {
+		return x.VehicleNumber
 	}
 	return ""
 }
 
-func (x *ValidationError) GetMessage() string {
+func (x *ETCRecordData) GetCardNumber() string {
 	if x != nil {
-		return x.Message
+		return x.CardNumber
 	}
 	return ""
 }
 
-func (x *ValidationError) GetRecordData() string {
+func (x *ETCRecordData) GetNotes() string {
 	if x != nil {
-		return x.RecordData
+		return x.Notes
 	}
 	return ""
 }
@@ -647,31 +2161,74 @@ const file_src_proto_data_processor_proto_rawDesc = "" +
 	"\rcsv_file_path\x18\x01 \x01(\tR\vcsvFilePath\x12\x1d\n" +
 	"\n" +
 	"account_id\x18\x02 \x01(\tR\taccountId\x12'\n" +
-	"\x0fskip_duplicates\x18\x03 \x01(\bR\x0eskipDuplicates\"\xa0\x01\n" +
+	"\x0fskip_duplicates\x18\x03 \x01(\bR\x0eskipDuplicates\"\x89\x03\n" +
 	"\x16ProcessCSVFileResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
 	"\x05stats\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
-	"\x06errors\x18\x04 \x03(\tR\x06errors\"z\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\x12\x1b\n" +
+	"\timport_id\x18\x05 \x01(\tR\bimportId\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12-\n" +
+	"\x12duplicate_strategy\x18\a \x01(\tR\x11duplicateStrategy\x12\x19\n" +
+	"\brule_set\x18\b \x01(\tR\aruleSet\x129\n" +
+	"\aentries\x18\t \x03(\v2\x1f.etcdataprocessor.v1.FileResultR\aentries\"\xda\x01\n" +
+	"\x16ProcessCSVFilesRequest\x12$\n" +
+	"\x0ecsv_file_paths\x18\x01 \x03(\tR\fcsvFilePaths\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\x12\x12\n" +
+	"\x04glob\x18\x03 \x01(\tR\x04glob\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x04 \x01(\tR\taccountId\x12'\n" +
+	"\x0fskip_duplicates\x18\x05 \x01(\bR\x0eskipDuplicates\x12 \n" +
+	"\vconcurrency\x18\x06 \x01(\x05R\vconcurrency\"\xff\x01\n" +
+	"\n" +
+	"FileResult\x12\"\n" +
+	"\rcsv_file_path\x18\x01 \x01(\tR\vcsvFilePath\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12:\n" +
+	"\x05stats\x18\x04 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
+	"\x06errors\x18\x05 \x03(\tR\x06errors\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\"\xcc\x02\n" +
+	"\x17ProcessCSVFilesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12<\n" +
+	"\x06totals\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x06totals\x125\n" +
+	"\x05files\x18\x04 \x03(\v2\x1f.etcdataprocessor.v1.FileResultR\x05files\x12\x1b\n" +
+	"\timport_id\x18\x05 \x01(\tR\bimportId\x12!\n" +
+	"\ffailed_files\x18\x06 \x01(\x05R\vfailedFiles\x12-\n" +
+	"\x12duplicate_strategy\x18\a \x01(\tR\x11duplicateStrategy\x12\x19\n" +
+	"\brule_set\x18\b \x01(\tR\aruleSet\"\xd2\x01\n" +
 	"\x15ProcessCSVDataRequest\x12\x19\n" +
 	"\bcsv_data\x18\x01 \x01(\tR\acsvData\x12\x1d\n" +
 	"\n" +
 	"account_id\x18\x02 \x01(\tR\taccountId\x12'\n" +
-	"\x0fskip_duplicates\x18\x03 \x01(\bR\x0eskipDuplicates\"\xa0\x01\n" +
+	"\x0fskip_duplicates\x18\x03 \x01(\bR\x0eskipDuplicates\x12\x1b\n" +
+	"\tcsv_bytes\x18\x04 \x01(\fR\bcsvBytes\x129\n" +
+	"\bencoding\x18\x05 \x01(\x0e2\x1d.etcdataprocessor.v1.EncodingR\bencoding\"\xce\x02\n" +
 	"\x16ProcessCSVDataResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
 	"\x05stats\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
-	"\x06errors\x18\x04 \x03(\tR\x06errors\"R\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\x12\x1b\n" +
+	"\timport_id\x18\x05 \x01(\tR\bimportId\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12-\n" +
+	"\x12duplicate_strategy\x18\a \x01(\tR\x11duplicateStrategy\x12\x19\n" +
+	"\brule_set\x18\b \x01(\tR\aruleSet\"\xaa\x01\n" +
 	"\x16ValidateCSVDataRequest\x12\x19\n" +
 	"\bcsv_data\x18\x01 \x01(\tR\acsvData\x12\x1d\n" +
 	"\n" +
-	"account_id\x18\x02 \x01(\tR\taccountId\"\xc0\x01\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x1b\n" +
+	"\tcsv_bytes\x18\x03 \x01(\fR\bcsvBytes\x129\n" +
+	"\bencoding\x18\x04 \x01(\x0e2\x1d.etcdataprocessor.v1.EncodingR\bencoding\"\xac\x03\n" +
 	"\x17ValidateCSVDataResponse\x12\x19\n" +
 	"\bis_valid\x18\x01 \x01(\bR\aisValid\x12<\n" +
 	"\x06errors\x18\x02 \x03(\v2$.etcdataprocessor.v1.ValidationErrorR\x06errors\x12'\n" +
 	"\x0fduplicate_count\x18\x03 \x01(\x05R\x0eduplicateCount\x12#\n" +
-	"\rtotal_records\x18\x04 \x01(\x05R\ftotalRecords\"\x14\n" +
+	"\rtotal_records\x18\x04 \x01(\x05R\ftotalRecords\x12E\n" +
+	"\rrecord_errors\x18\x05 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\x12-\n" +
+	"\x12duplicate_strategy\x18\x06 \x01(\tR\x11duplicateStrategy\x12)\n" +
+	"\x10correction_count\x18\a \x01(\x05R\x0fcorrectionCount\x12.\n" +
+	"\x13status_change_count\x18\b \x01(\x05R\x11statusChangeCount\x12\x19\n" +
+	"\brule_set\x18\t \x01(\tR\aruleSet\"\x14\n" +
 	"\x12HealthCheckRequest\"\xf2\x01\n" +
 	"\x13HealthCheckResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
@@ -680,25 +2237,156 @@ const file_src_proto_data_processor_proto_rawDesc = "" +
 	"\adetails\x18\x04 \x03(\v25.etcdataprocessor.v1.HealthCheckResponse.DetailsEntryR\adetails\x1a:\n" +
 	"\fDetailsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x01\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xcc\x03\n" +
 	"\x0fProcessingStats\x12#\n" +
 	"\rtotal_records\x18\x01 \x01(\x05R\ftotalRecords\x12#\n" +
 	"\rsaved_records\x18\x02 \x01(\x05R\fsavedRecords\x12'\n" +
 	"\x0fskipped_records\x18\x03 \x01(\x05R\x0eskippedRecords\x12#\n" +
-	"\rerror_records\x18\x04 \x01(\x05R\ferrorRecords\"\x83\x01\n" +
+	"\rerror_records\x18\x04 \x01(\x05R\ferrorRecords\x12'\n" +
+	"\x0fretried_records\x18\x05 \x01(\x05R\x0eretriedRecords\x12%\n" +
+	"\x0eretry_attempts\x18\x06 \x01(\x05R\rretryAttempts\x12)\n" +
+	"\x10transient_errors\x18\a \x01(\x05R\x0ftransientErrors\x12)\n" +
+	"\x10permanent_errors\x18\b \x01(\x05R\x0fpermanentErrors\x12+\n" +
+	"\x11corrected_records\x18\t \x01(\x05R\x10correctedRecords\x12%\n" +
+	"\x0estatus_changes\x18\n" +
+	" \x01(\x05R\rstatusChanges\x12'\n" +
+	"\x0fupdated_records\x18\v \x01(\x05R\x0eupdatedRecords\"\xa2\x02\n" +
+	"\vRecordError\x122\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x1e.etcdataprocessor.v1.ErrorCodeR\x04code\x12\x1f\n" +
+	"\vline_number\x18\x02 \x01(\x05R\n" +
+	"lineNumber\x12!\n" +
+	"\frecord_index\x18\x03 \x01(\x05R\vrecordIndex\x12\x14\n" +
+	"\x05field\x18\x04 \x01(\tR\x05field\x129\n" +
+	"\bseverity\x18\x05 \x01(\x0e2\x1d.etcdataprocessor.v1.SeverityR\bseverity\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\x12\x1c\n" +
+	"\tretryable\x18\a \x01(\bR\tretryable\x12\x12\n" +
+	"\x04rule\x18\b \x01(\tR\x04rule\"\x83\x01\n" +
 	"\x0fValidationError\x12\x1f\n" +
 	"\vline_number\x18\x01 \x01(\x05R\n" +
 	"lineNumber\x12\x14\n" +
 	"\x05field\x18\x02 \x01(\tR\x05field\x12\x18\n" +
 	"\amessage\x18\x03 \x01(\tR\amessage\x12\x1f\n" +
 	"\vrecord_data\x18\x04 \x01(\tR\n" +
-	"recordData2\xa6\x04\n" +
+	"recordData\"l\n" +
+	"\x18ListFailedRecordsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1b\n" +
+	"\timport_id\x18\x02 \x01(\tR\bimportId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"y\n" +
+	"\x19ListFailedRecordsResponse\x12;\n" +
+	"\arecords\x18\x01 \x03(\v2!.etcdataprocessor.v1.FailedRecordR\arecords\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\"m\n" +
+	"\x1dReprocessFailedRecordsRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1b\n" +
+	"\timport_id\x18\x02 \x01(\tR\bimportId\x12\x10\n" +
+	"\x03ids\x18\x03 \x03(\tR\x03ids\"\x98\x02\n" +
+	"\x1eReprocessFailedRecordsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12:\n" +
+	"\x05stats\x18\x03 \x01(\v2$.etcdataprocessor.v1.ProcessingStatsR\x05stats\x12\x16\n" +
+	"\x06errors\x18\x04 \x03(\tR\x06errors\x12'\n" +
+	"\x0fremaining_count\x18\x05 \x01(\x05R\x0eremainingCount\x12E\n" +
+	"\rrecord_errors\x18\x06 \x03(\v2 .etcdataprocessor.v1.RecordErrorR\frecordErrors\"\x8a\x03\n" +
+	"\fFailedRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\timport_id\x18\x02 \x01(\tR\bimportId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x03 \x01(\tR\taccountId\x12\x1f\n" +
+	"\vline_number\x18\x04 \x01(\x05R\n" +
+	"lineNumber\x12\x17\n" +
+	"\araw_row\x18\x05 \x01(\tR\x06rawRow\x12:\n" +
+	"\x06record\x18\x06 \x01(\v2\".etcdataprocessor.v1.ETCRecordDataR\x06record\x12\x14\n" +
+	"\x05stage\x18\a \x01(\tR\x05stage\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error\x12\x1f\n" +
+	"\verror_class\x18\t \x01(\tR\n" +
+	"errorClass\x12-\n" +
+	"\x12reprocess_attempts\x18\n" +
+	" \x01(\x05R\x11reprocessAttempts\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\v \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\f \x01(\x03R\tupdatedAt\"5\n" +
+	"\x14GetQuotaUsageRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\"\x8d\x01\n" +
+	"\x15GetQuotaUsageResponse\x127\n" +
+	"\x06global\x18\x01 \x01(\v2\x1f.etcdataprocessor.v1.QuotaUsageR\x06global\x12;\n" +
+	"\baccounts\x18\x02 \x03(\v2\x1f.etcdataprocessor.v1.QuotaUsageR\baccounts\"\xb4\x03\n" +
+	"\n" +
+	"QuotaUsage\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x129\n" +
+	"\x19requests_per_second_limit\x18\x02 \x01(\x01R\x16requestsPerSecondLimit\x12\x1f\n" +
+	"\vburst_limit\x18\x03 \x01(\x05R\n" +
+	"burstLimit\x12-\n" +
+	"\x12concurrent_imports\x18\x04 \x01(\x05R\x11concurrentImports\x128\n" +
+	"\x18concurrent_imports_limit\x18\x05 \x01(\x05R\x16concurrentImportsLimit\x12#\n" +
+	"\rrecords_today\x18\x06 \x01(\x03R\frecordsToday\x121\n" +
+	"\x15records_per_day_limit\x18\a \x01(\x03R\x12recordsPerDayLimit\x12\x10\n" +
+	"\x03day\x18\b \x01(\tR\x03day\x12-\n" +
+	"\x12throttled_requests\x18\t \x01(\x03R\x11throttledRequests\x12)\n" +
+	"\x10rejected_imports\x18\n" +
+	" \x01(\x03R\x0frejectedImports\"\xe6\x03\n" +
+	"\rETCRecordData\x12\x1d\n" +
+	"\n" +
+	"entry_date\x18\x01 \x01(\tR\tentryDate\x12\x1d\n" +
+	"\n" +
+	"entry_time\x18\x02 \x01(\tR\tentryTime\x12\x1b\n" +
+	"\texit_date\x18\x03 \x01(\tR\bexitDate\x12\x1b\n" +
+	"\texit_time\x18\x04 \x01(\tR\bexitTime\x12\x19\n" +
+	"\bentry_ic\x18\x05 \x01(\tR\aentryIc\x12\x17\n" +
+	"\aexit_ic\x18\x06 \x01(\tR\x06exitIc\x12\x1d\n" +
+	"\n" +
+	"route_info\x18\a \x01(\tR\trouteInfo\x12\x1d\n" +
+	"\n" +
+	"etc_amount\x18\b \x01(\x05R\tetcAmount\x12#\n" +
+	"\rnormal_amount\x18\t \x01(\x05R\fnormalAmount\x12)\n" +
+	"\x10discount_applied\x18\n" +
+	" \x01(\x05R\x0fdiscountApplied\x12\x18\n" +
+	"\amileage\x18\v \x01(\x05R\amileage\x12#\n" +
+	"\rvehicle_class\x18\f \x01(\x05R\fvehicleClass\x12%\n" +
+	"\x0evehicle_number\x18\r \x01(\tR\rvehicleNumber\x12\x1f\n" +
+	"\vcard_number\x18\x0e \x01(\tR\n" +
+	"cardNumber\x12\x14\n" +
+	"\x05notes\x18\x0f \x01(\tR\x05notes*\xb1\x03\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17ERROR_CODE_PARSE_FAILED\x10\x01\x12\x1c\n" +
+	"\x18ERROR_CODE_MISSING_FIELD\x10\x02\x12\x1b\n" +
+	"\x17ERROR_CODE_INVALID_DATE\x10\x03\x12\x1d\n" +
+	"\x19ERROR_CODE_INVALID_AMOUNT\x10\x04\x12$\n" +
+	" ERROR_CODE_INVALID_VEHICLE_CLASS\x10\x05\x12 \n" +
+	"\x1cERROR_CODE_VALIDATION_FAILED\x10\x06\x12 \n" +
+	"\x1cERROR_CODE_CONVERSION_FAILED\x10\a\x12\x1a\n" +
+	"\x16ERROR_CODE_SAVE_FAILED\x10\b\x12\x18\n" +
+	"\x14ERROR_CODE_DUPLICATE\x10\t\x12\x18\n" +
+	"\x14ERROR_CODE_CANCELLED\x10\n" +
+	"\x12\x1e\n" +
+	"\x1aERROR_CODE_INVALID_REQUEST\x10\v\x12\x19\n" +
+	"\x15ERROR_CODE_CORRECTION\x10\f\x12\x1c\n" +
+	"\x18ERROR_CODE_STATUS_CHANGE\x10\r*\\\n" +
+	"\bEncoding\x12\x11\n" +
+	"\rENCODING_AUTO\x10\x00\x12\x16\n" +
+	"\x12ENCODING_SHIFT_JIS\x10\x01\x12\x11\n" +
+	"\rENCODING_UTF8\x10\x02\x12\x12\n" +
+	"\x0eENCODING_UTF16\x10\x03*a\n" +
+	"\bSeverity\x12\x18\n" +
+	"\x14SEVERITY_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rSEVERITY_INFO\x10\x01\x12\x14\n" +
+	"\x10SEVERITY_WARNING\x10\x02\x12\x12\n" +
+	"\x0eSEVERITY_ERROR\x10\x032\xf9\b\n" +
 	"\x14DataProcessorService\x12\x86\x01\n" +
-	"\x0eProcessCSVFile\x12*.etcdataprocessor.v1.ProcessCSVFileRequest\x1a+.etcdataprocessor.v1.ProcessCSVFileResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/process/file\x12\x86\x01\n" +
+	"\x0eProcessCSVFile\x12*.etcdataprocessor.v1.ProcessCSVFileRequest\x1a+.etcdataprocessor.v1.ProcessCSVFileResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/process/file\x12\x8a\x01\n" +
+	"\x0fProcessCSVFiles\x12+.etcdataprocessor.v1.ProcessCSVFilesRequest\x1a,.etcdataprocessor.v1.ProcessCSVFilesResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/process/files\x12\x86\x01\n" +
 	"\x0eProcessCSVData\x12*.etcdataprocessor.v1.ProcessCSVDataRequest\x1a+.etcdataprocessor.v1.ProcessCSVDataResponse\"\x1b\x82\xd3\xe4\x93\x02\x15:\x01*\"\x10/v1/process/data\x12\x85\x01\n" +
 	"\x0fValidateCSVData\x12+.etcdataprocessor.v1.ValidateCSVDataRequest\x1a,.etcdataprocessor.v1.ValidateCSVDataResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/validate\x12t\n" +
 	"\vHealthCheck\x12'.etcdataprocessor.v1.HealthCheckRequest\x1a(.etcdataprocessor.v1.HealthCheckResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
-	"/v1/healthB;Z9github.com/yhonda-ohishi/etc_data_processor/src/api/pb;pbb\x06proto3"
+	"/v1/health\x12\x8e\x01\n" +
+	"\x11ListFailedRecords\x12-.etcdataprocessor.v1.ListFailedRecordsRequest\x1a..etcdataprocessor.v1.ListFailedRecordsResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/v1/failed-records\x12\xaa\x01\n" +
+	"\x16ReprocessFailedRecords\x122.etcdataprocessor.v1.ReprocessFailedRecordsRequest\x1a3.etcdataprocessor.v1.ReprocessFailedRecordsResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\"\x1c/v1/failed-records/reprocess\x12\x85\x01\n" +
+	"\rGetQuotaUsage\x12).etcdataprocessor.v1.GetQuotaUsageRequest\x1a*.etcdataprocessor.v1.GetQuotaUsageResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/v1/admin/quota-usageB;Z9github.com/yhonda-ohishi/etc_data_processor/src/api/pb;pbb\x06proto3"
 
 var (
 	file_src_proto_data_processor_proto_rawDescOnce sync.Once
@@ -712,38 +2400,81 @@ func file_src_proto_data_processor_proto_rawDescGZIP() []byte {
 	return file_src_proto_data_processor_proto_rawDescData
 }
 
-var file_src_proto_data_processor_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_src_proto_data_processor_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_src_proto_data_processor_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_src_proto_data_processor_proto_goTypes = []any{
-	(*ProcessCSVFileRequest)(nil),   // 0: etcdataprocessor.v1.ProcessCSVFileRequest
-	(*ProcessCSVFileResponse)(nil),  // 1: etcdataprocessor.v1.ProcessCSVFileResponse
-	(*ProcessCSVDataRequest)(nil),   // 2: etcdataprocessor.v1.ProcessCSVDataRequest
-	(*ProcessCSVDataResponse)(nil),  // 3: etcdataprocessor.v1.ProcessCSVDataResponse
-	(*ValidateCSVDataRequest)(nil),  // 4: etcdataprocessor.v1.ValidateCSVDataRequest
-	(*ValidateCSVDataResponse)(nil), // 5: etcdataprocessor.v1.ValidateCSVDataResponse
-	(*HealthCheckRequest)(nil),      // 6: etcdataprocessor.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),     // 7: etcdataprocessor.v1.HealthCheckResponse
-	(*ProcessingStats)(nil),         // 8: etcdataprocessor.v1.ProcessingStats
-	(*ValidationError)(nil),         // 9: etcdataprocessor.v1.ValidationError
-	nil,                             // 10: etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
+	(ErrorCode)(0),                         // 0: etcdataprocessor.v1.ErrorCode
+	(Encoding)(0),                          // 1: etcdataprocessor.v1.Encoding
+	(Severity)(0),                          // 2: etcdataprocessor.v1.Severity
+	(*ProcessCSVFileRequest)(nil),          // 3: etcdataprocessor.v1.ProcessCSVFileRequest
+	(*ProcessCSVFileResponse)(nil),         // 4: etcdataprocessor.v1.ProcessCSVFileResponse
+	(*ProcessCSVFilesRequest)(nil),         // 5: etcdataprocessor.v1.ProcessCSVFilesRequest
+	(*FileResult)(nil),                     // 6: etcdataprocessor.v1.FileResult
+	(*ProcessCSVFilesResponse)(nil),        // 7: etcdataprocessor.v1.ProcessCSVFilesResponse
+	(*ProcessCSVDataRequest)(nil),          // 8: etcdataprocessor.v1.ProcessCSVDataRequest
+	(*ProcessCSVDataResponse)(nil),         // 9: etcdataprocessor.v1.ProcessCSVDataResponse
+	(*ValidateCSVDataRequest)(nil),         // 10: etcdataprocessor.v1.ValidateCSVDataRequest
+	(*ValidateCSVDataResponse)(nil),        // 11: etcdataprocessor.v1.ValidateCSVDataResponse
+	(*HealthCheckRequest)(nil),             // 12: etcdataprocessor.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),            // 13: etcdataprocessor.v1.HealthCheckResponse
+	(*ProcessingStats)(nil),                // 14: etcdataprocessor.v1.ProcessingStats
+	(*RecordError)(nil),                    // 15: etcdataprocessor.v1.RecordError
+	(*ValidationError)(nil),                // 16: etcdataprocessor.v1.ValidationError
+	(*ListFailedRecordsRequest)(nil),       // 17: etcdataprocessor.v1.ListFailedRecordsRequest
+	(*ListFailedRecordsResponse)(nil),      // 18: etcdataprocessor.v1.ListFailedRecordsResponse
+	(*ReprocessFailedRecordsRequest)(nil),  // 19: etcdataprocessor.v1.ReprocessFailedRecordsRequest
+	(*ReprocessFailedRecordsResponse)(nil), // 20: etcdataprocessor.v1.ReprocessFailedRecordsResponse
+	(*FailedRecord)(nil),                   // 21: etcdataprocessor.v1.FailedRecord
+	(*GetQuotaUsageRequest)(nil),           // 22: etcdataprocessor.v1.GetQuotaUsageRequest
+	(*GetQuotaUsageResponse)(nil),          // 23: etcdataprocessor.v1.GetQuotaUsageResponse
+	(*QuotaUsage)(nil),                     // 24: etcdataprocessor.v1.QuotaUsage
+	(*ETCRecordData)(nil),                  // 25: etcdataprocessor.v1.ETCRecordData
+	nil,                                    // 26: etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
 }
 var file_src_proto_data_processor_proto_depIdxs = []int32{
-	8,  // 0: etcdataprocessor.v1.ProcessCSVFileResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
-	8,  // 1: etcdataprocessor.v1.ProcessCSVDataResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
-	9,  // 2: etcdataprocessor.v1.ValidateCSVDataResponse.errors:type_name -> etcdataprocessor.v1.ValidationError
-	10, // 3: etcdataprocessor.v1.HealthCheckResponse.details:type_name -> etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
-	0,  // 4: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:input_type -> etcdataprocessor.v1.ProcessCSVFileRequest
-	2,  // 5: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:input_type -> etcdataprocessor.v1.ProcessCSVDataRequest
-	4,  // 6: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:input_type -> etcdataprocessor.v1.ValidateCSVDataRequest
-	6,  // 7: etcdataprocessor.v1.DataProcessorService.HealthCheck:input_type -> etcdataprocessor.v1.HealthCheckRequest
-	1,  // 8: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:output_type -> etcdataprocessor.v1.ProcessCSVFileResponse
-	3,  // 9: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:output_type -> etcdataprocessor.v1.ProcessCSVDataResponse
-	5,  // 10: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:output_type -> etcdataprocessor.v1.ValidateCSVDataResponse
-	7,  // 11: etcdataprocessor.v1.DataProcessorService.HealthCheck:output_type -> etcdataprocessor.v1.HealthCheckResponse
-	8,  // [8:12] is the sub-list for method output_type
-	4,  // [4:8] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
+	14, // 0: etcdataprocessor.v1.ProcessCSVFileResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	15, // 1: etcdataprocessor.v1.ProcessCSVFileResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	6,  // 2: etcdataprocessor.v1.ProcessCSVFileResponse.entries:type_name -> etcdataprocessor.v1.FileResult
+	14, // 3: etcdataprocessor.v1.FileResult.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	15, // 4: etcdataprocessor.v1.FileResult.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	14, // 5: etcdataprocessor.v1.ProcessCSVFilesResponse.totals:type_name -> etcdataprocessor.v1.ProcessingStats
+	6,  // 6: etcdataprocessor.v1.ProcessCSVFilesResponse.files:type_name -> etcdataprocessor.v1.FileResult
+	1,  // 7: etcdataprocessor.v1.ProcessCSVDataRequest.encoding:type_name -> etcdataprocessor.v1.Encoding
+	14, // 8: etcdataprocessor.v1.ProcessCSVDataResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	15, // 9: etcdataprocessor.v1.ProcessCSVDataResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	1,  // 10: etcdataprocessor.v1.ValidateCSVDataRequest.encoding:type_name -> etcdataprocessor.v1.Encoding
+	16, // 11: etcdataprocessor.v1.ValidateCSVDataResponse.errors:type_name -> etcdataprocessor.v1.ValidationError
+	15, // 12: etcdataprocessor.v1.ValidateCSVDataResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	26, // 13: etcdataprocessor.v1.HealthCheckResponse.details:type_name -> etcdataprocessor.v1.HealthCheckResponse.DetailsEntry
+	0,  // 14: etcdataprocessor.v1.RecordError.code:type_name -> etcdataprocessor.v1.ErrorCode
+	2,  // 15: etcdataprocessor.v1.RecordError.severity:type_name -> etcdataprocessor.v1.Severity
+	21, // 16: etcdataprocessor.v1.ListFailedRecordsResponse.records:type_name -> etcdataprocessor.v1.FailedRecord
+	14, // 17: etcdataprocessor.v1.ReprocessFailedRecordsResponse.stats:type_name -> etcdataprocessor.v1.ProcessingStats
+	15, // 18: etcdataprocessor.v1.ReprocessFailedRecordsResponse.record_errors:type_name -> etcdataprocessor.v1.RecordError
+	25, // 19: etcdataprocessor.v1.FailedRecord.record:type_name -> etcdataprocessor.v1.ETCRecordData
+	24, // 20: etcdataprocessor.v1.GetQuotaUsageResponse.global:type_name -> etcdataprocessor.v1.QuotaUsage
+	24, // 21: etcdataprocessor.v1.GetQuotaUsageResponse.accounts:type_name -> etcdataprocessor.v1.QuotaUsage
+	3,  // 22: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:input_type -> etcdataprocessor.v1.ProcessCSVFileRequest
+	5,  // 23: etcdataprocessor.v1.DataProcessorService.ProcessCSVFiles:input_type -> etcdataprocessor.v1.ProcessCSVFilesRequest
+	8,  // 24: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:input_type -> etcdataprocessor.v1.ProcessCSVDataRequest
+	10, // 25: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:input_type -> etcdataprocessor.v1.ValidateCSVDataRequest
+	12, // 26: etcdataprocessor.v1.DataProcessorService.HealthCheck:input_type -> etcdataprocessor.v1.HealthCheckRequest
+	17, // 27: etcdataprocessor.v1.DataProcessorService.ListFailedRecords:input_type -> etcdataprocessor.v1.ListFailedRecordsRequest
+	19, // 28: etcdataprocessor.v1.DataProcessorService.ReprocessFailedRecords:input_type -> etcdataprocessor.v1.ReprocessFailedRecordsRequest
+	22, // 29: etcdataprocessor.v1.DataProcessorService.GetQuotaUsage:input_type -> etcdataprocessor.v1.GetQuotaUsageRequest
+	4,  // 30: etcdataprocessor.v1.DataProcessorService.ProcessCSVFile:output_type -> etcdataprocessor.v1.ProcessCSVFileResponse
+	7,  // 31: etcdataprocessor.v1.DataProcessorService.ProcessCSVFiles:output_type -> etcdataprocessor.v1.ProcessCSVFilesResponse
+	9,  // 32: etcdataprocessor.v1.DataProcessorService.ProcessCSVData:output_type -> etcdataprocessor.v1.ProcessCSVDataResponse
+	11, // 33: etcdataprocessor.v1.DataProcessorService.ValidateCSVData:output_type -> etcdataprocessor.v1.ValidateCSVDataResponse
+	13, // 34: etcdataprocessor.v1.DataProcessorService.HealthCheck:output_type -> etcdataprocessor.v1.HealthCheckResponse
+	18, // 35: etcdataprocessor.v1.DataProcessorService.ListFailedRecords:output_type -> etcdataprocessor.v1.ListFailedRecordsResponse
+	20, // 36: etcdataprocessor.v1.DataProcessorService.ReprocessFailedRecords:output_type -> etcdataprocessor.v1.ReprocessFailedRecordsResponse
+	23, // 37: etcdataprocessor.v1.DataProcessorService.GetQuotaUsage:output_type -> etcdataprocessor.v1.GetQuotaUsageResponse
+	30, // [30:38] is the sub-list for method output_type
+	22, // [22:30] is the sub-list for method input_type
+	22, // [22:22] is the sub-list for extension type_name
+	22, // [22:22] is the sub-list for extension extendee
+	0,  // [0:22] is the sub-list for field type_name
 }
 
 func init() { file_src_proto_data_processor_proto_init() }
@@ -756,13 +2487,14 @@ func file_src_proto_data_processor_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_src_proto_data_processor_proto_rawDesc), len(file_src_proto_data_processor_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      3,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_src_proto_data_processor_proto_goTypes,
 		DependencyIndexes: file_src_proto_data_processor_proto_depIdxs,
+		EnumInfos:         file_src_proto_data_processor_proto_enumTypes,
 		MessageInfos:      file_src_proto_data_processor_proto_msgTypes,
 	}.Build()
 	File_src_proto_data_processor_proto = out.File
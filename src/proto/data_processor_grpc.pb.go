@@ -19,10 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DataProcessorService_ProcessCSVFile_FullMethodName  = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVFile"
-	DataProcessorService_ProcessCSVData_FullMethodName  = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVData"
-	DataProcessorService_ValidateCSVData_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/ValidateCSVData"
-	DataProcessorService_HealthCheck_FullMethodName     = "/etcdataprocessor.v1.DataProcessorService/HealthCheck"
+	DataProcessorService_ProcessCSVFile_FullMethodName         = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVFile"
+	DataProcessorService_ProcessCSVFiles_FullMethodName        = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVFiles"
+	DataProcessorService_ProcessCSVData_FullMethodName         = "/etcdataprocessor.v1.DataProcessorService/ProcessCSVData"
+	DataProcessorService_ValidateCSVData_FullMethodName        = "/etcdataprocessor.v1.DataProcessorService/ValidateCSVData"
+	DataProcessorService_HealthCheck_FullMethodName            = "/etcdataprocessor.v1.DataProcessorService/HealthCheck"
+	DataProcessorService_ListFailedRecords_FullMethodName      = "/etcdataprocessor.v1.DataProcessorService/ListFailedRecords"
+	DataProcessorService_ReprocessFailedRecords_FullMethodName = "/etcdataprocessor.v1.DataProcessorService/ReprocessFailedRecords"
+	DataProcessorService_GetQuotaUsage_FullMethodName          = "/etcdataprocessor.v1.DataProcessorService/GetQuotaUsage"
 )
 
 // DataProcessorServiceClient is the client API for DataProcessorService service.
@@ -30,9 +34,15 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DataProcessorServiceClient interface {
 	ProcessCSVFile(ctx context.Context, in *ProcessCSVFileRequest, opts ...grpc.CallOption) (*ProcessCSVFileResponse, error)
+	// Process several CSV files (a list of paths, a directory or a glob) as one import
+	ProcessCSVFiles(ctx context.Context, in *ProcessCSVFilesRequest, opts ...grpc.CallOption) (*ProcessCSVFilesResponse, error)
 	ProcessCSVData(ctx context.Context, in *ProcessCSVDataRequest, opts ...grpc.CallOption) (*ProcessCSVDataResponse, error)
 	ValidateCSVData(ctx context.Context, in *ValidateCSVDataRequest, opts ...grpc.CallOption) (*ValidateCSVDataResponse, error)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	ListFailedRecords(ctx context.Context, in *ListFailedRecordsRequest, opts ...grpc.CallOption) (*ListFailedRecordsResponse, error)
+	ReprocessFailedRecords(ctx context.Context, in *ReprocessFailedRecordsRequest, opts ...grpc.CallOption) (*ReprocessFailedRecordsResponse, error)
+	// Admin: current use of the rate limits and import quotas
+	GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error)
 }
 
 type dataProcessorServiceClient struct {
@@ -53,6 +63,16 @@ func (c *dataProcessorServiceClient) ProcessCSVFile(ctx context.Context, in *Pro
 	return out, nil
 }
 
+func (c *dataProcessorServiceClient) ProcessCSVFiles(ctx context.Context, in *ProcessCSVFilesRequest, opts ...grpc.CallOption) (*ProcessCSVFilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessCSVFilesResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ProcessCSVFiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *dataProcessorServiceClient) ProcessCSVData(ctx context.Context, in *ProcessCSVDataRequest, opts ...grpc.CallOption) (*ProcessCSVDataResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ProcessCSVDataResponse)
@@ -83,14 +103,50 @@ func (c *dataProcessorServiceClient) HealthCheck(ctx context.Context, in *Health
 	return out, nil
 }
 
+func (c *dataProcessorServiceClient) ListFailedRecords(ctx context.Context, in *ListFailedRecordsRequest, opts ...grpc.CallOption) (*ListFailedRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFailedRecordsResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ListFailedRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) ReprocessFailedRecords(ctx context.Context, in *ReprocessFailedRecordsRequest, opts ...grpc.CallOption) (*ReprocessFailedRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReprocessFailedRecordsResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_ReprocessFailedRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataProcessorServiceClient) GetQuotaUsage(ctx context.Context, in *GetQuotaUsageRequest, opts ...grpc.CallOption) (*GetQuotaUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetQuotaUsageResponse)
+	err := c.cc.Invoke(ctx, DataProcessorService_GetQuotaUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DataProcessorServiceServer is the server API for DataProcessorService service.
 // All implementations must embed UnimplementedDataProcessorServiceServer
 // for forward compatibility.
 type DataProcessorServiceServer interface {
 	ProcessCSVFile(context.Context, *ProcessCSVFileRequest) (*ProcessCSVFileResponse, error)
+	// Process several CSV files (a list of paths, a directory or a glob) as one import
+	ProcessCSVFiles(context.Context, *ProcessCSVFilesRequest) (*ProcessCSVFilesResponse, error)
 	ProcessCSVData(context.Context, *ProcessCSVDataRequest) (*ProcessCSVDataResponse, error)
 	ValidateCSVData(context.Context, *ValidateCSVDataRequest) (*ValidateCSVDataResponse, error)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	ListFailedRecords(context.Context, *ListFailedRecordsRequest) (*ListFailedRecordsResponse, error)
+	ReprocessFailedRecords(context.Context, *ReprocessFailedRecordsRequest) (*ReprocessFailedRecordsResponse, error)
+	// Admin: current use of the rate limits and import quotas
+	GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error)
 	mustEmbedUnimplementedDataProcessorServiceServer()
 }
 
@@ -104,6 +160,9 @@ type UnimplementedDataProcessorServiceServer struct{}
 func (UnimplementedDataProcessorServiceServer) ProcessCSVFile(context.Context, *ProcessCSVFileRequest) (*ProcessCSVFileResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessCSVFile not implemented")
 }
+func (UnimplementedDataProcessorServiceServer) ProcessCSVFiles(context.Context, *ProcessCSVFilesRequest) (*ProcessCSVFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ProcessCSVFiles not implemented")
+}
 func (UnimplementedDataProcessorServiceServer) ProcessCSVData(context.Context, *ProcessCSVDataRequest) (*ProcessCSVDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProcessCSVData not implemented")
 }
@@ -113,6 +172,15 @@ func (UnimplementedDataProcessorServiceServer) ValidateCSVData(context.Context,
 func (UnimplementedDataProcessorServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedDataProcessorServiceServer) ListFailedRecords(context.Context, *ListFailedRecordsRequest) (*ListFailedRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFailedRecords not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) ReprocessFailedRecords(context.Context, *ReprocessFailedRecordsRequest) (*ReprocessFailedRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReprocessFailedRecords not implemented")
+}
+func (UnimplementedDataProcessorServiceServer) GetQuotaUsage(context.Context, *GetQuotaUsageRequest) (*GetQuotaUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQuotaUsage not implemented")
+}
 func (UnimplementedDataProcessorServiceServer) mustEmbedUnimplementedDataProcessorServiceServer() {}
 func (UnimplementedDataProcessorServiceServer) testEmbeddedByValue()                              {}
 
@@ -152,6 +220,24 @@ func _DataProcessorService_ProcessCSVFile_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataProcessorService_ProcessCSVFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessCSVFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ProcessCSVFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ProcessCSVFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ProcessCSVFiles(ctx, req.(*ProcessCSVFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _DataProcessorService_ProcessCSVData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ProcessCSVDataRequest)
 	if err := dec(in); err != nil {
@@ -206,6 +292,60 @@ func _DataProcessorService_HealthCheck_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataProcessorService_ListFailedRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFailedRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ListFailedRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ListFailedRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ListFailedRecords(ctx, req.(*ListFailedRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_ReprocessFailedRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReprocessFailedRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).ReprocessFailedRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_ReprocessFailedRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).ReprocessFailedRecords(ctx, req.(*ReprocessFailedRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataProcessorService_GetQuotaUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQuotaUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataProcessorServiceServer).GetQuotaUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataProcessorService_GetQuotaUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataProcessorServiceServer).GetQuotaUsage(ctx, req.(*GetQuotaUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DataProcessorService_ServiceDesc is the grpc.ServiceDesc for DataProcessorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -217,6 +357,10 @@ var DataProcessorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ProcessCSVFile",
 			Handler:    _DataProcessorService_ProcessCSVFile_Handler,
 		},
+		{
+			MethodName: "ProcessCSVFiles",
+			Handler:    _DataProcessorService_ProcessCSVFiles_Handler,
+		},
 		{
 			MethodName: "ProcessCSVData",
 			Handler:    _DataProcessorService_ProcessCSVData_Handler,
@@ -229,6 +373,18 @@ var DataProcessorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _DataProcessorService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "ListFailedRecords",
+			Handler:    _DataProcessorService_ListFailedRecords_Handler,
+		},
+		{
+			MethodName: "ReprocessFailedRecords",
+			Handler:    _DataProcessorService_ReprocessFailedRecords_Handler,
+		},
+		{
+			MethodName: "GetQuotaUsage",
+			Handler:    _DataProcessorService_GetQuotaUsage_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "src/proto/data_processor.proto",
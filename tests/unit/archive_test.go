@@ -0,0 +1,250 @@
+package unit
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/archive"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// zipEntry is one file written by buildZip; cp932 names are stored the way
+// Japanese Windows writes them, without the UTF-8 flag, and unflagged UTF-8
+// names the way many other zippers do
+type zipEntry struct {
+	name      string
+	data      string
+	cp932     bool
+	unflagged bool
+}
+
+func buildZip(t *testing.T, entries []zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.cp932 {
+			name, err := japanese.ShiftJIS.NewEncoder().String(e.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header.Name = name
+			header.NonUTF8 = true
+		}
+		if e.unflagged {
+			header.NonUTF8 = true
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// shiftJIS encodes CSV content the way issuer portals deliver it
+func shiftJIS(t *testing.T, s string) string {
+	t.Helper()
+	encoded, err := japanese.ShiftJIS.NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestArchive_Read(t *testing.T) {
+	data := buildZip(t, []zipEntry{
+		{name: "2025年09月.csv", data: "a", cp932: true},
+		{name: "__MACOSX/._2025年09月.csv", data: "meta"},
+		{name: "readme.txt", data: "ignored"},
+		{name: "2025/", data: ""},
+		{name: "2025/10月.CSV", data: "b"},
+		{name: "2025/11月.csv", data: "c", unflagged: true},
+	})
+
+	entries, err := archive.ReadBytes(data, archive.Limits{})
+	if err != nil {
+		t.Fatalf("ReadBytes() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 CSV entries, got %d", len(entries))
+	}
+	if entries[0].Name != "2025年09月.csv" || string(entries[0].Data) != "a" {
+		t.Errorf("Expected CP932 name to be decoded, got %q", entries[0].Name)
+	}
+	if entries[1].Name != "2025/10月.CSV" {
+		t.Errorf("Expected UTF-8 name to be kept, got %q", entries[1].Name)
+	}
+	if entries[2].Name != "2025/11月.csv" {
+		t.Errorf("Expected UTF-8 name without the UTF-8 flag to be kept, got %q", entries[2].Name)
+	}
+}
+
+func TestArchive_Limits(t *testing.T) {
+	big := strings.Repeat("0", 4096)
+	data := buildZip(t, []zipEntry{
+		{name: "a.csv", data: big},
+		{name: "b.csv", data: big},
+		{name: "c.txt", data: "x"},
+	})
+
+	tests := map[string]archive.Limits{
+		"entry count": {MaxEntries: 2},
+		"entry size":  {MaxEntryBytes: 1024},
+		"total size":  {MaxTotalBytes: 6000},
+	}
+	for name, limits := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := archive.ReadBytes(data, limits)
+			var limitErr *archive.LimitError
+			if !errors.As(err, &limitErr) {
+				t.Errorf("Expected limit error, got %v", err)
+			}
+		})
+	}
+
+	if _, err := archive.ReadBytes(data, archive.Limits{MaxEntries: 3, MaxEntryBytes: 4096, MaxTotalBytes: 8192}); err != nil {
+		t.Errorf("Expected archive within limits to be read, got %v", err)
+	}
+}
+
+func TestArchive_Invalid(t *testing.T) {
+	if _, err := archive.ReadBytes([]byte("not a zip"), archive.Limits{}); err == nil {
+		t.Error("Expected error for non-ZIP data")
+	}
+	if _, err := archive.ReadBytes(buildZip(t, []zipEntry{{name: "readme.txt", data: "x"}}), archive.Limits{}); err == nil {
+		t.Error("Expected error for archive without CSV files")
+	}
+	if !archive.IsZip("/data/STATEMENTS.ZIP") || archive.IsZip("/data/a.csv") {
+		t.Error("IsZip() must match the .zip extension case-insensitively")
+	}
+}
+
+func TestProcessCSVFile_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statements.zip")
+	data := buildZip(t, []zipEntry{
+		{name: "2025年09月.csv", data: shiftJIS(t, retryTestCSV), cp932: true},
+		{name: "2025年10月.csv", data: shiftJIS(t, retryTestCSV), cp932: true},
+	})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db := &concurrentDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db)
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath:    path,
+		AccountId:      "test-account",
+		SkipDuplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFile() error = %v", err)
+	}
+
+	if len(resp.Entries) != 2 || resp.Entries[0].CsvFilePath != "2025年09月.csv" {
+		t.Fatalf("Expected one sub-result per entry, got %+v", resp.Entries)
+	}
+	// The second statement repeats the first, so its rows are skipped
+	if resp.Stats.TotalRecords != 4 || resp.Stats.SavedRecords != 2 || resp.Stats.SkippedRecords != 2 || db.saved != 2 {
+		t.Errorf("Unexpected totals: %+v", resp.Stats)
+	}
+	notice := resp.Entries[1].RecordErrors[0]
+	if notice.Code != pb.ErrorCode_ERROR_CODE_DUPLICATE || !strings.Contains(notice.Message, "2025年09月.csv line 2") {
+		t.Errorf("Expected notice pointing at the first entry, got %+v", notice)
+	}
+
+	// Files inside a ZIP are also accepted by the batch RPC
+	batch, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		CsvFilePaths: []string{path},
+		AccountId:    "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+	if len(batch.Files) != 2 || batch.Files[1].CsvFilePath != path+"!/2025年10月.csv" {
+		t.Errorf("Expected archive entries as batch files, got %+v", batch.Files)
+	}
+}
+
+func TestProcessCSVFile_ZipCorrectionWins(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statements.zip")
+	data := buildZip(t, []zipEntry{
+		{name: "original.csv", data: strings.SplitN(septCSV, "\n", 2)[0]},
+		{name: "reissued.csv", data: "25/09/01,08:00,25/09/01,09:01,東京,横浜,1500,-200,1300,2,1234,********12345678,"},
+	})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The original row is saved slowly, so the correction would overtake it
+	db := &slowUpsertDBClient{slow: func(data interface{}) bool {
+		return data.(map[string]interface{})["exit_time"] == "09:00"
+	}}
+	service := handler.NewDataProcessorServiceWithOptions(db,
+		handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)),
+		handler.WithBatchLimits(handler.BatchLimits{MaxConcurrency: 2}),
+	)
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: path,
+		AccountId:   "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFile() error = %v", err)
+	}
+
+	if len(db.rows) != 1 {
+		t.Fatalf("Expected one stored trip, got %d", len(db.rows))
+	}
+	for _, row := range db.rows {
+		if exit := row.(map[string]interface{})["exit_time"]; exit != "09:01" {
+			t.Errorf("Expected the corrected row stored, got exit time %v", exit)
+		}
+	}
+	if stats := resp.Entries[1].Stats; stats.CorrectedRecords != 1 || stats.UpdatedRecords != 1 {
+		t.Errorf("Expected the correction counted on the later entry, got %+v", stats)
+	}
+}
+
+func TestProcessCSVFile_ZipBomb(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bomb.zip")
+	data := buildZip(t, []zipEntry{{name: "huge.csv", data: strings.Repeat("0", 1<<20)}})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db := &concurrentDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithArchiveLimits(archive.Limits{MaxEntryBytes: 64 << 10}))
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: path,
+		AccountId:   "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFile() error = %v", err)
+	}
+	if resp.Success || !strings.Contains(resp.Message, "expands to more than") || db.saved != 0 {
+		t.Errorf("Expected archive to be rejected, got %q", resp.Message)
+	}
+	if resp.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_PARSE_FAILED {
+		t.Errorf("Expected PARSE_FAILED, got %v", resp.RecordErrors[0].Code)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testAuthenticator knows a key for acct-1, a hashed key for acct-2 and acct-3
+// and an admin key for every account
+func testAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+	sum := sha256.Sum256([]byte("key-two"))
+	store, err := auth.NewStore([]auth.Key{
+		{Name: "one", Key: "key-one", Accounts: []string{"acct-1"}},
+		{Name: "two", KeySHA256: hex.EncodeToString(sum[:]), Accounts: []string{"acct-2", "acct-3"}},
+		{Name: "admin", Key: "key-admin", Accounts: []string{auth.AllAccounts}},
+	})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return auth.NewAuthenticator(store, "/grpc.health.v1.Health/", pb.DataProcessorService_HealthCheck_FullMethodName)
+}
+
+func TestAuth_NewStore(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []auth.Key
+	}{
+		{"missing name", []auth.Key{{Key: "k", Accounts: []string{"a"}}}},
+		{"missing key", []auth.Key{{Name: "n", Accounts: []string{"a"}}}},
+		{"key and hash", []auth.Key{{Name: "n", Key: "k", KeySHA256: strings.Repeat("0", 64), Accounts: []string{"a"}}}},
+		{"bad hash", []auth.Key{{Name: "n", KeySHA256: "abc", Accounts: []string{"a"}}}},
+		{"no accounts", []auth.Key{{Name: "n", Key: "k"}}},
+		{"duplicate name", []auth.Key{{Name: "n", Key: "k1", Accounts: []string{"a"}}, {Name: "n", Key: "k2", Accounts: []string{"a"}}}},
+		{"duplicate key", []auth.Key{{Name: "n1", Key: "k", Accounts: []string{"a"}}, {Name: "n2", Key: "k", Accounts: []string{"b"}}}},
+	}
+	for _, tt := range tests {
+		if _, err := auth.NewStore(tt.keys); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	store, _ := auth.NewStore([]auth.Key{{Name: "one", Key: "key-one", Accounts: []string{"acct-1"}}})
+	if p, err := store.Authenticate("key-one"); err != nil || p.Name != "one" || !p.Allows("acct-1") || p.Allows("acct-2") {
+		t.Errorf("Authenticate(key-one) = %+v, %v", p, err)
+	}
+	for _, key := range []string{"", "key-two"} {
+		if _, err := store.Authenticate(key); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("Authenticate(%q) error = %v, want Unauthenticated", key, err)
+		}
+	}
+}
+
+func TestAuth_GRPC(t *testing.T) {
+	a := testAuthenticator(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(a.UnaryServerInterceptor()))
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{}))
+	go server.Serve(lis)
+	defer server.Stop()
+	client := pb.NewDataProcessorServiceClient(mustDial(t, lis.Addr().String()))
+
+	withKey := func(key, value string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), key, value)
+	}
+	process := func(ctx context.Context, accountID string) error {
+		_, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: accountID})
+		return err
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		accountID string
+		want      codes.Code
+	}{
+		{"no credentials", context.Background(), "acct-1", codes.Unauthenticated},
+		{"unknown key", withKey("authorization", "Bearer nope"), "acct-1", codes.Unauthenticated},
+		{"not a bearer token", withKey("authorization", "Basic a2V5LW9uZQ=="), "acct-1", codes.Unauthenticated},
+		{"bearer key", withKey("authorization", "Bearer key-one"), "acct-1", codes.OK},
+		{"other account", withKey("authorization", "Bearer key-one"), "acct-2", codes.PermissionDenied},
+		{"hashed api key", withKey("x-api-key", "key-two"), "acct-3", codes.OK},
+		{"hashed key, other account", withKey("x-api-key", "key-two"), "acct-1", codes.PermissionDenied},
+		{"admin key", withKey("authorization", "bearer key-admin"), "acct-9", codes.OK},
+	}
+	for _, tt := range tests {
+		if got := status.Code(process(tt.ctx, tt.accountID)); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Failed records and validation are limited to the key's accounts too
+	ctx := withKey("x-api-key", "key-one")
+	if _, err := client.ListFailedRecords(ctx, &pb.ListFailedRecordsRequest{AccountId: "acct-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ListFailedRecords() for another account error = %v, want PermissionDenied", err)
+	}
+	if _, err := client.ReprocessFailedRecords(ctx, &pb.ReprocessFailedRecordsRequest{AccountId: "acct-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ReprocessFailedRecords() for another account error = %v, want PermissionDenied", err)
+	}
+	if _, err := client.ValidateCSVData(ctx, &pb.ValidateCSVDataRequest{CsvData: septCSV}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ValidateCSVData() without an account error = %v, want PermissionDenied", err)
+	}
+	if _, err := client.ProcessCSVFile(ctx, &pb.ProcessCSVFileRequest{CsvFilePath: "/etc/passwd", AccountId: "acct-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ProcessCSVFile() for another account error = %v, want PermissionDenied", err)
+	}
+	if _, err := client.ProcessCSVFiles(ctx, &pb.ProcessCSVFilesRequest{CsvFilePaths: []string{"a.csv"}, AccountId: "acct-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("ProcessCSVFiles() for another account error = %v, want PermissionDenied", err)
+	}
+
+	// Health checks need no key
+	if _, err := client.HealthCheck(context.Background(), &pb.HealthCheckRequest{}); err != nil {
+		t.Errorf("HealthCheck() error = %v", err)
+	}
+}
+
+func TestAuth_Gateway(t *testing.T) {
+	a := testAuthenticator(t)
+	service := handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{})
+	gw, err := gateway.New(context.Background(), gateway.Options{Server: service, Uploader: service, UploadDir: t.TempDir(), Auth: a})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+
+	post := func(path, header, value, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, strings.NewReader(body))
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	data := func(accountID string) string {
+		return `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "` + accountID + `"}`
+	}
+
+	if code := post("/v1/process/data", "", "", data("acct-1")); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a key, got %d", code)
+	}
+	if code := post("/v1/process/data", "Authorization", "Bearer key-one", data("acct-2")); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another account, got %d", code)
+	}
+	if code := post("/v1/process/data", "X-Api-Key", "key-one", data("acct-1")); code != http.StatusOK {
+		t.Errorf("Expected 200 for the key's account, got %d", code)
+	}
+
+	body, contentType := multipartBody(t,
+		formPart{name: "account_id", value: "acct-2"},
+		formPart{name: "file", fileName: "statement.csv", value: septCSV},
+	)
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/upload", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer key-one")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for an upload to another account, got %d", resp.StatusCode)
+	}
+
+	for _, path := range []string{"/v1/health", "/swagger.json"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected %s to need no key, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestAuth_ProxyGatewayForwardsKeys(t *testing.T) {
+	a := testAuthenticator(t)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(a.UnaryServerInterceptor()))
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw, err := gateway.New(ctx, gateway.Options{Mode: gateway.ModeProxy, Endpoint: lis.Addr().String(), Auth: a})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		header, value, accountID string
+		want                     int
+	}{
+		{"X-Api-Key", "key-two", "acct-2", http.StatusOK},
+		{"Authorization", "Bearer key-two", "acct-2", http.StatusOK},
+		{"Authorization", "Bearer key-two", "acct-1", http.StatusForbidden},
+	} {
+		body := `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "` + tt.accountID + `"}`
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/process/data", strings.NewReader(body))
+		req.Header.Set(tt.header, tt.value)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s for %s: got %d, want %d", tt.header, tt.accountID, resp.StatusCode, tt.want)
+		}
+	}
+}
\ No newline at end of file
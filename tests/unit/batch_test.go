@@ -0,0 +1,277 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Batch fixtures have no header row; octCSV repeats the first trip of septCSV
+const septCSV = `25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト1
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********87654321,テスト2`
+
+const octCSV = `25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト1
+25/10/01,08:00,25/10/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト3`
+
+// concurrentDBClient is safe for concurrent saves and records how many ran at once
+type concurrentDBClient struct {
+	mu       sync.Mutex
+	saved    int
+	inFlight int
+	peak     int
+	delay    time.Duration
+}
+
+func (c *concurrentDBClient) SaveETCData(data interface{}) error {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.mu.Unlock()
+
+	time.Sleep(c.delay)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.saved++
+	c.mu.Unlock()
+	return nil
+}
+
+// batchFixture creates a directory with two monthly statements and a non-CSV file
+func batchFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "2025-09.csv"), septCSV)
+	writeFile(t, filepath.Join(dir, "2025-10.csv"), octCSV)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a csv")
+	return dir
+}
+
+func TestProcessCSVFiles_CrossFileDuplicates(t *testing.T) {
+	dir := batchFixture(t)
+	db := &concurrentDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db)
+
+	resp, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		Directory:      dir,
+		AccountId:      "test-account",
+		SkipDuplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+
+	if len(resp.Files) != 2 || resp.FailedFiles != 0 || !resp.Success {
+		t.Fatalf("Expected two successful files, got %+v", resp.Files)
+	}
+	if resp.Totals.TotalRecords != 4 || resp.Totals.SavedRecords != 3 || resp.Totals.SkippedRecords != 1 {
+		t.Errorf("Unexpected totals: %+v", resp.Totals)
+	}
+	if db.saved != 3 {
+		t.Errorf("Expected 3 saves, got %d", db.saved)
+	}
+	if resp.ImportId == "" {
+		t.Error("Expected a batch import ID")
+	}
+
+	second := resp.Files[1]
+	if !strings.HasSuffix(second.CsvFilePath, "2025-10.csv") || second.Stats.SkippedRecords != 1 {
+		t.Fatalf("Expected the duplicate to be skipped in the second file, got %+v", second)
+	}
+	notice := second.RecordErrors[0]
+	if notice.Code != pb.ErrorCode_ERROR_CODE_DUPLICATE || notice.LineNumber != 1 || !strings.Contains(notice.Message, "2025-09.csv line 1") {
+		t.Errorf("Expected notice pointing at the first file, got %+v", notice)
+	}
+}
+
+func TestProcessCSVFiles_PathsAndGlob(t *testing.T) {
+	dir := batchFixture(t)
+	service := handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{})
+
+	resp, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		CsvFilePaths: []string{filepath.Join(dir, "2025-09.csv"), filepath.Join(dir, "missing.csv")},
+		Glob:         filepath.Join(dir, "2025-*.csv"),
+		AccountId:    "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+
+	// The glob matches 2025-09.csv again; it is processed once
+	if len(resp.Files) != 3 {
+		t.Fatalf("Expected 3 files, got %d", len(resp.Files))
+	}
+	missing := resp.Files[1]
+	if missing.Success || !strings.Contains(missing.Message, "not found") || resp.FailedFiles != 1 {
+		t.Errorf("Expected missing file to fail on its own, got %+v", missing)
+	}
+	if !resp.Success || resp.Totals.SavedRecords != 4 {
+		t.Errorf("Expected the other files to be saved, got %+v", resp.Totals)
+	}
+}
+
+func TestProcessCSVFiles_BoundedConcurrency(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "b.csv", "c.csv", "d.csv", "e.csv"} {
+		writeFile(t, filepath.Join(dir, name), septCSV)
+	}
+
+	db := &concurrentDBClient{delay: 5 * time.Millisecond}
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithBatchLimits(handler.BatchLimits{MaxConcurrency: 4}))
+
+	resp, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		Directory:   dir,
+		AccountId:   "test-account",
+		Concurrency: 2,
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+	if resp.Totals.SavedRecords != 10 {
+		t.Errorf("Expected duplicates across files to be saved when not skipped, got %d", resp.Totals.SavedRecords)
+	}
+	if db.peak > 2 {
+		t.Errorf("Expected at most 2 files at once, got %d", db.peak)
+	}
+}
+
+// slowUpsertDBClient stores records by match key, delaying the saves slow selects
+type slowUpsertDBClient struct {
+	mu   sync.Mutex
+	rows map[string]interface{}
+	slow func(data interface{}) bool
+}
+
+func (c *slowUpsertDBClient) SaveETCData(data interface{}) error {
+	return nil
+}
+
+func (c *slowUpsertDBClient) UpsertETCData(matchKey string, data interface{}) (bool, error) {
+	if c.slow(data) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rows == nil {
+		c.rows = make(map[string]interface{})
+	}
+	_, replaced := c.rows[matchKey]
+	c.rows[matchKey] = data
+	return replaced, nil
+}
+
+func TestProcessCSVFiles_CorrectionInLaterFileWins(t *testing.T) {
+	dir := t.TempDir()
+	original := filepath.Join(dir, "a.csv")
+	corrected := filepath.Join(dir, "b.csv")
+	writeFile(t, original, strings.SplitN(septCSV, "\n", 2)[0])
+	writeFile(t, corrected, "25/09/01,08:00,25/09/01,09:01,東京,横浜,1500,-200,1300,2,1234,********12345678,")
+
+	// The original row is saved slowly, so the correction would overtake it
+	db := &slowUpsertDBClient{slow: func(data interface{}) bool {
+		return data.(map[string]interface{})["exit_time"] == "09:00"
+	}}
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)))
+
+	resp, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		CsvFilePaths: []string{original, corrected},
+		AccountId:    "test-account",
+		Concurrency:  2,
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+
+	if len(db.rows) != 1 {
+		t.Fatalf("Expected one stored trip, got %d", len(db.rows))
+	}
+	for _, row := range db.rows {
+		if exit := row.(map[string]interface{})["exit_time"]; exit != "09:01" {
+			t.Errorf("Expected the corrected row stored, got exit time %v", exit)
+		}
+	}
+	if stats := resp.Files[1].Stats; stats.CorrectedRecords != 1 || stats.UpdatedRecords != 1 {
+		t.Errorf("Expected the correction counted on the later file, got %+v", stats)
+	}
+	if stats := resp.Files[0].Stats; stats.UpdatedRecords != 0 {
+		t.Errorf("Expected the original inserted, got %+v", stats)
+	}
+}
+
+func TestProcessCSVFiles_Errors(t *testing.T) {
+	dir := batchFixture(t)
+	service := handler.NewDataProcessorServiceWithOptions(nil, handler.WithBatchLimits(handler.BatchLimits{MaxFiles: 1}))
+
+	tests := map[string]*pb.ProcessCSVFilesRequest{
+		"no files":         {AccountId: "test-account"},
+		"empty glob":       {AccountId: "test-account", Glob: filepath.Join(dir, "*.xlsx")},
+		"too many files":   {AccountId: "test-account", Directory: dir},
+		"missing account":  {Directory: dir},
+		"negative workers": {AccountId: "test-account", Directory: dir, Concurrency: -1},
+		"bad glob":         {AccountId: "test-account", Glob: "[" + dir},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.ProcessCSVFiles(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+
+	_, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		AccountId: "test-account",
+		Directory: filepath.Join(dir, "missing"),
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for missing directory, got %v", err)
+	}
+}
+
+func TestProcessCSVFiles_Sandbox(t *testing.T) {
+	root, outside := sandboxFixture(t)
+	sb, err := sandbox.New([]string{root}, 0, []string{".csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{}, handler.WithSandbox(sb))
+
+	rejected := map[string]*pb.ProcessCSVFilesRequest{
+		"directory": {AccountId: "test-account", Directory: outside},
+		"glob":      {AccountId: "test-account", Glob: filepath.Join(outside, "*.csv")},
+		"escape":    {AccountId: "test-account", Glob: filepath.Join(root, "..", "outside", "*.csv")},
+	}
+	for name, req := range rejected {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.ProcessCSVFiles(context.Background(), req); status.Code(err) != codes.PermissionDenied {
+				t.Errorf("Expected PermissionDenied, got %v", err)
+			}
+		})
+	}
+
+	// A listed path outside the sandbox fails on its own
+	resp, err := service.ProcessCSVFiles(context.Background(), &pb.ProcessCSVFilesRequest{
+		AccountId:    "test-account",
+		CsvFilePaths: []string{filepath.Join(root, "data.csv"), filepath.Join(outside, "secret.csv")},
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVFiles() error = %v", err)
+	}
+	if !resp.Files[0].Success || resp.Files[1].Success || resp.FailedFiles != 1 {
+		t.Errorf("Expected only the sandboxed file to be processed, got %+v", resp.Files)
+	}
+	if strings.Contains(resp.Files[1].Message, "root:x") {
+		t.Error("Rejection must not echo file contents")
+	}
+}
\ No newline at end of file
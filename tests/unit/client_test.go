@@ -0,0 +1,257 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/client"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// clientCSV returns a file with a header row and ten rows; the seventh, on
+// line 8, has an invalid date
+func clientCSV() string {
+	rows := []string{"利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考"}
+	for i := 1; i <= 10; i++ {
+		day := fmt.Sprintf("%02d", i)
+		if i == 7 {
+			day = "xx"
+		}
+		rows = append(rows, fmt.Sprintf("25/09/%s,08:00,25/09/%s,09:00,東京,横浜,1500,-300,1200,2,1234,********1234%04d,", day, day, i))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// serveClient starts a server that authenticates with testAuthenticator and
+// keeps failed records, and returns its address
+func serveClient(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptors = append(interceptors, testAuthenticator(t).UnaryServerInterceptor())
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	service := handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{},
+		handler.WithDeadLetterStore(deadletter.NewMemoryStore()))
+	pb.RegisterDataProcessorServiceServer(server, service)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func newTestClient(t *testing.T, addr string, opts ...client.Option) *client.Client {
+	t.Helper()
+	c, err := client.New(addr, append([]client.Option{client.WithAPIKey("key-one")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestClient_Process(t *testing.T) {
+	addr := serveClient(t)
+	ctx := context.Background()
+	opts := client.FileOptions{AccountID: "acct-1"}
+
+	// A chunk size only splits Validate; the import stays whole
+	c := newTestClient(t, addr, client.WithChunkBytes(300))
+	res, err := c.Process(ctx, []byte(clientCSV()), opts)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if res.Stats.Total != 10 || res.Stats.Saved != 9 || res.Stats.Failed != 1 {
+		t.Errorf("Stats = %+v, want 10 total, 9 saved, 1 failed", res.Stats)
+	}
+	if len(res.Errors) == 0 || res.Errors[0].Line != 8 || res.Errors[0].RecordIndex != 6 {
+		t.Errorf("Errors = %+v, want one on line 8, record 6", res.Errors)
+	}
+	if st, err := c.ImportStatus(ctx, "acct-1", res.ImportID); err != nil || st.Total != 1 {
+		t.Errorf("ImportStatus(%s) = %+v, %v, want the failed record", res.ImportID, st, err)
+	}
+
+	// Duplicates far apart in a large file are still found
+	lines := strings.Split(clientCSV(), "\n")
+	dup := clientCSV() + "\n" + lines[1]
+	if res, err := c.Process(ctx, []byte(dup), client.FileOptions{AccountID: "acct-1", SkipDuplicates: true}); err != nil || res.Stats.Skipped != 1 {
+		t.Errorf("Process() with a distant duplicate = %+v, %v, want 1 skipped", res, err)
+	}
+
+	v, err := c.Validate(ctx, []byte(clientCSV()), opts)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if v.Valid || v.Total != 10 || v.Chunks < 3 {
+		t.Errorf("Validate() = %+v, want 10 records in several chunks, invalid", v)
+	}
+	if h, err := c.Health(ctx); err != nil || h.Status != "healthy" {
+		t.Errorf("Health() = %+v, %v", h, err)
+	}
+
+	// Keys are only valid for their accounts
+	if _, err := c.Process(ctx, []byte(clientCSV()), client.FileOptions{AccountID: "acct-2"}); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Process() for another account error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	var calls, refusals, retryAfter atomic.Int32
+	refuse := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		calls.Add(1)
+		if refusals.Add(-1) >= 0 {
+			grpc.SetHeader(ctx, metadata.Pairs(ratelimit.RetryAfterHeader, strconv.Itoa(int(retryAfter.Load()))))
+			return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		}
+		return next(ctx, req)
+	}
+	addr := serveClient(t, refuse)
+	c := newTestClient(t, addr, client.WithRetryPolicy(client.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Second,
+	}))
+	ctx := context.Background()
+
+	refusals.Store(2)
+	if _, err := c.Health(ctx); err != nil || calls.Load() != 3 {
+		t.Errorf("Health() after two refusals error = %v, calls = %d, want success on the third", err, calls.Load())
+	}
+
+	calls.Store(0)
+	refusals.Store(3)
+	if _, err := c.Health(ctx); status.Code(err) != codes.ResourceExhausted || calls.Load() != 3 {
+		t.Errorf("Health() refused three times error = %v, calls = %d, want ResourceExhausted after 3", err, calls.Load())
+	}
+
+	// A retry-after longer than the maximum backoff is not waited for
+	calls.Store(0)
+	refusals.Store(1)
+	retryAfter.Store(60)
+	if _, err := c.Health(ctx); status.Code(err) != codes.ResourceExhausted || calls.Load() != 1 {
+		t.Errorf("Health() with retry-after 60 error = %v, calls = %d, want ResourceExhausted after 1", err, calls.Load())
+	}
+
+	// Other errors are not retried
+	calls.Store(0)
+	if _, err := c.ImportStatus(ctx, "acct-2", ""); status.Code(err) != codes.PermissionDenied || calls.Load() != 1 {
+		t.Errorf("ImportStatus() for another account error = %v, calls = %d, want PermissionDenied after 1", err, calls.Load())
+	}
+}
+
+func TestClient_RetryUnavailable(t *testing.T) {
+	var calls, failures atomic.Int32
+	unavailable := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		calls.Add(1)
+		if failures.Add(-1) >= 0 {
+			return nil, status.Error(codes.Unavailable, "connection lost")
+		}
+		return next(ctx, req)
+	}
+	addr := serveClient(t, unavailable)
+	policy := client.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Second}
+	c := newTestClient(t, addr, client.WithRetryPolicy(policy))
+	ctx := context.Background()
+	opts := client.FileOptions{AccountID: "acct-1"}
+
+	// Reads are retried
+	failures.Store(2)
+	if _, err := c.Health(ctx); err != nil || calls.Load() != 3 {
+		t.Errorf("Health() after two failures error = %v, calls = %d, want success on the third", err, calls.Load())
+	}
+
+	// An import may already have saved rows, so it is not retried
+	calls.Store(0)
+	failures.Store(1)
+	if _, err := c.Process(ctx, []byte(clientCSV()), opts); status.Code(err) != codes.Unavailable || calls.Load() != 1 {
+		t.Errorf("Process() after a failure error = %v, calls = %d, want Unavailable after 1", err, calls.Load())
+	}
+
+	// unless the policy accepts importing a chunk twice
+	policy.RetryImportsOnUnavailable = true
+	c = newTestClient(t, addr, client.WithRetryPolicy(policy))
+	calls.Store(0)
+	failures.Store(1)
+	if _, err := c.Process(ctx, []byte(clientCSV()), opts); err != nil || calls.Load() != 2 {
+		t.Errorf("Process() with retries on Unavailable error = %v, calls = %d, want success on the second", err, calls.Load())
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	block := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	addr := serveClient(t, block)
+
+	c := newTestClient(t, addr, client.WithTimeout(50*time.Millisecond))
+	if _, err := c.Health(context.Background()); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Health() error = %v, want DeadlineExceeded from the default timeout", err)
+	}
+
+	// The caller's deadline wins over the default
+	c = newTestClient(t, addr, client.WithTimeout(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.Health(ctx); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("Health() error = %v, want DeadlineExceeded from the context", err)
+	}
+}
+
+func TestEtcproc_Client(t *testing.T) {
+	addr := serveClient(t)
+	path := writeTempCSV(t, "client.csv", clientCSV())
+
+	code, stdout, stderr := runEtcproc(t, "", "client", "process", "-addr", addr, "-api-key", "key-one", "-account", "acct-1", "-chunk-bytes", "300", path)
+	if code != 1 || !strings.Contains(stdout, "total 10, saved 9") || !strings.Contains(stdout, "failed records: import") {
+		t.Errorf("client process = %d, %q, %q", code, stdout, stderr)
+	}
+
+	code, stdout, stderr = runEtcproc(t, "", "client", "validate", "-addr", addr, "-api-key", "key-one", "-account", "acct-1", "-format", "json", path)
+	if code != 1 || !strings.Contains(stdout, `"total": 10`) || !strings.Contains(stdout, `"line": 8`) {
+		t.Errorf("client validate = %d, %q, %q", code, stdout, stderr)
+	}
+
+	code, stdout, stderr = runEtcproc(t, "", "client", "status", "-addr", addr)
+	if code != 0 || !strings.Contains(stdout, "server:  healthy") {
+		t.Errorf("client status = %d, %q, %q", code, stdout, stderr)
+	}
+	code, stdout, stderr = runEtcproc(t, "", "client", "status", "-addr", addr, "-api-key", "key-one", "-account", "acct-1")
+	if code != 1 || !strings.Contains(stdout, "failed records (all imports): 1") {
+		t.Errorf("client status -account = %d, %q, %q", code, stdout, stderr)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"client"}, "Usage: etcproc client"},
+		{[]string{"client", "nope"}, `unknown command "nope"`},
+		{[]string{"client", "process", "-addr", addr, path}, "-account is required"},
+		{[]string{"client", "status", "-addr", addr, "-import", "x"}, "-import needs -account"},
+		{[]string{"client", "status", "-addr", addr, "extra"}, "unexpected arguments"},
+	}
+	for _, tt := range tests {
+		if code, _, stderr := runEtcproc(t, "", tt.args...); code != 2 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("%v = %d, %q, want usage error %q", tt.args, code, stderr, tt.want)
+		}
+	}
+
+	// Without a key the server refuses the import
+	if code, _, stderr := runEtcproc(t, "", "client", "process", "-addr", addr, "-account", "acct-1", path); code != 3 || !strings.Contains(stderr, "etcproc client process:") {
+		t.Errorf("client process without a key = %d, %q", code, stderr)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,312 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testDeadLetterStore(t *testing.T, store deadletter.Store) {
+	t.Helper()
+
+	first, err := store.Add(deadletter.FailedRecord{
+		ImportID:   "import-1",
+		AccountID:  "account-a",
+		LineNumber: 2,
+		RawRow:     "25/09/01,08:00",
+		Record:     parser.ActualETCRecord{EntryDate: "25/09/01", CardNumber: "1234"},
+		Stage:      deadletter.StageSave,
+		Error:      "db down",
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if first.ID == "" {
+		t.Fatal("Expected Add to assign an ID")
+	}
+
+	if _, err := store.Add(deadletter.FailedRecord{ImportID: "import-2", AccountID: "account-b", LineNumber: 3}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	got, err := store.Get(first.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.RawRow != "25/09/01,08:00" || got.Record.CardNumber != "1234" {
+		t.Errorf("Get() returned unexpected record: %+v", got)
+	}
+
+	list, err := store.List(deadletter.Filter{AccountID: "account-a"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ID != first.ID {
+		t.Errorf("Expected only account-a record, got %+v", list)
+	}
+
+	all, _ := store.List(deadletter.Filter{})
+	if len(all) != 2 {
+		t.Errorf("Expected 2 records, got %d", len(all))
+	}
+
+	limited, _ := store.List(deadletter.Filter{Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("Expected limit to apply, got %d", len(limited))
+	}
+
+	got.ReprocessAttempts = 1
+	if err := store.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	updated, _ := store.Get(first.ID)
+	if updated.ReprocessAttempts != 1 {
+		t.Errorf("Expected updated reprocess attempts, got %d", updated.ReprocessAttempts)
+	}
+
+	if err := store.Delete(first.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(first.ID); err == nil {
+		t.Error("Expected error getting deleted record")
+	}
+	if err := store.Delete(first.ID); err == nil {
+		t.Error("Expected error deleting missing record")
+	}
+	if err := store.Update(deadletter.FailedRecord{ID: "missing"}); err == nil {
+		t.Error("Expected error updating missing record")
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testDeadLetterStore(t, deadletter.NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := deadletter.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	testDeadLetterStore(t, store)
+}
+
+func TestNewFileStore_EmptyDir(t *testing.T) {
+	if _, err := deadletter.NewFileStore(""); err == nil {
+		t.Error("Expected error for empty directory")
+	}
+}
+
+func TestFileStore_SurvivesReopen(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dead-letters")
+	store, _ := deadletter.NewFileStore(dir)
+	rec, _ := store.Add(deadletter.FailedRecord{AccountID: "account-a", CreatedAt: time.Now()})
+
+	reopened, _ := deadletter.NewFileStore(dir)
+	if _, err := reopened.Get(rec.ID); err != nil {
+		t.Errorf("Expected record to survive reopen: %v", err)
+	}
+
+	// Failed records hold card numbers, so only the owner may read them
+	for path, want := range map[string]os.FileMode{dir: 0o700, filepath.Join(dir, rec.ID+".json"): 0o600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s mode = %v, want %v", path, info.Mode().Perm(), want)
+		}
+	}
+}
+
+func TestFailedRecords_ListAndReprocess(t *testing.T) {
+	dbDown := true
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			if dbDown {
+				return status.Error(codes.Unavailable, "db_service down")
+			}
+			return nil
+		},
+	}
+
+	store := deadletter.NewMemoryStore()
+	service := handler.NewDataProcessorServiceWithOptions(mockDB,
+		handler.WithRetryPolicy(fastRetryPolicy(t, 2)),
+		handler.WithDeadLetterStore(store),
+	)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   retryTestCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.ImportId == "" {
+		t.Fatal("Expected import ID in response")
+	}
+	if resp.Stats.ErrorRecords != 2 {
+		t.Fatalf("Expected 2 error records, got %d", resp.Stats.ErrorRecords)
+	}
+
+	listResp, err := service.ListFailedRecords(context.Background(), &pb.ListFailedRecordsRequest{
+		AccountId: "test-account",
+		ImportId:  resp.ImportId,
+	})
+	if err != nil {
+		t.Fatalf("ListFailedRecords() error = %v", err)
+	}
+	if listResp.TotalCount != 2 || len(listResp.Records) != 2 {
+		t.Fatalf("Expected 2 failed records, got %d", listResp.TotalCount)
+	}
+
+	first := listResp.Records[0]
+	if first.LineNumber != 2 {
+		t.Errorf("Expected line number 2, got %d", first.LineNumber)
+	}
+	if first.RawRow == "" {
+		t.Error("Expected raw CSV row to be kept")
+	}
+	if first.Record.GetCardNumber() != "********12345678" {
+		t.Errorf("Expected parsed record to be kept, got %+v", first.Record)
+	}
+	if first.Stage != deadletter.StageSave || first.ErrorClass != "transient" {
+		t.Errorf("Unexpected stage/class: %s/%s", first.Stage, first.ErrorClass)
+	}
+
+	// Other accounts see nothing
+	otherResp, _ := service.ListFailedRecords(context.Background(), &pb.ListFailedRecordsRequest{AccountId: "other-account"})
+	if otherResp.TotalCount != 0 {
+		t.Errorf("Expected no records for other account, got %d", otherResp.TotalCount)
+	}
+
+	// db_service comes back; replay only the first failure
+	dbDown = false
+	reResp, err := service.ReprocessFailedRecords(context.Background(), &pb.ReprocessFailedRecordsRequest{
+		AccountId: "test-account",
+		Ids:       []string{first.Id},
+	})
+	if err != nil {
+		t.Fatalf("ReprocessFailedRecords() error = %v", err)
+	}
+	if reResp.Stats.SavedRecords != 1 {
+		t.Errorf("Expected 1 saved record, got %d", reResp.Stats.SavedRecords)
+	}
+	if reResp.RemainingCount != 1 {
+		t.Errorf("Expected 1 remaining record, got %d", reResp.RemainingCount)
+	}
+
+	// Replay the rest of the import
+	reResp, _ = service.ReprocessFailedRecords(context.Background(), &pb.ReprocessFailedRecordsRequest{
+		AccountId: "test-account",
+		ImportId:  resp.ImportId,
+	})
+	if !reResp.Success || reResp.RemainingCount != 0 {
+		t.Errorf("Expected all records replayed, got remaining %d", reResp.RemainingCount)
+	}
+}
+
+func TestReprocessFailedRecords_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbDown := true
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			if dbDown {
+				return errors.New("rejected")
+			}
+			// The caller goes away after the first record is replayed
+			cancel()
+			return nil
+		},
+	}
+
+	store := deadletter.NewMemoryStore()
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithDeadLetterStore(store))
+	service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   retryTestCSV,
+		AccountId: "test-account",
+	})
+
+	dbDown = false
+	resp, err := service.ReprocessFailedRecords(ctx, &pb.ReprocessFailedRecordsRequest{AccountId: "test-account"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Success || resp.Stats.SavedRecords != 1 || resp.Stats.ErrorRecords != 1 {
+		t.Errorf("Expected a partial replay to fail with 1 saved and 1 left, got success=%v %+v", resp.Success, resp.Stats)
+	}
+	if resp.RemainingCount != 1 {
+		t.Errorf("Expected 1 remaining record, got %d", resp.RemainingCount)
+	}
+}
+
+func TestReprocessFailedRecords_StillFailing(t *testing.T) {
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			return errors.New("rejected")
+		},
+	}
+
+	store := deadletter.NewMemoryStore()
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithDeadLetterStore(store))
+
+	service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   retryTestCSV,
+		AccountId: "test-account",
+	})
+
+	resp, err := service.ReprocessFailedRecords(context.Background(), &pb.ReprocessFailedRecordsRequest{AccountId: "test-account"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Success {
+		t.Error("Expected Success to be false")
+	}
+	if resp.RemainingCount != 2 {
+		t.Errorf("Expected 2 remaining records, got %d", resp.RemainingCount)
+	}
+
+	records, _ := store.List(deadletter.Filter{AccountID: "test-account"})
+	for _, rec := range records {
+		if rec.ReprocessAttempts != 1 {
+			t.Errorf("Expected reprocess attempts to be recorded, got %d", rec.ReprocessAttempts)
+		}
+	}
+}
+
+func TestFailedRecords_RequestValidation(t *testing.T) {
+	service := handler.NewDataProcessorService(nil)
+
+	if _, err := service.ListFailedRecords(context.Background(), nil); err == nil {
+		t.Error("Expected error for nil request")
+	}
+	if _, err := service.ListFailedRecords(context.Background(), &pb.ListFailedRecordsRequest{}); err == nil {
+		t.Error("Expected error for missing account_id")
+	}
+	if _, err := service.ReprocessFailedRecords(context.Background(), nil); err == nil {
+		t.Error("Expected error for nil request")
+	}
+
+	noStore := handler.NewDataProcessorServiceWithOptions(nil, handler.WithDeadLetterStore(nil))
+	_, err := noStore.ReprocessFailedRecords(context.Background(), &pb.ReprocessFailedRecordsRequest{AccountId: "test-account"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without store, got %v", err)
+	}
+
+	// No store is kept unless one is configured
+	_, err = service.ListFailedRecords(context.Background(), &pb.ListFailedRecordsRequest{AccountId: "test-account"})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition from the default service, got %v", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,160 @@
+package unit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+func dedupBaseRecord() parser.ActualETCRecord {
+	return parser.ActualETCRecord{
+		EntryDate:  "25/09/01",
+		EntryTime:  "08:00",
+		ExitDate:   "25/09/01",
+		ExitTime:   "09:00",
+		EntryIC:    "東京",
+		ExitIC:     "横浜",
+		ETCAmount:  1200,
+		CardNumber: "********12345678",
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	if s, err := dedup.ParseStrategy(""); err != nil || s != dedup.DefaultStrategy {
+		t.Errorf("Expected default strategy, got %q (%v)", s, err)
+	}
+	if _, err := dedup.ParseStrategy("bogus"); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+	for _, name := range []string{"exact_row", "card_exit_amount", "ignore_status"} {
+		if _, err := dedup.ParseStrategy(name); err != nil {
+			t.Errorf("Unexpected error for %s: %v", name, err)
+		}
+	}
+}
+
+func TestPolicy_Key(t *testing.T) {
+	base := dedupBaseRecord()
+
+	statusChanged := base
+	statusChanged.Notes = "確定"
+
+	otherCard := base
+	otherCard.CardNumber = "********87654321"
+
+	otherEntry := base
+	otherEntry.EntryTime = "08:05"
+
+	tests := []struct {
+		strategy string
+		other    parser.ActualETCRecord
+		wantSame bool
+	}{
+		{"exact_row", base, true},
+		{"exact_row", statusChanged, false},
+		{"ignore_status", statusChanged, true},
+		{"ignore_status", otherEntry, false},
+		{"card_exit_amount", otherEntry, true},
+		{"card_exit_amount", otherCard, false},
+	}
+
+	for _, tt := range tests {
+		policy, err := dedup.NewPolicy(tt.strategy)
+		if err != nil {
+			t.Fatalf("NewPolicy(%s) error = %v", tt.strategy, err)
+		}
+		same := policy.Key(base) == policy.Key(tt.other)
+		if same != tt.wantSame {
+			t.Errorf("%s: same=%v, want %v", tt.strategy, same, tt.wantSame)
+		}
+	}
+}
+
+func TestTracker_Observe(t *testing.T) {
+	policy, _ := dedup.NewPolicy("exact_row")
+	tracker := dedup.NewTracker(policy)
+
+	if _, dup := tracker.Observe(dedupBaseRecord(), 0); dup {
+		t.Error("First record must not be a duplicate")
+	}
+	first, dup := tracker.Observe(dedupBaseRecord(), 1)
+	if !dup || first != 0 {
+		t.Errorf("Expected duplicate of index 0, got %d/%v", first, dup)
+	}
+}
+
+func TestPolicySet_For(t *testing.T) {
+	set, err := dedup.NewPolicySet("exact_row", map[string]string{"acct-b": "ignore_status"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if set.For("acct-a").Strategy != dedup.StrategyExactRow {
+		t.Errorf("Expected default strategy for acct-a, got %s", set.For("acct-a").Strategy)
+	}
+	if set.For("acct-b").Strategy != dedup.StrategyIgnoreStatus {
+		t.Errorf("Expected override for acct-b, got %s", set.For("acct-b").Strategy)
+	}
+
+	if _, err := dedup.NewPolicySet("bogus", nil); err == nil {
+		t.Error("Expected error for unknown default strategy")
+	}
+	if _, err := dedup.NewPolicySet("", map[string]string{"acct": "bogus"}); err == nil {
+		t.Error("Expected error for unknown account strategy")
+	}
+}
+
+// Validation and processing must agree on which rows are duplicates
+func TestDuplicatePolicy_ValidateAndProcessAgree(t *testing.T) {
+	// Same card, exit and amount; different entry time and notes
+	csvData := `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:05,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,確定
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********87654321,`
+
+	tests := []struct {
+		strategy string
+		wantDups int32
+	}{
+		{"card_exit_amount", 1},
+		{"exact_row", 0},
+		{"ignore_status", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			set, _ := dedup.NewPolicySet("exact_row", map[string]string{"test-account": tt.strategy})
+			service := handler.NewDataProcessorServiceWithOptions(&mockDBClient{}, handler.WithDuplicatePolicies(set))
+
+			vResp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+				CsvData:   csvData,
+				AccountId: "test-account",
+			})
+			if err != nil {
+				t.Fatalf("ValidateCSVData() error = %v", err)
+			}
+
+			pResp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+				CsvData:        csvData,
+				AccountId:      "test-account",
+				SkipDuplicates: true,
+			})
+			if err != nil {
+				t.Fatalf("ProcessCSVData() error = %v", err)
+			}
+
+			if vResp.DuplicateCount != tt.wantDups {
+				t.Errorf("Validate duplicates = %d, want %d", vResp.DuplicateCount, tt.wantDups)
+			}
+			if pResp.Stats.SkippedRecords != vResp.DuplicateCount {
+				t.Errorf("Process skipped %d, validate reported %d", pResp.Stats.SkippedRecords, vResp.DuplicateCount)
+			}
+			if vResp.DuplicateStrategy != tt.strategy || pResp.DuplicateStrategy != tt.strategy {
+				t.Errorf("Expected strategy %s reported, got %s/%s", tt.strategy, vResp.DuplicateStrategy, pResp.DuplicateStrategy)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"golang.org/x/text/encoding/unicode"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func utf16Bytes(t *testing.T, s string, order unicode.Endianness, bom unicode.BOMPolicy) []byte {
+	t.Helper()
+	encoded, err := unicode.UTF16(order, bom).NewEncoder().String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(encoded)
+}
+
+func TestEncoding_DetectAndDecode(t *testing.T) {
+	const text = "利用年月日,東京,横浜"
+
+	tests := []struct {
+		name string
+		data []byte
+		want parser.Encoding
+	}{
+		{"shift_jis", []byte(shiftJIS(t, text)), parser.EncodingShiftJIS},
+		{"utf8", []byte(text), parser.EncodingUTF8},
+		{"utf8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text...), parser.EncodingUTF8},
+		{"utf16le bom", utf16Bytes(t, text, unicode.LittleEndian, unicode.UseBOM), parser.EncodingUTF16},
+		{"utf16be bom", utf16Bytes(t, text, unicode.BigEndian, unicode.UseBOM), parser.EncodingUTF16},
+		{"utf16le ascii", utf16Bytes(t, "25/09/01,08:00,1200", unicode.LittleEndian, unicode.IgnoreBOM), parser.EncodingUTF16},
+		{"utf16be ascii", utf16Bytes(t, "25/09/01,08:00,1200", unicode.BigEndian, unicode.IgnoreBOM), parser.EncodingUTF16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parser.DetectEncoding(tt.data); got != tt.want {
+				t.Errorf("DetectEncoding() = %s, want %s", got, tt.want)
+			}
+
+			decoded, err := parser.Decode(tt.data, parser.EncodingAuto)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if decoded != text && decoded != "25/09/01,08:00,1200" {
+				t.Errorf("Decode() = %q", decoded)
+			}
+
+			// Naming the detected encoding decodes the same way
+			explicit, err := parser.Decode(tt.data, tt.want)
+			if err != nil || explicit != decoded {
+				t.Errorf("Decode(%s) = %q, %v", tt.want, explicit, err)
+			}
+		})
+	}
+}
+
+func TestEncoding_DecodeErrors(t *testing.T) {
+	sjis := []byte(shiftJIS(t, "東京"))
+	if _, err := parser.Decode(sjis, parser.EncodingUTF8); err == nil {
+		t.Error("Expected error decoding Shift-JIS as UTF-8")
+	}
+	if _, err := parser.Decode([]byte{0xFF, 0xFE, 0x41}, parser.EncodingUTF16); err == nil {
+		t.Error("Expected error for odd-length UTF-16")
+	}
+	if _, err := parser.Decode([]byte("x"), parser.Encoding(99)); err == nil {
+		t.Error("Expected error for unknown encoding")
+	}
+}
+
+func TestProcessCSVData_CsvBytes(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(mockDB)
+
+	// The scraper forwards the issuer's Shift-JIS file unchanged
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvBytes:  []byte(shiftJIS(t, retryTestCSV)),
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+	if resp.Stats.TotalRecords != 2 || resp.Stats.SavedRecords != 2 {
+		t.Errorf("Expected both records saved, got %+v", resp.Stats)
+	}
+
+	validate, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvBytes:  utf16Bytes(t, retryTestCSV, unicode.LittleEndian, unicode.UseBOM),
+		Encoding:  pb.Encoding_ENCODING_UTF16,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("ValidateCSVData() error = %v", err)
+	}
+	if validate.TotalRecords != 2 {
+		t.Errorf("Expected 2 records from UTF-16 input, got %d", validate.TotalRecords)
+	}
+}
+
+func TestProcessCSVData_CsvBytesErrors(t *testing.T) {
+	service := handler.NewDataProcessorServiceWithOptions(&mockDBClient{})
+
+	tests := map[string]*pb.ProcessCSVDataRequest{
+		"both payloads": {CsvData: retryTestCSV, CsvBytes: []byte(retryTestCSV), AccountId: "test-account"},
+		"wrong encoding": {
+			CsvBytes:  []byte(shiftJIS(t, retryTestCSV)),
+			Encoding:  pb.Encoding_ENCODING_UTF8,
+			AccountId: "test-account",
+		},
+		"too short": {CsvBytes: []byte("a,b"), AccountId: "test-account"},
+	}
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := service.ProcessCSVData(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseFile_DetectsEncoding(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sjis.csv": shiftJIS(t, retryTestCSV),
+		"utf8.csv": retryTestCSV,
+		"bom.csv":  "\xEF\xBB\xBF" + retryTestCSV,
+	}
+
+	p := parser.NewETCCSVParser()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		writeFile(t, path, content)
+
+		records, err := p.ParseFile(path)
+		if err != nil {
+			t.Fatalf("%s: ParseFile() error = %v", name, err)
+		}
+		if len(records) != 2 || records[0].Format != parser.FormatHeader || records[0].ExitIC != "横浜" {
+			t.Errorf("%s: expected 2 header-mapped records, got %+v", name, records)
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,90 @@
+package unit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/envconfig"
+)
+
+type envTestLimits struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+}
+
+type envTestKey struct {
+	Name     string   `yaml:"name"`
+	Accounts []string `yaml:"accounts"`
+}
+
+type envTestConfig struct {
+	Port       int                      `yaml:"port"`
+	LogLevel   string                   `yaml:"log_level"`
+	Enabled    bool                     `yaml:"enabled"`
+	MaxBytes   int64                    `yaml:"max_bytes"`
+	Extensions []string                 `yaml:"extensions"`
+	Limits     envTestLimits            `yaml:"limits"`
+	Accounts   map[string]envTestLimits `yaml:"accounts"`
+	Keys       []envTestKey             `yaml:"keys"`
+	Internal   string                   `yaml:"-"`
+}
+
+func TestEnvConfig_Apply(t *testing.T) {
+	cfg := envTestConfig{Port: 50051, LogLevel: "info", Extensions: []string{".csv"}, Limits: envTestLimits{Burst: 5}}
+	err := envconfig.Apply(&cfg, "ETC_TEST", []string{
+		"PATH=/usr/bin",
+		"ETC_TEST_PORT=6000",
+		"ETC_TEST_ENABLED=true",
+		"ETC_TEST_MAX_BYTES=1048576",
+		"ETC_TEST_EXTENSIONS=.csv, .zip,",
+		"ETC_TEST_LIMITS_REQUESTS_PER_SECOND=2.5",
+		`ETC_TEST_ACCOUNTS={"acct-1": {"burst": 9}}`,
+		"ETC_TEST_KEYS=[{name: billing, accounts: [acct-1, acct-2]}]",
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := envTestConfig{
+		Port:       6000,
+		LogLevel:   "info",
+		Enabled:    true,
+		MaxBytes:   1 << 20,
+		Extensions: []string{".csv", ".zip"},
+		Limits:     envTestLimits{RequestsPerSecond: 2.5, Burst: 5},
+		Accounts:   map[string]envTestLimits{"acct-1": {Burst: 9}},
+		Keys:       []envTestKey{{Name: "billing", Accounts: []string{"acct-1", "acct-2"}}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Apply() = %+v, want %+v", cfg, want)
+	}
+
+	// A list of strings may also be given as YAML
+	if err := envconfig.Apply(&cfg, "ETC_TEST", []string{`ETC_TEST_EXTENSIONS=[".tsv"]`}); err != nil || !reflect.DeepEqual(cfg.Extensions, []string{".tsv"}) {
+		t.Errorf("Apply() = %v, %v", cfg.Extensions, err)
+	}
+}
+
+func TestEnvConfig_Errors(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"ETC_TEST_PORT=abc", `invalid ETC_TEST_PORT: not an integer: "abc"`},
+		{"ETC_TEST_ENABLED=maybe", "invalid ETC_TEST_ENABLED"},
+		{"ETC_TEST_LIMITS_BURST=1.5", "invalid ETC_TEST_LIMITS_BURST"},
+		{"ETC_TEST_ACCOUNTS=[1, 2]", "invalid ETC_TEST_ACCOUNTS"},
+		{"ETC_TEST_PROT=6000", "unknown environment variable ETC_TEST_PROT"},
+		{"ETC_TEST_LIMITS=x", "unknown environment variable ETC_TEST_LIMITS"},
+		{"ETC_TEST_INTERNAL=x", "unknown environment variable ETC_TEST_INTERNAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			var cfg envTestConfig
+			err := envconfig.Apply(&cfg, "ETC_TEST", []string{tt.env})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
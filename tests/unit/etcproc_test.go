@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/etcproc"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+)
+
+// positionalCSV has no header row and all 15 positional columns
+const positionalCSV = `25/09/01,08:00,25/09/01,09:00,東京,横浜,首都高,1200,1500,-300,0,2,1234,********12345678,テスト1
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,東名,2500,3000,-500,0,2,1234,********87654321,`
+
+// runEtcproc runs the CLI with stdin and returns the exit code and output
+func runEtcproc(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := etcproc.Run(args, strings.NewReader(stdin), &stdout, &stderr, nil)
+	return code, stdout.String(), stderr.String()
+}
+
+// writeTempCSV writes data to a file in a temporary directory
+func writeTempCSV(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEtcproc_Usage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{nil, etcproc.ExitUsage},
+		{[]string{"help"}, etcproc.ExitOK},
+		{[]string{"bogus"}, etcproc.ExitUsage},
+		{[]string{"inspect"}, etcproc.ExitUsage},
+		{[]string{"inspect", "a.csv", "b.csv"}, etcproc.ExitUsage},
+		{[]string{"inspect", "-h"}, etcproc.ExitOK},
+		{[]string{"inspect", "-format", "xml", "-"}, etcproc.ExitUsage},
+		{[]string{"convert", "-encoding", "latin1", "-"}, etcproc.ExitUsage},
+		{[]string{"import", "-out", "x.jsonl", "-"}, etcproc.ExitUsage},
+		{[]string{"inspect", filepath.Join(t.TempDir(), "missing.csv")}, etcproc.ExitError},
+	}
+	for _, tt := range tests {
+		if code, _, stderr := runEtcproc(t, positionalCSV, tt.args...); code != tt.want {
+			t.Errorf("etcproc %v = %d, want %d (%s)", tt.args, code, tt.want, stderr)
+		}
+	}
+}
+
+func TestEtcproc_Inspect(t *testing.T) {
+	code, out, stderr := runEtcproc(t, positionalCSV, "inspect", "-")
+	if code != etcproc.ExitOK {
+		t.Fatalf("inspect = %d: %s", code, stderr)
+	}
+	if strings.Contains(out, "missing:") {
+		t.Errorf("Expected every positional column to be present:\n%s", out)
+	}
+	for _, want := range []string{"encoding:  utf8", "format:    positional", "rows:      2 (2 records, 0 skipped)", "card_number       13"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	code, out, _ = runEtcproc(t, rulesCSV, "inspect", "-format", "json", "-")
+	if code != etcproc.ExitOK {
+		t.Fatalf("inspect -format json = %d", code)
+	}
+	var got struct {
+		Format  string `json:"format"`
+		Records int    `json:"records"`
+		Columns []struct {
+			Field  string `json:"field"`
+			Index  int    `json:"index"`
+			Header string `json:"header"`
+		} `json:"columns"`
+		Missing []string `json:"missing"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if got.Format != parser.FormatHeader || got.Records != 3 {
+		t.Errorf("Unexpected inspection: %+v", got)
+	}
+	columns := map[string]string{}
+	for _, c := range got.Columns {
+		columns[c.Field] = c.Header
+	}
+	if columns["etc_amount"] != "通行料金" || columns["exit_ic"] != "利用ＩＣ（至）" || columns["normal_amount"] != "割引前料金" {
+		t.Errorf("Unexpected column mapping: %v", columns)
+	}
+	if strings.Join(got.Missing, ",") != "route_info,mileage" {
+		t.Errorf("Unexpected missing fields: %v", got.Missing)
+	}
+}
+
+func TestEtcproc_Validate(t *testing.T) {
+	rulesFile := writeTempCSV(t, "rules.yaml", testRulesYAML)
+
+	code, out, stderr := runEtcproc(t, positionalCSV, "validate", "-")
+	if code != etcproc.ExitOK || !strings.HasPrefix(out, "valid: 2 records, 0 errors") {
+		t.Errorf("validate = %d: %s%s", code, out, stderr)
+	}
+
+	code, out, _ = runEtcproc(t, rulesCSV, "validate", "-rules", rulesFile, "-account", "test-account", "-")
+	if code != etcproc.ExitInvalid {
+		t.Errorf("Expected invalid data to exit %d, got %d", etcproc.ExitInvalid, code)
+	}
+	for _, want := range []string{"LINE", "3     ERROR", "amount_non_negative", "WARNING", "class_known", "invalid: 3 records, 1 errors, 1 warnings", "rule set strict"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	code, out, _ = runEtcproc(t, rulesCSV, "validate", "-rules", rulesFile, "-account", "relaxed-account", "-format", "json", "-")
+	var resp struct {
+		IsValid bool   `json:"isValid"`
+		RuleSet string `json:"ruleSet"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, out)
+	}
+	if code != etcproc.ExitOK || !resp.IsValid || resp.RuleSet != "relaxed" {
+		t.Errorf("validate -format json = %d, %+v", code, resp)
+	}
+
+	if code, _, _ := runEtcproc(t, positionalCSV, "validate", "-rules", filepath.Join(t.TempDir(), "missing.yaml"), "-"); code != etcproc.ExitError {
+		t.Errorf("Expected a missing rules file to exit %d, got %d", etcproc.ExitError, code)
+	}
+}
+
+func TestEtcproc_Convert(t *testing.T) {
+	path := writeTempCSV(t, "sept.csv", positionalCSV)
+
+	code, out, _ := runEtcproc(t, "", "convert", path)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if code != etcproc.ExitOK || len(lines) != 3 {
+		t.Fatalf("convert = %d:\n%s", code, out)
+	}
+	if lines[0] != "line_number,"+strings.Join(parser.Fields, ",") {
+		t.Errorf("Unexpected header: %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "1,25/09/01,08:00,25/09/01,09:00,東京,横浜,首都高,1200,1500,-300,0,2,1234,********12345678,テスト1") {
+		t.Errorf("Unexpected row: %s", lines[1])
+	}
+
+	output := filepath.Join(t.TempDir(), "out.jsonl")
+	if code, _, stderr := runEtcproc(t, "", "convert", "-to", "jsonl", "-o", output, path); code != etcproc.ExitOK {
+		t.Fatalf("convert -to jsonl = %d: %s", code, stderr)
+	}
+	data, _ := os.ReadFile(output)
+	var first map[string]interface{}
+	if err := json.Unmarshal(bytes.SplitN(data, []byte("\n"), 2)[0], &first); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if first["exit_ic"] != "横浜" || first["etc_amount"] != float64(1200) || first["line_number"] != float64(1) {
+		t.Errorf("Unexpected record: %v", first)
+	}
+}
+
+func TestEtcproc_Import(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "saved.jsonl")
+
+	code, stdout, stderr := runEtcproc(t, positionalCSV, "import", "-account", "acct-1", "-out", out, "-")
+	if code != etcproc.ExitOK || !strings.Contains(stdout, "saved 2") {
+		t.Fatalf("import = %d: %s%s", code, stdout, stderr)
+	}
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	saved := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); saved++ {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record["account_id"] != "acct-1" {
+			t.Errorf("Unexpected saved record: %s", scanner.Text())
+		}
+	}
+	if saved != 2 {
+		t.Errorf("Expected 2 saved records, got %d", saved)
+	}
+
+	// Records rejected by the rules fail the import
+	rulesFile := writeTempCSV(t, "rules.yaml", testRulesYAML)
+	if code, stdout, _ := runEtcproc(t, rulesCSV, "import", "-account", "test-account", "-rules", rulesFile, "-out", out, "-"); code != etcproc.ExitInvalid || !strings.Contains(stdout, "failed 1") {
+		t.Errorf("Expected a failed record to exit %d, got %d: %s", etcproc.ExitInvalid, code, stdout)
+	}
+
+	// Without a DB client nothing can be imported
+	if code, _, stderr := runEtcproc(t, positionalCSV, "import", "-account", "acct-1", "-"); code != etcproc.ExitError || !strings.Contains(stderr, "-out") {
+		t.Errorf("Expected an import without a DB client to exit %d, got %d: %s", etcproc.ExitError, code, stderr)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+)
+
+func getBody(t *testing.T, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+// checkGatewayRoutes exercises the REST routes and bundled documents
+func checkGatewayRoutes(t *testing.T, baseURL string) {
+	t.Helper()
+
+	resp, body := getBody(t, baseURL+"/v1/health")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"status":"healthy"`) {
+		t.Errorf("Unexpected health response: %d %s", resp.StatusCode, body)
+	}
+
+	validate, err := http.Post(baseURL+"/v1/validate", "application/json", strings.NewReader(`{"csv_data": "`+strings.ReplaceAll(retryTestCSV, "\n", `\n`)+`", "account_id": "test-account"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer validate.Body.Close()
+	var result struct {
+		TotalRecords int `json:"totalRecords"`
+	}
+	if err := json.NewDecoder(validate.Body).Decode(&result); err != nil || validate.StatusCode != http.StatusOK || result.TotalRecords != 2 {
+		t.Errorf("Unexpected validate response: %d %+v %v", validate.StatusCode, result, err)
+	}
+
+	// gRPC errors map to HTTP status codes
+	bad, err := http.Post(baseURL+"/v1/process/data", "application/json", strings.NewReader(`{"csv_data": "x"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad.Body.Close()
+	if bad.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for missing account, got %d", bad.StatusCode)
+	}
+
+	resp, body = getBody(t, baseURL+"/swagger.json")
+	if resp.Header.Get("Content-Type") != "application/json" || !strings.Contains(body, `"/v1/process/file"`) {
+		t.Errorf("Unexpected swagger document: %s", resp.Header.Get("Content-Type"))
+	}
+
+	resp, body = getBody(t, baseURL+"/docs/")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "swagger.json") {
+		t.Errorf("Unexpected explorer page: %d", resp.StatusCode)
+	}
+}
+
+func TestGateway_InProcess(t *testing.T) {
+	service := handler.NewDataProcessorServiceWithOptions(&mockDBClient{})
+	gw, err := gateway.New(context.Background(), gateway.Options{Mode: gateway.ModeInProcess, Server: service})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+	checkGatewayRoutes(t, srv.URL)
+}
+
+func TestGateway_Proxy(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterDataProcessorServiceServer(grpcServer, handler.NewDataProcessorServiceWithOptions(&mockDBClient{}))
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw, err := gateway.New(ctx, gateway.Options{Mode: gateway.ModeProxy, Endpoint: lis.Addr().String()})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- gw.Serve(httpLis) }()
+
+	checkGatewayRoutes(t, "http://"+httpLis.Addr().String())
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), time.Second)
+	defer cancelShutdown()
+	if err := gw.Shutdown(shutdownCtx); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected Serve to return nil after Shutdown, got %v", err)
+	}
+}
+
+func TestGateway_Options(t *testing.T) {
+	if _, err := gateway.New(context.Background(), gateway.Options{Mode: gateway.ModeInProcess}); err == nil {
+		t.Error("Expected error without a server")
+	}
+	if _, err := gateway.New(context.Background(), gateway.Options{Mode: gateway.ModeProxy}); err == nil {
+		t.Error("Expected error without an endpoint")
+	}
+	if _, err := gateway.ParseMode("sideways"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+	if mode, _ := gateway.ParseMode(""); mode != gateway.ModeInProcess {
+		t.Errorf("Expected in_process default, got %s", mode)
+	}
+}
\ No newline at end of file
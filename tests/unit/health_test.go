@@ -0,0 +1,256 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/health"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/inbox"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// pingDBClient is a DB client whose Ping fails while down is set
+type pingDBClient struct {
+	mockDBClient
+	down atomic.Bool
+}
+
+func (c *pingDBClient) Ping(ctx context.Context) error {
+	if c.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// blockingDBClient holds every save until release is closed
+type blockingDBClient struct {
+	release chan struct{}
+}
+
+func (c *blockingDBClient) SaveETCData(data interface{}) error {
+	<-c.release
+	return nil
+}
+
+// healthClient serves the monitor's health service and returns a client for it
+func healthClient(t *testing.T, monitor *health.Monitor) healthpb.HealthClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	monitor.Register(server)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return healthpb.NewHealthClient(conn)
+}
+
+func servingStatus(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("Check(%q) error = %v", service, err)
+	}
+	return resp.Status
+}
+
+func TestHealthCheck_Details(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+	resp, err := service.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if resp.Status != "healthy" {
+		t.Errorf("Expected healthy without a monitor, got %s", resp.Status)
+	}
+	for _, key := range []string{"service", "uptime", "uptime_seconds", "started_at", "in_flight_jobs", "go_version"} {
+		if resp.Details[key] == "" {
+			t.Errorf("Missing detail %q in %v", key, resp.Details)
+		}
+	}
+	if resp.Details["uptime"] == "running" {
+		t.Error("Expected a real uptime")
+	}
+	if resp.Details["in_flight_jobs"] != "0" {
+		t.Errorf("Expected no jobs in flight, got %s", resp.Details["in_flight_jobs"])
+	}
+}
+
+func TestHealthCheck_InFlightJobs(t *testing.T) {
+	db := &blockingDBClient{release: make(chan struct{})}
+	service := handler.NewDataProcessorService(db)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "test-account"})
+		done <- err
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for service.InFlightJobs() != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	resp, _ := service.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	if resp.Details["in_flight_jobs"] != "1" {
+		t.Errorf("Expected 1 job in flight, got %s", resp.Details["in_flight_jobs"])
+	}
+
+	close(db.release)
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+	if n := service.InFlightJobs(); n != 0 {
+		t.Errorf("Expected no jobs in flight after the import, got %d", n)
+	}
+}
+
+func TestHealthMonitor_LivenessAndReadiness(t *testing.T) {
+	db := &pingDBClient{}
+	db.down.Store(true)
+
+	var service *handler.DataProcessorService
+	monitor := health.NewMonitor([]health.Dependency{
+		{Name: "database", Check: func(ctx context.Context) error { return service.PingDatabase(ctx) }},
+		{Name: "storage", Check: health.Writable(t.TempDir())},
+	}, health.Options{Services: []string{pb.DataProcessorService_ServiceDesc.ServiceName}})
+	service = handler.NewDataProcessorServiceWithOptions(db, handler.WithHealthMonitor(monitor))
+	client := healthClient(t, monitor)
+
+	// Not ready before the first probe, but live
+	if st := servingStatus(t, client, health.ReadinessService); st != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("Expected readiness NOT_SERVING before probing, got %v", st)
+	}
+	if st := servingStatus(t, client, ""); st != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected overall SERVING, got %v", st)
+	}
+
+	// An unreachable database fails readiness only
+	monitor.Probe(context.Background())
+	for _, name := range []string{health.ReadinessService, pb.DataProcessorService_ServiceDesc.ServiceName} {
+		if st := servingStatus(t, client, name); st != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected %s NOT_SERVING, got %v", name, st)
+		}
+	}
+	if st := servingStatus(t, client, health.LivenessService); st != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected liveness SERVING, got %v", st)
+	}
+	resp, _ := service.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	if resp.Status != "unhealthy" || !strings.Contains(resp.Details["dependency.database"], "connection refused") {
+		t.Errorf("Expected unhealthy with the database error, got %s %v", resp.Status, resp.Details)
+	}
+	if resp.Details["dependency.storage"] != "ok" {
+		t.Errorf("Expected storage ok, got %q", resp.Details["dependency.storage"])
+	}
+
+	// Recovery is picked up by the next probe
+	db.down.Store(false)
+	monitor.Probe(context.Background())
+	if st := servingStatus(t, client, health.ReadinessService); st != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected readiness SERVING after recovery, got %v", st)
+	}
+	resp, _ = service.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	if resp.Status != "healthy" || resp.Details["dependency.database"] != "ok" {
+		t.Errorf("Expected healthy after recovery, got %s %v", resp.Status, resp.Details)
+	}
+
+	// Shutdown takes everything out of service for good
+	monitor.Shutdown()
+	monitor.Probe(context.Background())
+	for _, name := range []string{"", health.LivenessService, health.ReadinessService} {
+		if st := servingStatus(t, client, name); st != healthpb.HealthCheckResponse_NOT_SERVING {
+			t.Errorf("Expected %q NOT_SERVING after shutdown, got %v", name, st)
+		}
+	}
+	if monitor.Ready() {
+		t.Error("Expected not ready after shutdown")
+	}
+}
+
+func TestHealthMonitor_CheckTimeoutAndPanic(t *testing.T) {
+	monitor := health.NewMonitor([]health.Dependency{
+		{Name: "slow", Check: func(ctx context.Context) error { time.Sleep(time.Second); return nil }},
+		{Name: "broken", Check: func(ctx context.Context) error { panic("boom") }},
+	}, health.Options{Timeout: 20 * time.Millisecond})
+
+	statuses := monitor.Probe(context.Background())
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+	if statuses[0].Healthy || !strings.Contains(statuses[0].Error, "timed out") {
+		t.Errorf("Expected a timeout, got %+v", statuses[0])
+	}
+	if statuses[1].Healthy || !strings.Contains(statuses[1].Error, "panicked") {
+		t.Errorf("Expected a panic to fail the check, got %+v", statuses[1])
+	}
+}
+
+func TestHealth_PingDatabaseAndWritable(t *testing.T) {
+	if err := handler.NewDataProcessorService(nil).PingDatabase(context.Background()); err == nil {
+		t.Error("Expected an error without a DB client")
+	}
+	if err := handler.NewDataProcessorService(&mockDBClient{}).PingDatabase(context.Background()); err != nil {
+		t.Errorf("Expected clients without Ping to pass, got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := health.Writable(dir)(context.Background()); err != nil {
+		t.Errorf("Writable() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected the probe file to be removed, found %d entries", len(entries))
+	}
+	if err := health.Writable(filepath.Join(dir, "missing"))(context.Background()); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestInboxWatcher_Check(t *testing.T) {
+	dir := t.TempDir()
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		return inbox.Outcome{Success: true}, nil
+	}
+	w, err := inbox.New([]inbox.Dir{{Path: dir, AccountID: "fixed-account"}}, importer, inbox.Options{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not started yet: only the directories are checked
+	if err := w.Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+
+	// A watcher that stopped scanning is reported
+	w.Scan(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	if err := w.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "not been scanned") {
+		t.Errorf("Expected a stalled watcher to fail, got %v", err)
+	}
+	w.Scan(context.Background())
+	if err := w.Check(context.Background()); err != nil {
+		t.Errorf("Check() after a scan error = %v", err)
+	}
+
+	// A missing failed directory is reported
+	os.RemoveAll(w.Dirs()[0].FailedDir)
+	if err := w.Check(context.Background()); err == nil {
+		t.Error("Expected an error for a missing failed directory")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,252 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/inbox"
+)
+
+// agedFile writes a file whose mtime is far enough in the past to be stable
+func agedFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, content)
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readSidecar(t *testing.T, path string) inbox.Result {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected sidecar %s: %v", path, err)
+	}
+	var result inbox.Result
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Invalid sidecar JSON: %v", err)
+	}
+	return result
+}
+
+func TestInbox_ImportsAndArchives(t *testing.T) {
+	dir := t.TempDir()
+	agedFile(t, filepath.Join(dir, "acct-1", "statement.csv"), "ok")
+	agedFile(t, filepath.Join(dir, "acct-2", "broken.csv"), "bad")
+	agedFile(t, filepath.Join(dir, "acct-1", "readme.txt"), "ignored")
+
+	var imported []string
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		imported = append(imported, accountID)
+		if filepath.Base(path) == "broken.csv" {
+			return inbox.Outcome{}, errors.New("parse failed")
+		}
+		return inbox.Outcome{ImportID: "imp-1", Success: true, Stats: map[string]int{"saved_records": 2}}, nil
+	}
+
+	w, err := inbox.New([]inbox.Dir{{Path: dir, AccountPattern: "{account}/*.csv"}}, importer, inbox.Options{StableFor: time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if n := w.Scan(context.Background()); n != 2 {
+		t.Fatalf("Expected 2 files handled, got %d", n)
+	}
+	if len(imported) != 2 || imported[0] != "acct-1" || imported[1] != "acct-2" {
+		t.Errorf("Unexpected accounts: %v", imported)
+	}
+
+	archived := filepath.Join(dir, "archive", "acct-1", "statement.csv")
+	if _, err := os.Stat(archived); err != nil {
+		t.Errorf("Expected archived file: %v", err)
+	}
+	result := readSidecar(t, archived+".result.json")
+	if !result.Success || result.AccountID != "acct-1" || result.ImportID != "imp-1" || result.Stats["saved_records"] != 2 {
+		t.Errorf("Unexpected archive result: %+v", result)
+	}
+
+	failed := filepath.Join(dir, "failed", "acct-2", "broken.csv")
+	result = readSidecar(t, failed+".result.json")
+	if result.Success || result.Error != "parse failed" {
+		t.Errorf("Unexpected failed result: %+v", result)
+	}
+
+	// Moved statements and their results hold card numbers
+	for path, want := range map[string]os.FileMode{
+		filepath.Join(dir, "archive"):          0o700,
+		filepath.Join(dir, "failed", "acct-2"): 0o700,
+		archived + ".result.json":              0o600,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s mode = %v, want %v", path, info.Mode().Perm(), want)
+		}
+	}
+
+	// Nothing left to import; the .txt file stays where it is
+	if n := w.Scan(context.Background()); n != 0 {
+		t.Errorf("Expected nothing on rescan, got %d", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "acct-1", "readme.txt")); err != nil {
+		t.Errorf("Non-CSV file must be left alone: %v", err)
+	}
+}
+
+func TestInbox_WaitsUntilStable(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "new.csv")
+	writeFile(t, path, "still writing")
+
+	calls := 0
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		calls++
+		return inbox.Outcome{Success: true}, nil
+	}
+
+	w, _ := inbox.New([]inbox.Dir{{Path: dir, AccountID: "fixed-account"}}, importer, inbox.Options{StableFor: 200 * time.Millisecond})
+
+	if n := w.Scan(context.Background()); n != 0 || calls != 0 {
+		t.Fatalf("Fresh file must not be imported yet, got %d", n)
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	if n := w.Scan(context.Background()); n != 1 || calls != 1 {
+		t.Errorf("Expected stable file to be imported, got %d", n)
+	}
+}
+
+func TestInbox_UnmatchedPathGoesToFailed(t *testing.T) {
+	dir := t.TempDir()
+	agedFile(t, filepath.Join(dir, "random.csv"), "x")
+
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		t.Error("Importer must not be called for unmatched paths")
+		return inbox.Outcome{}, nil
+	}
+
+	w, _ := inbox.New([]inbox.Dir{{Path: dir, AccountPattern: "etc_{account}_*.csv"}}, importer, inbox.Options{StableFor: time.Second})
+	w.Scan(context.Background())
+
+	result := readSidecar(t, filepath.Join(dir, "failed", "random.csv.result.json"))
+	if result.Error == "" {
+		t.Error("Expected pattern mismatch error in sidecar")
+	}
+}
+
+func TestInbox_PatternCapturesAccount(t *testing.T) {
+	dir := t.TempDir()
+	agedFile(t, filepath.Join(dir, "etc_acct-9_202509.csv"), "x")
+
+	var got string
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		got = accountID
+		return inbox.Outcome{Success: true}, nil
+	}
+
+	w, _ := inbox.New([]inbox.Dir{{Path: dir, AccountPattern: "etc_{account}_*.csv"}}, importer, inbox.Options{StableFor: time.Second})
+	w.Scan(context.Background())
+
+	if got != "acct-9" {
+		t.Errorf("Expected account acct-9, got %q", got)
+	}
+}
+
+func TestInbox_New_Errors(t *testing.T) {
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		return inbox.Outcome{}, nil
+	}
+
+	if _, err := inbox.New(nil, importer, inbox.Options{}); err == nil {
+		t.Error("Expected error without directories")
+	}
+	if _, err := inbox.New([]inbox.Dir{{Path: t.TempDir()}}, nil, inbox.Options{}); err == nil {
+		t.Error("Expected error without importer")
+	}
+	if _, err := inbox.New([]inbox.Dir{{Path: t.TempDir(), AccountPattern: "*.csv"}}, importer, inbox.Options{}); err == nil {
+		t.Error("Expected error when no account can be derived")
+	}
+}
+
+func TestInbox_RunStopsOnCancel(t *testing.T) {
+	dir := t.TempDir()
+	agedFile(t, filepath.Join(dir, "leftover.csv"), "x")
+
+	done := make(chan string, 1)
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		done <- path
+		return inbox.Outcome{Success: true}, nil
+	}
+
+	w, _ := inbox.New([]inbox.Dir{{Path: dir, AccountID: "fixed-account"}}, importer, inbox.Options{PollInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- w.Run(ctx) }()
+
+	// The startup scan picks up files that were already there
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected leftover file to be imported on startup")
+	}
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestInbox_UnmovableFileImportedOnce(t *testing.T) {
+	dir := t.TempDir()
+	agedFile(t, filepath.Join(dir, "a.csv"), "x")
+	agedFile(t, filepath.Join(dir, "b.csv"), "y")
+
+	calls := map[string]int{}
+	importer := func(ctx context.Context, path, accountID string) (inbox.Outcome, error) {
+		calls[filepath.Base(path)]++
+		return inbox.Outcome{Success: true}, nil
+	}
+	w, _ := inbox.New([]inbox.Dir{{Path: dir, AccountID: "fixed-account"}}, importer, inbox.Options{StableFor: time.Second})
+
+	// The archive directory can no longer be written, and a.csv cannot be
+	// renamed in place either
+	archive := filepath.Join(dir, "archive")
+	if err := os.Remove(archive); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, archive, "not a directory")
+	if err := os.Mkdir(filepath.Join(dir, "a.csv.failed"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		w.Scan(context.Background())
+	}
+	if calls["a.csv"] != 1 || calls["b.csv"] != 1 {
+		t.Errorf("Expected each file imported once, got %v", calls)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b.csv.failed")); err != nil {
+		t.Errorf("Expected b.csv set aside in place: %v", err)
+	}
+	if result := readSidecar(t, filepath.Join(dir, "b.csv.failed.result.json")); !result.Success {
+		t.Errorf("Expected the import result in the sidecar, got %+v", result)
+	}
+
+	// A changed file is a new statement and is imported again
+	agedFile(t, filepath.Join(dir, "a.csv"), "changed")
+	w.Scan(context.Background())
+	if calls["a.csv"] != 2 {
+		t.Errorf("Expected the changed file imported again, got %v", calls)
+	}
+}
\ No newline at end of file
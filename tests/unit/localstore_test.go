@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"context"
+	"encoding/binary"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/etcproc"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/localstore"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	bolt "go.etcd.io/bbolt"
+)
+
+func openLocalStore(t *testing.T, path string) *localstore.Store {
+	t.Helper()
+	store, err := localstore.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestLocalStore_SaveAndFind(t *testing.T) {
+	store := openLocalStore(t, filepath.Join(t.TempDir(), "data", "etc.db"))
+	service := handler.NewDataProcessorServiceWithOptions(store)
+
+	for _, account := range []string{"acct-1", "acct-2"} {
+		resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: positionalCSV, AccountId: account})
+		if err != nil || resp.Stats.SavedRecords != 2 {
+			t.Fatalf("ProcessCSVData(%s) = %+v, %v", account, resp, err)
+		}
+	}
+	if err := store.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+
+	records, err := store.Find(localstore.Query{AccountID: "acct-1"})
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Find(account) = %+v, %v", records, err)
+	}
+	r := records[0]
+	if r.EntryIC != "東京" || r.ExitTime != "09:00" || r.ETCAmount != 1200 || r.VehicleClass != 2 || r.Notes != "テスト1" || r.Date.Day() != 1 {
+		t.Errorf("Find(account)[0] = %+v, want the full first row", r)
+	}
+
+	tests := []struct {
+		name  string
+		query localstore.Query
+		want  int
+	}{
+		{"card", localstore.Query{CardNumber: "********87654321"}, 2},
+		{"card and account", localstore.Query{CardNumber: "********87654321", AccountID: "acct-2"}, 1},
+		{"date range", localstore.Query{From: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)}, 2},
+		{"limit", localstore.Query{Limit: 3}, 3},
+		{"no match", localstore.Query{AccountID: "acct-3"}, 0},
+	}
+	for _, tt := range tests {
+		if got, err := store.Find(tt.query); err != nil || len(got) != tt.want {
+			t.Errorf("Find(%s) = %d records, %v, want %d", tt.name, len(got), err, tt.want)
+		}
+	}
+
+	if err := store.SaveETCData(map[string]interface{}{"route": "東名"}); err == nil {
+		t.Error("SaveETCData() without account_id succeeded")
+	}
+}
+
+func TestLocalStore_Upsert(t *testing.T) {
+	store := openLocalStore(t, filepath.Join(t.TempDir(), "etc.db"))
+	data := map[string]interface{}{"account_id": "acct-1", "card_number": "********12345678", "amount": 1200}
+
+	if replaced, err := store.UpsertETCData("key-1", data); err != nil || replaced {
+		t.Fatalf("UpsertETCData() = %v, %v, want an insert", replaced, err)
+	}
+	first, _ := store.Find(localstore.Query{AccountID: "acct-1"})
+
+	data["amount"] = 1500
+	data["card_number"] = "********87654321"
+	if replaced, err := store.UpsertETCData("key-1", data); err != nil || !replaced {
+		t.Fatalf("UpsertETCData() = %v, %v, want a replacement", replaced, err)
+	}
+	if n, _ := store.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+	r, err := store.Get(first[0].ID)
+	if err != nil || r.Amount != 1500 || !r.CreatedAt.Equal(first[0].CreatedAt) {
+		t.Errorf("Get() = %+v, %v, want the replacement under the same ID", r, err)
+	}
+	if old, _ := store.Find(localstore.Query{CardNumber: "********12345678"}); len(old) != 0 {
+		t.Errorf("Find(old card) = %+v, want the old index entry removed", old)
+	}
+
+	// The same key of another account is another record
+	data["account_id"] = "acct-2"
+	if replaced, err := store.UpsertETCData("key-1", data); err != nil || replaced {
+		t.Errorf("UpsertETCData(acct-2) = %v, %v, want an insert", replaced, err)
+	}
+}
+
+func TestLocalStore_Schema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etc.db")
+	store, err := localstore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SaveETCData(map[string]interface{}{"account_id": "acct-1"})
+	store.Close()
+
+	// Records and the schema version survive reopening
+	store = openLocalStore(t, path)
+	if n, _ := store.Len(); n != 1 {
+		t.Errorf("Len() after reopening = %d, want 1", n)
+	}
+	if v, err := store.Version(); err != nil || v != localstore.SchemaVersion {
+		t.Errorf("Version() = %d, %v, want %d", v, err, localstore.SchemaVersion)
+	}
+	store.Close()
+
+	// A database written by a newer build is refused
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Update(func(tx *bolt.Tx) error {
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, 99)
+		return tx.Bucket([]byte("meta")).Put([]byte("schema_version"), v)
+	})
+	db.Close()
+	if _, err := localstore.Open(path); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Open() of a newer schema error = %v", err)
+	}
+}
+
+func TestEtcproc_ImportLocalStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etc.db")
+	cfg := writeTempCSV(t, "config.yaml", "local_store:\n  enabled: true\n  path: "+path+"\n")
+
+	code, stdout, stderr := runEtcproc(t, positionalCSV, "import", "-account", "acct-1", "-config", cfg, "-")
+	if code != etcproc.ExitOK || !strings.Contains(stdout, "saved 2") {
+		t.Fatalf("import = %d: %s%s", code, stdout, stderr)
+	}
+	store := openLocalStore(t, path)
+	if records, err := store.Find(localstore.Query{AccountID: "acct-1"}); err != nil || len(records) != 2 {
+		t.Errorf("Find() = %+v, %v, want the imported records", records, err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,233 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// logBuffer collects log output written from several goroutines
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries decodes the JSON lines written so far
+func (b *logBuffer) entries(t *testing.T) []map[string]interface{} {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// find returns the first entry with the given message
+func (b *logBuffer) find(t *testing.T, msg string) map[string]interface{} {
+	t.Helper()
+	for _, entry := range b.entries(t) {
+		if entry["msg"] == msg {
+			return entry
+		}
+	}
+	t.Fatalf("No %q line in:\n%s", msg, b.buf.String())
+	return nil
+}
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"card 1234567812345678 used", "card [REDACTED] used"},
+		{"card ********12345678 used", "card [REDACTED] used"},
+		{"card 1234-5678-1234-5678", "card [REDACTED]"},
+		{"plate 品川 300 あ 12-34 seen", "plate [REDACTED] seen"},
+		{"plate 横浜500さ1234", "plate [REDACTED]"},
+		{"request 0f3a9c1234567890123456789abcdef0", "request 0f3a9c1234567890123456789abcdef0"},
+		{"date 25/09/01 08:00 amount 1500", "date 25/09/01 08:00 amount 1500"},
+	}
+	for _, tt := range tests {
+		if got := logging.Redact(tt.in); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLogging_NewAndRedactingHandler(t *testing.T) {
+	if _, err := logging.New(&bytes.Buffer{}, "loud", "json"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, err := logging.New(&bytes.Buffer{}, "info", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+
+	var text bytes.Buffer
+	logger, _ := logging.New(&text, "warn", "logfmt")
+	logger.Info("hidden")
+	logger.Warn("shown", "account_id", "acct-1")
+	if strings.Contains(text.String(), "hidden") || !strings.Contains(text.String(), "msg=shown account_id=acct-1") {
+		t.Errorf("Unexpected logfmt output: %s", text.String())
+	}
+
+	out := &logBuffer{}
+	logger, _ = logging.New(out, "debug", "json")
+	logger.With("card_number", "1234567812345678").Info("saved card 1234567812345678",
+		"vehicle_number", "1234",
+		"error", errors.New("duplicate key for ********12345678"),
+		slog.Group("record", "card_number", "x", "note", "品川 300 あ 12-34"),
+	)
+
+	entry := out.find(t, "saved card [REDACTED]")
+	record := entry["record"].(map[string]interface{})
+	for name, got := range map[string]interface{}{
+		"card_number":    entry["card_number"],
+		"vehicle_number": entry["vehicle_number"],
+		"error":          entry["error"],
+		"record.card":    record["card_number"],
+		"record.note":    record["note"],
+	} {
+		if s, _ := got.(string); !strings.Contains(s, logging.Redacted) || strings.ContainsAny(strings.ReplaceAll(s, "duplicate key for ", ""), "0123456789") {
+			t.Errorf("Expected %s to be redacted, got %v", name, got)
+		}
+	}
+}
+
+func TestLogging_GRPCRequestIDs(t *testing.T) {
+	out := &logBuffer{}
+	logger, _ := logging.New(out, "info", "json")
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(logger)),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor(logger)),
+	)
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&mockDBClient{}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewDataProcessorServiceClient(conn)
+
+	// A valid client ID is propagated to the logs and the response header
+	ctx := metadata.AppendToOutgoingContext(context.Background(), logging.RequestIDKey, "req-42")
+	var header metadata.MD
+	if _, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"}, grpc.Header(&header)); err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+	if got := header.Get(logging.RequestIDKey); len(got) != 1 || got[0] != "req-42" {
+		t.Errorf("Expected the request ID in the response header, got %v", got)
+	}
+
+	imported := out.find(t, "import finished")
+	if imported["request_id"] != "req-42" || imported["account_id"] != "acct-1" || imported["format"] != "csv" {
+		t.Errorf("Unexpected import line: %v", imported)
+	}
+	if stats, _ := imported["stats"].(map[string]interface{}); stats["total"] != float64(2) || stats["saved"] != float64(2) {
+		t.Errorf("Expected stats on the import line, got %v", imported["stats"])
+	}
+	rpc := out.find(t, "rpc finished")
+	if rpc["request_id"] != "req-42" || rpc["grpc_code"] != "OK" || rpc["level"] != "INFO" {
+		t.Errorf("Unexpected rpc line: %v", rpc)
+	}
+
+	// Without one, or with one that could forge log fields, an ID is generated
+	header = nil
+	ctx = metadata.AppendToOutgoingContext(context.Background(), logging.RequestIDKey, "bad id level=ERROR")
+	client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: "x"}, grpc.Header(&header))
+	got := header.Get(logging.RequestIDKey)
+	if len(got) != 1 || len(got[0]) != 32 {
+		t.Errorf("Expected a generated request ID, got %v", got)
+	}
+	for _, entry := range out.entries(t) {
+		if entry["grpc_code"] == "InvalidArgument" && (entry["level"] != "WARN" || entry["request_id"] != got[0]) {
+			t.Errorf("Unexpected failed rpc line: %v", entry)
+		}
+	}
+}
+
+func TestLogging_GatewayRequestIDs(t *testing.T) {
+	out := &logBuffer{}
+	logger, _ := logging.New(out, "info", "json")
+
+	// Proxy mode forwards the ID to the gRPC server's interceptor
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor(logger)))
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&mockDBClient{}))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw, err := gateway.New(ctx, gateway.Options{Mode: gateway.ModeProxy, Endpoint: lis.Addr().String(), Logger: logger})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gw.Serve(httpLis)
+	defer gw.Shutdown(context.Background())
+
+	body := `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "acct-http"}`
+	req, _ := http.NewRequest(http.MethodPost, "http://"+httpLis.Addr().String()+"/v1/process/data", strings.NewReader(body))
+	req.Header.Set("X-Request-Id", "http-7")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Request-Id") != "http-7" {
+		t.Errorf("Expected the request ID in the response, got %q", resp.Header.Get("X-Request-Id"))
+	}
+
+	imported := out.find(t, "import finished")
+	if imported["request_id"] != "http-7" || imported["account_id"] != "acct-http" {
+		t.Errorf("Expected the gRPC import line to carry the HTTP request ID, got %v", imported)
+	}
+	access := out.find(t, "http request finished")
+	if access["request_id"] != "http-7" || access["http_status"] != float64(200) || access["http_path"] != "/v1/process/data" {
+		t.Errorf("Unexpected access line: %v", access)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,184 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/metrics"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// scrape returns the metrics text as Prometheus would see it
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := m.Registry().WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	return buf.String()
+}
+
+func expectLines(t *testing.T, text string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Missing line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestRegistry_TextFormat(t *testing.T) {
+	r := metrics.NewRegistry()
+	c := r.NewCounterVec("test_total", "A counter\nwith a newline.", "kind")
+	c.Add(2, `quote"d`)
+	c.Inc("plain")
+	h := r.NewHistogramVec("test_seconds", "A histogram.", []float64{0.1, 1}, "op")
+	h.Observe(0.05, "save")
+	h.Observe(0.5, "save")
+	h.Observe(3, "save")
+	r.NewGaugeFunc("test_gauge", "A gauge.", func() float64 { return 7 })
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	expectLines(t, buf.String(),
+		`# HELP test_total A counter\nwith a newline.`,
+		`# TYPE test_total counter`,
+		`test_total{kind="plain"} 1`,
+		`test_total{kind="quote\"d"} 2`,
+		`# TYPE test_seconds histogram`,
+		`test_seconds_bucket{op="save",le="0.1"} 1`,
+		`test_seconds_bucket{op="save",le="1"} 2`,
+		`test_seconds_bucket{op="save",le="+Inf"} 3`,
+		`test_seconds_sum{op="save"} 3.55`,
+		`test_seconds_count{op="save"} 3`,
+		`# TYPE test_gauge gauge`,
+		`test_gauge 7`,
+	)
+
+	// Families are written in name order
+	text := buf.String()
+	if strings.Index(text, "test_gauge") > strings.Index(text, "test_seconds") {
+		t.Error("Expected metrics sorted by name")
+	}
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+	resp, body := getBody(t, srv.URL)
+	if resp.Header.Get("Content-Type") != metrics.ContentType || !strings.Contains(body, "test_gauge 7") {
+		t.Errorf("Unexpected scrape: %s %s", resp.Header.Get("Content-Type"), body)
+	}
+	post, err := http.Post(srv.URL, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Body.Close()
+	if post.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", post.StatusCode)
+	}
+}
+
+func TestMetrics_RecordsAndSaves(t *testing.T) {
+	m := metrics.New()
+	db := &mockDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithMetrics(m))
+
+	req := &pb.ProcessCSVDataRequest{CsvData: septCSV + "\n" + septCSV, AccountId: "test-account", SkipDuplicates: true}
+	if _, err := service.ProcessCSVData(context.Background(), req); err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "statements.zip")
+	if err := os.WriteFile(path, buildZip(t, []zipEntry{{name: "oct.csv", data: octCSV}, {name: "empty.csv", data: ""}}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{CsvFilePath: path, AccountId: "test-account"}); err != nil {
+		t.Fatalf("ProcessCSVFile() error = %v", err)
+	}
+
+	text := scrape(t, m)
+	expectLines(t, text,
+		`etc_records_total{outcome="parsed",format="csv"} 4`,
+		`etc_records_total{outcome="saved",format="csv"} 2`,
+		`etc_records_total{outcome="skipped",format="csv"} 2`,
+		`etc_records_total{outcome="rejected",format="csv"} 0`,
+		`etc_records_total{outcome="parsed",format="zip"} 2`,
+		`etc_records_total{outcome="saved",format="zip"} 2`,
+		`etc_parse_failures_total{format="zip"} 1`,
+		`etc_db_save_duration_seconds_count{operation="save",result="ok"} 4`,
+		`etc_imports_in_flight 0`,
+	)
+}
+
+func TestMetrics_InFlightImports(t *testing.T) {
+	m := metrics.New()
+	db := &blockingDBClient{release: make(chan struct{})}
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithMetrics(m))
+
+	done := make(chan struct{})
+	go func() {
+		service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "test-account"})
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for service.InFlightJobs() != 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	expectLines(t, scrape(t, m), `etc_imports_in_flight 1`)
+
+	close(db.release)
+	<-done
+	expectLines(t, scrape(t, m), `etc_imports_in_flight 0`)
+}
+
+func TestMetrics_GRPCInterceptors(t *testing.T) {
+	m := metrics.New()
+	service := handler.NewDataProcessorServiceWithOptions(&mockDBClient{}, handler.WithMetrics(m))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(m.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(m.StreamServerInterceptor()),
+	)
+	pb.RegisterDataProcessorServiceServer(server, service)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewDataProcessorServiceClient(conn)
+
+	client.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	client.HealthCheck(context.Background(), &pb.HealthCheckRequest{})
+	client.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: "x"})
+
+	svc := pb.DataProcessorService_ServiceDesc.ServiceName
+	expectLines(t, scrape(t, m),
+		`grpc_server_started_total{grpc_service="`+svc+`",grpc_method="HealthCheck"} 2`,
+		`grpc_server_handled_total{grpc_service="`+svc+`",grpc_method="HealthCheck",grpc_code="OK"} 2`,
+		`grpc_server_handled_total{grpc_service="`+svc+`",grpc_method="ProcessCSVData",grpc_code="InvalidArgument"} 1`,
+		`grpc_server_handling_seconds_count{grpc_service="`+svc+`",grpc_method="HealthCheck"} 2`,
+	)
+
+	// A service without metrics records nothing and does not fail
+	var none *metrics.Metrics
+	none.AddRecords(metrics.OutcomeSaved, metrics.FormatCSV, 1)
+	none.ImportStarted()()
+}
\ No newline at end of file
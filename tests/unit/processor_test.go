@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/processor"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestProcessor_Process(t *testing.T) {
+	res, err := processor.Process(context.Background(), strings.NewReader(rulesCSV), processor.Options{Rules: []byte(testRulesYAML)})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(res.Records) != 3 || res.Records[0].Line != 2 || res.Records[0].CardNumber != "********12345678" || res.Records[0].ETCAmount != 1200 {
+		t.Fatalf("Records = %+v", res.Records)
+	}
+	if !res.Records[0].Valid || res.Records[1].Valid || !res.Records[2].Valid {
+		t.Errorf("Valid = %v %v %v, want the negative amount on line 3 rejected",
+			res.Records[0].Valid, res.Records[1].Valid, res.Records[2].Valid)
+	}
+	want := processor.Stats{Records: 3, Accepted: 2, Failed: 1, Warnings: 1}
+	if res.Stats != want {
+		t.Errorf("Stats = %+v, want %+v", res.Stats, want)
+	}
+	if len(res.Diagnostics) != 2 {
+		t.Fatalf("Diagnostics = %+v, want 2", res.Diagnostics)
+	}
+	d := res.Diagnostics[0]
+	if d.Line != 3 || d.Severity != processor.SeverityError || d.Code != "INVALID_AMOUNT" || d.Rule != "amount_non_negative" {
+		t.Errorf("Diagnostics[0] = %+v", d)
+	}
+	if res.Diagnostics[1].Severity != processor.SeverityWarning || res.RuleSet != "strict" || res.Encoding != processor.EncodingUTF8 {
+		t.Errorf("Diagnostics[1] = %+v, RuleSet = %q, Encoding = %v", res.Diagnostics[1], res.RuleSet, res.Encoding)
+	}
+}
+
+func TestProcessor_Save(t *testing.T) {
+	sjis, err := japanese.ShiftJIS.NewEncoder().String(positionalCSV + "\n" + strings.SplitN(positionalCSV, "\n", 2)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &mockDBClient{}
+	res, err := processor.Process(context.Background(), strings.NewReader(sjis), processor.Options{
+		AccountID:      "acct-1",
+		SkipDuplicates: true,
+		Saver:          db,
+	})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if res.Encoding != processor.EncodingShiftJIS || res.Records[0].EntryIC != "東京" {
+		t.Errorf("Encoding = %v, Records[0] = %+v", res.Encoding, res.Records[0])
+	}
+	if res.Stats.Records != 3 || res.Stats.Accepted != 2 || res.Stats.Skipped != 1 || len(db.savedData) != 2 {
+		t.Errorf("Stats = %+v, saved %d, want the repeated row skipped", res.Stats, len(db.savedData))
+	}
+	if saved, ok := db.savedData[0].(map[string]interface{}); !ok || saved["account_id"] != "acct-1" {
+		t.Errorf("saved = %#v", db.savedData[0])
+	}
+}
+
+func TestProcessor_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  processor.Options
+		want  string
+	}{
+		{"empty input", "", processor.Options{}, "empty"},
+		{"unknown duplicate strategy", positionalCSV, processor.Options{DuplicateStrategy: "nope"}, "nope"},
+		{"invalid rules", positionalCSV, processor.Options{Rules: []byte("rule_sets: [")}, "invalid rules"},
+		{"short account", positionalCSV, processor.Options{AccountID: "a"}, "account"},
+	}
+	for _, tt := range tests {
+		_, err := processor.Process(context.Background(), strings.NewReader(tt.input), tt.opts)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+	if _, err := processor.ProcessFile(context.Background(), "/nonexistent.csv", processor.Options{}); err == nil {
+		t.Error("ProcessFile() of a missing file succeeded")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,328 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fakeClock is a settable clock for the limiter
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func TestRateLimit_Limiter(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)}
+	l := ratelimit.NewWithClock(
+		ratelimit.Limits{RequestsPerSecond: 3, ConcurrentImports: 3, RecordsPerDay: 150},
+		ratelimit.Limits{RequestsPerSecond: 2, ConcurrentImports: 1, RecordsPerDay: 100},
+		map[string]ratelimit.Limits{"big": {RequestsPerSecond: 10, ConcurrentImports: 2}},
+		clock.Now,
+	)
+
+	// Request rate: the account's burst, then the global rate
+	for i := 0; i < 2; i++ {
+		if err := l.Allow(ctx, "acct-1"); err != nil {
+			t.Fatalf("Allow() #%d error = %v", i+1, err)
+		}
+	}
+	err := l.Allow(ctx, "acct-1")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected the third request to be throttled, got %v", err)
+	}
+	if retryAfter, ok := ratelimit.RetryAfter(err); !ok || retryAfter != "1" {
+		t.Errorf("RetryAfter() = %q, %v, want 1", retryAfter, ok)
+	}
+	if err := l.Allow(ctx, "big"); err != nil {
+		t.Errorf("Expected another account to have its own rate, got %v", err)
+	}
+	if err := l.Allow(ctx, "big"); status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "all accounts") {
+		t.Errorf("Expected the global rate to be exhausted, got %v", err)
+	}
+	clock.Advance(500 * time.Millisecond)
+	if err := l.Allow(ctx, "acct-1"); err != nil {
+		t.Errorf("Expected a token after 500ms, got %v", err)
+	}
+
+	// Concurrent imports
+	done, err := l.StartImport(ctx, "acct-1")
+	if err != nil {
+		t.Fatalf("StartImport() error = %v", err)
+	}
+	if _, err := l.StartImport(ctx, "acct-1"); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected a second concurrent import to be refused, got %v", err)
+	}
+	done()
+	done()
+	again, err := l.StartImport(ctx, "acct-1")
+	if err != nil {
+		t.Errorf("Expected an import after the first ended, got %v", err)
+	} else {
+		again()
+	}
+
+	// Daily records, refused as a whole and reset at midnight
+	if err := l.ReserveRecords(ctx, "acct-1", 60); err != nil {
+		t.Fatalf("ReserveRecords(60) error = %v", err)
+	}
+	if err := l.ReserveRecords(ctx, "acct-1", 50); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected 110 records to exceed the quota of 100, got %v", err)
+	} else if retryAfter, _ := ratelimit.RetryAfter(err); retryAfter != "3600" {
+		t.Errorf("Expected a retry at midnight, got %s seconds", retryAfter)
+	}
+	if err := l.ReserveRecords(ctx, "acct-1", 40); err != nil {
+		t.Errorf("ReserveRecords(40) error = %v", err)
+	}
+	if err := l.ReserveRecords(ctx, "big", 60); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the global quota of 150 to be exceeded, got %v", err)
+	}
+
+	usages := l.Usage("")
+	if len(usages) != 3 || usages[0].AccountID != "" || usages[1].AccountID != "acct-1" || usages[2].AccountID != "big" {
+		t.Fatalf("Usage() = %+v", usages)
+	}
+	if u := usages[1]; u.RecordsToday != 100 || u.Day != "2026-10-18" || u.ThrottledRequests != 1 || u.RejectedImports != 2 || u.Limits.Burst != 2 {
+		t.Errorf("Unexpected account usage: %+v", u)
+	}
+	if u := usages[0]; u.RecordsToday != 100 || u.ThrottledRequests != 1 || u.RejectedImports != 1 {
+		t.Errorf("Unexpected global usage: %+v", u)
+	}
+
+	clock.Advance(time.Hour)
+	if err := l.ReserveRecords(ctx, "acct-1", 100); err != nil {
+		t.Errorf("Expected the quota to reset on a new day, got %v", err)
+	}
+	if u := l.Usage("acct-1"); len(u) != 2 || u[1].Day != "2026-10-19" || u[1].RecordsToday != 100 {
+		t.Errorf("Usage(acct-1) = %+v", u)
+	}
+
+	// A nil limiter allows everything
+	var none *ratelimit.Limiter
+	release, err := none.StartImport(ctx, "acct-1")
+	if err != nil || none.Allow(ctx, "acct-1") != nil || none.ReserveRecords(ctx, "acct-1", 1<<30) != nil || none.Usage("") != nil {
+		t.Error("Expected a nil limiter to allow everything")
+	}
+	release()
+}
+
+func TestRateLimit_GRPC(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limits{}, ratelimit.Limits{RequestsPerSecond: 0.01, Burst: 2, ConcurrentImports: 1, RecordsPerDay: 3}, nil)
+	db := &blockingDBClient{release: make(chan struct{})}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(limiter.UnaryServerInterceptor(pb.DataProcessorService_HealthCheck_FullMethodName, pb.DataProcessorService_GetQuotaUsage_FullMethodName)))
+	service := handler.NewDataProcessorServiceWithOptions(db, handler.WithLimiter(limiter))
+	pb.RegisterDataProcessorServiceServer(server, service)
+	go server.Serve(lis)
+	defer server.Stop()
+	client := pb.NewDataProcessorServiceClient(mustDial(t, lis.Addr().String()))
+	ctx := context.Background()
+
+	// A second import for the account waits for the first to finish
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"})
+		first <- err
+	}()
+	for service.InFlightJobs() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"}); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected a concurrent import to be refused, got %v", err)
+	}
+	close(db.release)
+	if err := <-first; err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+
+	// The burst of two is used up; the caller learns when to retry
+	var header metadata.MD
+	_, err = client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected the request rate to be exceeded, got %v", err)
+	}
+	if got := header.Get(ratelimit.RetryAfterHeader); len(got) != 1 || got[0] == "" {
+		t.Errorf("Expected a retry-after header, got %v", header)
+	}
+	if _, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{}); err != nil {
+		t.Errorf("Expected HealthCheck to be exempt, got %v", err)
+	}
+
+	// Another account: 2 records fit the daily quota of 3, 2 more do not
+	if _, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-2"}); err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+	if _, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-2"}); status.Code(err) != codes.ResourceExhausted || !strings.Contains(err.Error(), "daily quota") {
+		t.Errorf("Expected the daily quota to be exceeded, got %v", err)
+	}
+
+	resp, err := client.GetQuotaUsage(ctx, &pb.GetQuotaUsageRequest{})
+	if err != nil {
+		t.Fatalf("GetQuotaUsage() error = %v", err)
+	}
+	if resp.Global == nil || resp.Global.RecordsToday != 4 || len(resp.Accounts) != 2 {
+		t.Fatalf("Unexpected usage: %+v", resp)
+	}
+	acct1, acct2 := resp.Accounts[0], resp.Accounts[1]
+	if acct1.AccountId != "acct-1" || acct1.ThrottledRequests != 1 || acct1.RejectedImports != 1 || acct1.ConcurrentImports != 0 || acct1.ConcurrentImportsLimit != 1 {
+		t.Errorf("Unexpected acct-1 usage: %+v", acct1)
+	}
+	if acct2.AccountId != "acct-2" || acct2.RecordsToday != 2 || acct2.RecordsPerDayLimit != 3 || acct2.RejectedImports != 1 {
+		t.Errorf("Unexpected acct-2 usage: %+v", acct2)
+	}
+
+	// Without a limiter there is nothing to report
+	if _, err := handler.NewDataProcessorService(nil).GetQuotaUsage(ctx, &pb.GetQuotaUsageRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition without a limiter, got %v", err)
+	}
+}
+
+func TestRateLimit_Gateway(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limits{}, ratelimit.Limits{RequestsPerSecond: 0.01, Burst: 1}, nil)
+	service := handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{}, handler.WithLimiter(limiter))
+	gw, err := gateway.New(context.Background(), gateway.Options{
+		Server:      service,
+		Uploader:    service,
+		UploadDir:   t.TempDir(),
+		Interceptor: limiter.UnaryServerInterceptor(pb.DataProcessorService_GetQuotaUsage_FullMethodName),
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+
+	body := `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "acct-1"}`
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := http.Post(srv.URL+"/v1/process/data", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Request %d: got %d, want %d", i+1, resp.StatusCode, want)
+		}
+		if want == http.StatusTooManyRequests && resp.Header.Get("Retry-After") == "" {
+			t.Error("Expected a Retry-After header")
+		}
+	}
+
+	// Uploads count against the same rate
+	uploadBody, contentType := multipartBody(t,
+		formPart{name: "account_id", value: "acct-1"},
+		formPart{name: "file", fileName: "statement.csv", value: septCSV},
+	)
+	resp, err := http.Post(srv.URL+"/v1/upload", contentType, uploadBody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("Expected a throttled upload, got %d with Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/admin/quota-usage?account_id=acct-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the usage endpoint to be exempt, got %d", resp.StatusCode)
+	}
+}
+
+func TestRateLimit_AccountsBounded(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	l := ratelimit.NewWithClock(ratelimit.Limits{}, ratelimit.Limits{RequestsPerSecond: 1}, nil, clock.Now)
+	interceptor := l.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: pb.DataProcessorService_ProcessCSVData_FullMethodName}
+	ok := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	// A key limited to acct-1 cannot charge other accounts
+	store, err := auth.NewStore([]auth.Key{{Name: "scraper", Key: "k1", Accounts: []string{"acct-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := store.Authenticate("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := auth.NewContext(context.Background(), p)
+	for i := 0; i < 100; i++ {
+		req := &pb.ProcessCSVDataRequest{AccountId: fmt.Sprintf("other-%d", i)}
+		if _, err := interceptor(ctx, req, info, ok); status.Code(err) != codes.PermissionDenied {
+			t.Fatalf("Expected PermissionDenied for another account, got %v", err)
+		}
+	}
+	if n := l.Accounts(); n != 0 {
+		t.Errorf("Expected refused accounts not to be tracked, got %d", n)
+	}
+
+	// Unrestricted callers naming ever new accounts fill the limiter up to
+	// MaxAccounts; further accounts wait until some are idle
+	ctx = context.Background()
+	for i := 0; i < ratelimit.MaxAccounts; i++ {
+		if _, err := interceptor(ctx, &pb.ProcessCSVDataRequest{AccountId: fmt.Sprintf("acct-%d", i)}, info, ok); err != nil {
+			t.Fatalf("Request %d: %v", i, err)
+		}
+	}
+	if _, err := interceptor(ctx, &pb.ProcessCSVDataRequest{AccountId: "one-more"}, info, ok); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted with every account busy, got %v", err)
+	}
+	clock.Advance(time.Second)
+	for i := 0; i < 2*ratelimit.MaxAccounts; i++ {
+		if _, err := interceptor(ctx, &pb.ProcessCSVDataRequest{AccountId: fmt.Sprintf("new-%d", i)}, info, ok); err != nil {
+			t.Fatalf("Expected idle accounts to make room, got %v", err)
+		}
+		if i%ratelimit.MaxAccounts == ratelimit.MaxAccounts-1 {
+			clock.Advance(time.Second)
+		}
+	}
+	if n := l.Accounts(); n > ratelimit.MaxAccounts {
+		t.Errorf("Expected at most %d accounts, got %d", ratelimit.MaxAccounts, n)
+	}
+
+	// An account with records today is not idle and keeps its quota
+	l = ratelimit.NewWithClock(ratelimit.Limits{}, ratelimit.Limits{RecordsPerDay: 5}, nil, clock.Now)
+	if err := l.ReserveRecords(ctx, "acct-1", 5); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < ratelimit.MaxAccounts; i++ {
+		l.Allow(ctx, fmt.Sprintf("acct-x%d", i))
+	}
+	if err := l.ReserveRecords(ctx, "acct-1", 1); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected acct-1's quota to survive, got %v", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,216 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRecordError_From(t *testing.T) {
+	typed := recorderr.New(recorderr.CodeMissingField, "card_number", "card number cannot be empty")
+	wrapped := fmt.Errorf("outer: %w", typed)
+
+	got := recorderr.From(wrapped, recorderr.CodeValidationFailed)
+	if got.Code != recorderr.CodeMissingField || got.Field != "card_number" {
+		t.Errorf("Expected typed error to be found, got %+v", got)
+	}
+
+	plain := recorderr.From(errors.New("boom"), recorderr.CodeValidationFailed)
+	if plain.Code != recorderr.CodeValidationFailed || plain.Message != "boom" {
+		t.Errorf("Expected fallback code, got %+v", plain)
+	}
+	if !errors.Is(plain, plain.Err) {
+		t.Error("Expected Unwrap to expose the cause")
+	}
+}
+
+func TestRecordError_At(t *testing.T) {
+	base := recorderr.New(recorderr.CodeInvalidDate, "entry_date", "bad date")
+	located := base.At(5, 3)
+
+	if located.LineNumber != 5 || located.RecordIndex != 3 {
+		t.Errorf("Expected location 5/3, got %d/%d", located.LineNumber, located.RecordIndex)
+	}
+	if base.LineNumber != 0 || base.RecordIndex != -1 {
+		t.Error("At must not modify the original error")
+	}
+}
+
+func TestRecordError_Names(t *testing.T) {
+	if recorderr.CodeSaveFailed.String() != "SAVE_FAILED" {
+		t.Errorf("Unexpected code name: %s", recorderr.CodeSaveFailed)
+	}
+	if recorderr.Code(99).String() != "CODE_99" {
+		t.Errorf("Unexpected unknown code name: %s", recorderr.Code(99))
+	}
+	if recorderr.SeverityWarning.String() != "warning" {
+		t.Errorf("Unexpected severity name: %s", recorderr.SeverityWarning)
+	}
+}
+
+func TestRecordError_CodesMatchProto(t *testing.T) {
+	pairs := map[recorderr.Code]pb.ErrorCode{
+		recorderr.CodeParseFailed:      pb.ErrorCode_ERROR_CODE_PARSE_FAILED,
+		recorderr.CodeMissingField:     pb.ErrorCode_ERROR_CODE_MISSING_FIELD,
+		recorderr.CodeConversionFailed: pb.ErrorCode_ERROR_CODE_CONVERSION_FAILED,
+		recorderr.CodeSaveFailed:       pb.ErrorCode_ERROR_CODE_SAVE_FAILED,
+		recorderr.CodeInvalidRequest:   pb.ErrorCode_ERROR_CODE_INVALID_REQUEST,
+	}
+	for code, want := range pairs {
+		if pb.ErrorCode(code) != want {
+			t.Errorf("Code %s = %d, proto %s = %d", code, code, want, want)
+		}
+	}
+	if pb.Severity(recorderr.SeverityError) != pb.Severity_SEVERITY_ERROR {
+		t.Error("Severity values do not match proto")
+	}
+}
+
+func TestETCCSVParser_ValidateRecord_TypedErrors(t *testing.T) {
+	p := parser.NewETCCSVParser()
+
+	tests := []struct {
+		name   string
+		record parser.ActualETCRecord
+		code   recorderr.Code
+		field  string
+	}{
+		{"missing card", parser.ActualETCRecord{EntryDate: "25/09/01"}, recorderr.CodeMissingField, "card_number"},
+		{"bad entry date", parser.ActualETCRecord{EntryDate: "bad", CardNumber: "1"}, recorderr.CodeInvalidDate, "entry_date"},
+		{"bad exit date", parser.ActualETCRecord{ExitDate: "bad", CardNumber: "1"}, recorderr.CodeInvalidDate, "exit_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.ValidateRecord(tt.record)
+			var recErr *recorderr.Error
+			if !errors.As(err, &recErr) {
+				t.Fatalf("Expected *recorderr.Error, got %T", err)
+			}
+			if recErr.Code != tt.code || recErr.Field != tt.field {
+				t.Errorf("Got %s/%s, want %s/%s", recErr.Code, recErr.Field, tt.code, tt.field)
+			}
+		})
+	}
+}
+
+func TestProcessCSVData_RecordErrors(t *testing.T) {
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			return status.Error(codes.Unavailable, "db_service down")
+		},
+	}
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithRetryPolicy(fastRetryPolicy(t, 1)))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+invalid1,08:00,invalid2,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********87654321,テスト2`,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Legacy strings stay populated
+	if len(resp.Errors) != 2 {
+		t.Fatalf("Expected 2 legacy errors, got %v", resp.Errors)
+	}
+	if len(resp.RecordErrors) != 2 {
+		t.Fatalf("Expected 2 record errors, got %v", resp.RecordErrors)
+	}
+
+	conv := resp.RecordErrors[0]
+	if conv.Code != pb.ErrorCode_ERROR_CODE_CONVERSION_FAILED {
+		t.Errorf("Expected CONVERSION_FAILED, got %v", conv.Code)
+	}
+	if conv.LineNumber != 2 || conv.RecordIndex != 0 {
+		t.Errorf("Expected line 2 index 0, got %d/%d", conv.LineNumber, conv.RecordIndex)
+	}
+	if conv.Field != "exit_date" || conv.Severity != pb.Severity_SEVERITY_ERROR {
+		t.Errorf("Unexpected field/severity: %s/%v", conv.Field, conv.Severity)
+	}
+
+	save := resp.RecordErrors[1]
+	if save.Code != pb.ErrorCode_ERROR_CODE_SAVE_FAILED || !save.Retryable {
+		t.Errorf("Expected retryable SAVE_FAILED, got %v retryable=%v", save.Code, save.Retryable)
+	}
+	if save.LineNumber != 3 {
+		t.Errorf("Expected line 3, got %d", save.LineNumber)
+	}
+}
+
+func TestProcessCSVData_DuplicateNotice(t *testing.T) {
+	service := handler.NewDataProcessorService(&mockDBClient{})
+
+	csvData := `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考`
+	for i := 0; i < 2; i++ {
+		csvData += "\n25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト"
+	}
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        csvData,
+		AccountId:      "test-account",
+		SkipDuplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Errors) != 0 {
+		t.Errorf("Skipped duplicates must not add legacy errors, got %v", resp.Errors)
+	}
+	if len(resp.RecordErrors) != 1 || resp.RecordErrors[0].Severity != pb.Severity_SEVERITY_INFO {
+		t.Errorf("Expected one info-level duplicate notice, got %v", resp.RecordErrors)
+	}
+}
+
+func TestValidateCSVData_RecordErrorsAndField(t *testing.T) {
+	service := handler.NewDataProcessorService(nil)
+
+	resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvData: `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,,テスト`,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "card_number" {
+		t.Fatalf("Expected ValidationError.field to be card_number, got %v", resp.Errors)
+	}
+	if len(resp.RecordErrors) != 1 || resp.RecordErrors[0].Code != pb.ErrorCode_ERROR_CODE_MISSING_FIELD {
+		t.Errorf("Expected MISSING_FIELD record error, got %v", resp.RecordErrors)
+	}
+}
+
+func TestValidator_InvalidArgumentDetails(t *testing.T) {
+	v := handler.NewDefaultValidator()
+
+	err := v.ValidateAccountID("")
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("Expected InvalidArgument, got %v", st.Code())
+	}
+
+	found := false
+	for _, d := range st.Details() {
+		if recErr, ok := d.(*pb.RecordError); ok {
+			found = true
+			if recErr.Field != "account_id" || recErr.Code != pb.ErrorCode_ERROR_CODE_INVALID_REQUEST {
+				t.Errorf("Unexpected detail: %v", recErr)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected RecordError detail on status")
+	}
+}
\ No newline at end of file
@@ -0,0 +1,241 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/dedup"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+// upsertDBClient stores records by match key
+type upsertDBClient struct {
+	rows    map[string]interface{}
+	inserts int
+}
+
+func (m *upsertDBClient) SaveETCData(data interface{}) error {
+	m.inserts++
+	return nil
+}
+
+func (m *upsertDBClient) UpsertETCData(matchKey string, data interface{}) (bool, error) {
+	if m.rows == nil {
+		m.rows = make(map[string]interface{})
+	}
+	_, replaced := m.rows[matchKey]
+	m.rows[matchKey] = data
+	return replaced, nil
+}
+
+const reissueCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:01,東京,横浜,1500,-200,1300,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:01,東京,横浜,1500,-200,1300,2,1234,********12345678,確定
+25/09/01,10:00,25/09/01,11:00,横浜,名古屋,3000,-500,2500,2,1234,********12345678,`
+
+func TestFuzzyMatcher_Classify(t *testing.T) {
+	m := dedup.NewFuzzyMatcher(5 * time.Minute)
+	base := dedupBaseRecord()
+
+	corrected := base
+	corrected.ETCAmount = 1300
+	corrected.ExitTime = "09:03"
+
+	confirmed := base
+	confirmed.Notes = "確定"
+
+	late := base
+	late.ExitTime = "09:10"
+
+	otherIC := base
+	otherIC.ExitIC = "川崎"
+
+	tests := []struct {
+		name  string
+		later parser.ActualETCRecord
+		want  dedup.MatchKind
+	}{
+		{"same row", base, dedup.MatchDuplicate},
+		{"corrected amount", corrected, dedup.MatchCorrection},
+		{"status change", confirmed, dedup.MatchStatusChange},
+		{"outside window", late, dedup.MatchNone},
+		{"different exit IC", otherIC, dedup.MatchNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Classify(base, tt.later); got != tt.want {
+				t.Errorf("Classify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatcher_DefaultWindow(t *testing.T) {
+	if m := dedup.NewFuzzyMatcher(0); m.Window != dedup.DefaultFuzzyWindow {
+		t.Errorf("Expected default window, got %v", m.Window)
+	}
+}
+
+func TestFuzzyIndex_ChainsCorrections(t *testing.T) {
+	x := dedup.NewFuzzyIndex(dedup.NewFuzzyMatcher(5 * time.Minute))
+	base := dedupBaseRecord()
+
+	first := x.Observe(base, 0)
+	if first.Kind != dedup.MatchNone {
+		t.Fatalf("Expected first row to be new, got %s", first.Kind)
+	}
+
+	corrected := base
+	corrected.ETCAmount = 1300
+	corrected.ExitTime = "09:04"
+	second := x.Observe(corrected, 1)
+	if second.Kind != dedup.MatchCorrection || second.Index != 0 || second.Key != first.Key {
+		t.Errorf("Expected correction of row 0 under its key, got %+v", second)
+	}
+
+	// Compared against the corrected row, still under the original key
+	confirmed := corrected
+	confirmed.Notes = "確定"
+	third := x.Observe(confirmed, 2)
+	if third.Kind != dedup.MatchStatusChange || third.Index != 1 || third.Key != first.Key {
+		t.Errorf("Expected status change of row 1 under the original key, got %+v", third)
+	}
+}
+
+func TestProcessCSVData_UpsertsReissuedRows(t *testing.T) {
+	db := &upsertDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db,
+		handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)),
+	)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        reissueCSV,
+		AccountId:      "test-account",
+		SkipDuplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if db.inserts != 0 {
+		t.Errorf("Expected upserts only, got %d inserts", db.inserts)
+	}
+	if len(db.rows) != 2 {
+		t.Errorf("Expected 2 stored trips, got %d", len(db.rows))
+	}
+	if resp.Stats.CorrectedRecords != 1 || resp.Stats.StatusChanges != 1 {
+		t.Errorf("Expected 1 correction and 1 status change, got %d/%d", resp.Stats.CorrectedRecords, resp.Stats.StatusChanges)
+	}
+	if resp.Stats.UpdatedRecords != 2 {
+		t.Errorf("Expected 2 updated records, got %d", resp.Stats.UpdatedRecords)
+	}
+	if resp.Stats.SkippedRecords != 0 {
+		t.Errorf("Re-issued rows must not be skipped, got %d", resp.Stats.SkippedRecords)
+	}
+
+	codes := map[pb.ErrorCode]int{}
+	for _, e := range resp.RecordErrors {
+		codes[e.Code]++
+		if e.Severity != pb.Severity_SEVERITY_INFO {
+			t.Errorf("Expected info notices, got %v", e)
+		}
+	}
+	if codes[pb.ErrorCode_ERROR_CODE_CORRECTION] != 1 || codes[pb.ErrorCode_ERROR_CODE_STATUS_CHANGE] != 1 {
+		t.Errorf("Unexpected notices: %v", resp.RecordErrors)
+	}
+}
+
+func TestProcessCSVData_ReissueWithoutUpserter(t *testing.T) {
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(mockDB,
+		handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)),
+	)
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   reissueCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Plain DB clients keep inserting every row
+	if resp.Stats.SavedRecords != 4 || resp.Stats.UpdatedRecords != 0 {
+		t.Errorf("Expected 4 inserts, got saved=%d updated=%d", resp.Stats.SavedRecords, resp.Stats.UpdatedRecords)
+	}
+
+	// so no row is reported as replacing another
+	if resp.Stats.CorrectedRecords != 0 || resp.Stats.StatusChanges != 0 {
+		t.Errorf("Expected no corrections without an upserter, got %d/%d", resp.Stats.CorrectedRecords, resp.Stats.StatusChanges)
+	}
+	for _, e := range resp.RecordErrors {
+		if e.Code == pb.ErrorCode_ERROR_CODE_CORRECTION || e.Code == pb.ErrorCode_ERROR_CODE_STATUS_CHANGE {
+			t.Errorf("Unexpected re-issue notice without an upserter: %v", e)
+		}
+	}
+	if len(mockDB.savedData) != 4 {
+		t.Errorf("Expected every row inserted, got %d", len(mockDB.savedData))
+	}
+}
+
+func TestValidateCSVData_ReportsReissuedRows(t *testing.T) {
+	service := handler.NewDataProcessorServiceWithOptions(nil,
+		handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)),
+	)
+
+	resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvData:   reissueCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.CorrectionCount != 1 || resp.StatusChangeCount != 1 || resp.DuplicateCount != 0 {
+		t.Errorf("Expected 1 correction, 1 status change, 0 duplicates; got %d/%d/%d",
+			resp.CorrectionCount, resp.StatusChangeCount, resp.DuplicateCount)
+	}
+}
+
+func TestProcessCSVData_ExactRowIgnoresFuzzy(t *testing.T) {
+	policies, _ := dedup.NewPolicySet("exact_row", nil)
+	db := &upsertDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db,
+		handler.WithDuplicatePolicies(policies),
+		handler.WithFuzzyMatcher(dedup.NewFuzzyMatcher(5*time.Minute)),
+	)
+
+	// Only the repeated last row is the same trip under exact_row
+	csv := reissueCSV + "\n" + reissueCSV[strings.LastIndex(reissueCSV, "\n")+1:]
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:        csv,
+		AccountId:      "test-account",
+		SkipDuplicates: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(db.rows) != 4 {
+		t.Errorf("Expected every differing row stored separately, got %d", len(db.rows))
+	}
+	if resp.Stats.CorrectedRecords != 0 || resp.Stats.StatusChanges != 0 || resp.Stats.UpdatedRecords != 0 {
+		t.Errorf("Expected no re-issues, got corrected=%d status=%d updated=%d",
+			resp.Stats.CorrectedRecords, resp.Stats.StatusChanges, resp.Stats.UpdatedRecords)
+	}
+	if resp.Stats.SkippedRecords != 1 {
+		t.Errorf("Expected the repeated row skipped, got %d", resp.Stats.SkippedRecords)
+	}
+
+	validated, _ := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{CsvData: csv, AccountId: "test-account"})
+	if validated.CorrectionCount != 0 || validated.StatusChangeCount != 0 || validated.DuplicateCount != 1 {
+		t.Errorf("Expected 0 corrections, 0 status changes, 1 duplicate; got %d/%d/%d",
+			validated.CorrectionCount, validated.StatusChangeCount, validated.DuplicateCount)
+	}
+}
\ No newline at end of file
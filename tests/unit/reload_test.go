@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/logging"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/ratelimit"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReload_LogLevel(t *testing.T) {
+	buf := &logBuffer{}
+	level := new(slog.LevelVar)
+	logger, err := logging.NewLeveled(buf, level, logging.FormatJSON)
+	if err != nil {
+		t.Fatalf("NewLeveled() error = %v", err)
+	}
+
+	logger.Debug("before")
+	level.Set(slog.LevelDebug)
+	logger.Debug("after")
+	if entries := buf.entries(t); len(entries) != 1 || entries[0]["msg"] != "after" {
+		t.Errorf("Expected only the debug line after the level changed, got %v", entries)
+	}
+	if _, err := logging.NewLeveled(buf, level, "xml"); err == nil {
+		t.Error("Expected an unknown format to be an error")
+	}
+}
+
+func TestReload_AuthKeys(t *testing.T) {
+	authenticator := testAuthenticator(t)
+	protected := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	call := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/failed-records", nil)
+		req.Header.Set(auth.AuthorizationHeader, "Bearer "+key)
+		rec := httptest.NewRecorder()
+		protected.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if got := call("key-one"); got != http.StatusOK {
+		t.Fatalf("Expected key-one to be accepted, got %d", got)
+	}
+	store, err := auth.NewStore([]auth.Key{{Name: "rotated", Key: "key-rotated", Accounts: []string{"acct-1"}}})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	authenticator.SetStore(store)
+	if got := call("key-one"); got != http.StatusUnauthorized {
+		t.Errorf("Expected the removed key to be refused, got %d", got)
+	}
+	if got := call("key-rotated"); got != http.StatusOK {
+		t.Errorf("Expected the new key to be accepted, got %d", got)
+	}
+
+	var none *auth.Authenticator
+	none.SetStore(store)
+}
+
+func TestReload_RateLimits(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)}
+	l := ratelimit.NewWithClock(ratelimit.Limits{}, ratelimit.Limits{RequestsPerSecond: 1, RecordsPerDay: 10}, nil, clock.Now)
+
+	if err := l.Allow(ctx, "acct-1"); err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if err := l.ReserveRecords(ctx, "acct-1", 5); err != nil {
+		t.Fatalf("ReserveRecords() error = %v", err)
+	}
+	if err := l.Allow(ctx, "acct-1"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Expected the second request to be throttled, got %v", err)
+	}
+
+	// Raised rate and an override for another account; today's records count
+	// against the lowered quota
+	l.SetLimits(ratelimit.Limits{}, ratelimit.Limits{RequestsPerSecond: 3, RecordsPerDay: 6}, map[string]ratelimit.Limits{"acct-2": {RecordsPerDay: 1}})
+	clock.Advance(time.Second)
+	for i := 0; i < 3; i++ {
+		if err := l.Allow(ctx, "acct-1"); err != nil {
+			t.Errorf("Allow() #%d after raising the rate error = %v", i+1, err)
+		}
+	}
+	if err := l.ReserveRecords(ctx, "acct-1", 2); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected 7 records to exceed the lowered quota of 6, got %v", err)
+	}
+	if err := l.ReserveRecords(ctx, "acct-2", 2); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected the new override to apply, got %v", err)
+	}
+	if u := l.Usage("acct-1"); u[1].RecordsToday != 5 || u[1].Limits.RecordsPerDay != 6 || u[1].Limits.Burst != 3 {
+		t.Errorf("Usage(acct-1) = %+v", u[1])
+	}
+
+	var none *ratelimit.Limiter
+	none.SetLimits(ratelimit.Limits{}, ratelimit.Limits{}, nil)
+}
+
+func TestReload_Rules(t *testing.T) {
+	service := handler.NewDataProcessorServiceWithOptions(nil)
+	validate := func() *pb.ValidateCSVDataResponse {
+		resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{CsvData: rulesCSV, AccountId: "test-account"})
+		if err != nil {
+			t.Fatalf("ValidateCSVData() error = %v", err)
+		}
+		return resp
+	}
+
+	if resp := validate(); resp.RuleSet != "" {
+		t.Errorf("Expected no rule set before the rules are loaded, got %q", resp.RuleSet)
+	}
+	reg, err := rules.Parse([]byte(testRulesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.SetRules(reg)
+	if resp := validate(); resp.RuleSet != "strict" || resp.IsValid {
+		t.Errorf("Expected the loaded rules to reject the negative amount, got %q valid=%v", resp.RuleSet, resp.IsValid)
+	}
+	service.SetRules(nil)
+	if resp := validate(); resp.RuleSet != "" {
+		t.Errorf("Expected the rules to be disabled again, got %q", resp.RuleSet)
+	}
+}
\ No newline at end of file
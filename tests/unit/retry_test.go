@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const retryTestCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,テスト1
+25/09/02,08:00,25/09/02,09:00,横浜,名古屋,3000,-500,2500,2,1234,********87654321,テスト2`
+
+func fastRetryPolicy(t *testing.T, maxAttempts int) *handler.RetryPolicy {
+	t.Helper()
+	policy, err := handler.NewRetryPolicy(maxAttempts, time.Millisecond, 2*time.Millisecond, 0, nil)
+	if err != nil {
+		t.Fatalf("NewRetryPolicy() error = %v", err)
+	}
+	return policy
+}
+
+func TestNewRetryPolicy_Defaults(t *testing.T) {
+	policy, err := handler.NewRetryPolicy(0, 0, 0, 0, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if policy.MaxAttempts != 3 {
+		t.Errorf("Expected 3 max attempts, got %d", policy.MaxAttempts)
+	}
+	if policy.InitialBackoff != 100*time.Millisecond {
+		t.Errorf("Expected 100ms initial backoff, got %v", policy.InitialBackoff)
+	}
+	for _, c := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted} {
+		if !policy.RetryableCodes[c] {
+			t.Errorf("Expected %v to be retryable by default", c)
+		}
+	}
+}
+
+func TestNewRetryPolicy_InvalidCode(t *testing.T) {
+	_, err := handler.NewRetryPolicy(0, 0, 0, 0, []string{"NOT_A_CODE"})
+	if err == nil {
+		t.Error("Expected error for unknown code name")
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	tests := []struct {
+		name string
+		want codes.Code
+	}{
+		{"UNAVAILABLE", codes.Unavailable},
+		{"DEADLINE_EXCEEDED", codes.DeadlineExceeded},
+		{"ResourceExhausted", codes.ResourceExhausted},
+		{"aborted", codes.Aborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handler.ParseCode(tt.name)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCode(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &handler.RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := policy.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestRetryPolicy_Classify(t *testing.T) {
+	policy := handler.DefaultRetryPolicy()
+
+	if got := policy.Classify(status.Error(codes.Unavailable, "down")); got != handler.ErrorClassTransient {
+		t.Errorf("Expected transient for Unavailable, got %v", got)
+	}
+	if got := policy.Classify(status.Error(codes.InvalidArgument, "bad")); got != handler.ErrorClassPermanent {
+		t.Errorf("Expected permanent for InvalidArgument, got %v", got)
+	}
+	if got := policy.Classify(errors.New("plain error")); got != handler.ErrorClassPermanent {
+		t.Errorf("Expected permanent for non-status error, got %v", got)
+	}
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := fastRetryPolicy(t, 3)
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		calls := 0
+		attempts, _, err := policy.Do(context.Background(), func() error {
+			calls++
+			if calls < 3 {
+				return status.Error(codes.Unavailable, "db_service down")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("stops on permanent error", func(t *testing.T) {
+		attempts, class, err := policy.Do(context.Background(), func() error {
+			return status.Error(codes.InvalidArgument, "bad record")
+		})
+		if err == nil {
+			t.Error("Expected error")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+		if class != handler.ErrorClassPermanent {
+			t.Errorf("Expected permanent class, got %v", class)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		attempts, class, err := policy.Do(context.Background(), func() error {
+			return status.Error(codes.Unavailable, "db_service down")
+		})
+		if err == nil {
+			t.Error("Expected error")
+		}
+		if attempts != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts)
+		}
+		if class != handler.ErrorClassTransient {
+			t.Errorf("Expected transient class, got %v", class)
+		}
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		attempts, _, err := policy.Do(ctx, func() error {
+			return status.Error(codes.Unavailable, "db_service down")
+		})
+		if err == nil {
+			t.Error("Expected error")
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestProcessCSVData_RetriesTransientSaveErrors(t *testing.T) {
+	calls := 0
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			calls++
+			// First record fails once before succeeding
+			if calls == 1 {
+				return status.Error(codes.Unavailable, "db_service down")
+			}
+			return nil
+		},
+	}
+
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithRetryPolicy(fastRetryPolicy(t, 3)))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   retryTestCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 {
+		t.Errorf("Expected 2 saved records, got %d", resp.Stats.SavedRecords)
+	}
+	if resp.Stats.RetriedRecords != 1 {
+		t.Errorf("Expected 1 retried record, got %d", resp.Stats.RetriedRecords)
+	}
+	if resp.Stats.RetryAttempts != 1 {
+		t.Errorf("Expected 1 retry attempt, got %d", resp.Stats.RetryAttempts)
+	}
+	if len(resp.Errors) != 0 {
+		t.Errorf("Expected no errors, got %v", resp.Errors)
+	}
+}
+
+func TestProcessCSVData_LabelsSaveErrors(t *testing.T) {
+	mockDB := &mockDBClient{
+		saveFunc: func(data interface{}) error {
+			m := data.(map[string]interface{})
+			if m["card_number"] == "********12345678" {
+				return status.Error(codes.Unavailable, "db_service down")
+			}
+			return status.Error(codes.InvalidArgument, "rejected")
+		},
+	}
+
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithRetryPolicy(fastRetryPolicy(t, 2)))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   retryTestCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.TransientErrors != 1 {
+		t.Errorf("Expected 1 transient error, got %d", resp.Stats.TransientErrors)
+	}
+	if resp.Stats.PermanentErrors != 1 {
+		t.Errorf("Expected 1 permanent error, got %d", resp.Stats.PermanentErrors)
+	}
+	if resp.Stats.RetryAttempts != 1 {
+		t.Errorf("Expected 1 retry attempt, got %d", resp.Stats.RetryAttempts)
+	}
+	if len(resp.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", resp.Errors)
+	}
+	if !strings.Contains(resp.Errors[0], "transient") {
+		t.Errorf("Expected transient label, got %q", resp.Errors[0])
+	}
+	if !strings.Contains(resp.Errors[1], "permanent") {
+		t.Errorf("Expected permanent label, got %q", resp.Errors[1])
+	}
+}
\ No newline at end of file
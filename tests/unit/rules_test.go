@@ -0,0 +1,254 @@
+package unit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/deadletter"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/parser"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/recorderr"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/rules"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+)
+
+const testRulesYAML = `
+default: strict
+accounts:
+  relaxed-account: relaxed
+rule_sets:
+  strict:
+    - name: card_required
+      type: required
+      field: card_number
+    - name: amount_non_negative
+      type: range
+      field: etc_amount
+      min: "0"
+    - name: class_known
+      type: allowed
+      field: vehicle_class
+      values: ["1", "2", "3"]
+      severity: warning
+    - name: exit_after_entry
+      type: after
+      field: exit_date
+      other: entry_date
+    - name: date_window
+      type: date_range
+      field: exit_date
+      min: "2000-01-01"
+      max: now
+    - name: exit_ic_header_only
+      type: required
+      field: exit_ic
+      formats: [header]
+  relaxed:
+    - name: card_required
+      type: required
+      field: card_number
+`
+
+func violationRules(errs []*recorderr.Error) map[string]*recorderr.Error {
+	m := make(map[string]*recorderr.Error)
+	for _, e := range errs {
+		m[e.Rule] = e
+	}
+	return m
+}
+
+func TestRules_Evaluate(t *testing.T) {
+	reg, err := rules.Parse([]byte(testRulesYAML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	set := reg.For("any-account")
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	good := dedupBaseRecord()
+	good.VehicleClass = 2
+	if errs := set.EvaluateAt(good, now); len(errs) != 0 {
+		t.Fatalf("Expected no violations, got %v", errs)
+	}
+
+	tests := []struct {
+		name     string
+		mutate   func(r *parser.ActualETCRecord)
+		rule     string
+		code     recorderr.Code
+		severity recorderr.Severity
+	}{
+		{"missing card", func(r *parser.ActualETCRecord) { r.CardNumber = "" }, "card_required", recorderr.CodeMissingField, recorderr.SeverityError},
+		{"negative amount", func(r *parser.ActualETCRecord) { r.ETCAmount = -1 }, "amount_non_negative", recorderr.CodeInvalidAmount, recorderr.SeverityError},
+		{"unknown class", func(r *parser.ActualETCRecord) { r.VehicleClass = 9 }, "class_known", recorderr.CodeInvalidVehicleClass, recorderr.SeverityWarning},
+		{"exit before entry", func(r *parser.ActualETCRecord) { r.ExitTime = "07:00" }, "exit_after_entry", recorderr.CodeInvalidDate, recorderr.SeverityError},
+		{"future date", func(r *parser.ActualETCRecord) { r.ExitDate = "25/12/01" }, "date_window", recorderr.CodeInvalidDate, recorderr.SeverityError},
+		{"too old", func(r *parser.ActualETCRecord) { r.ExitDate = "1999/12/31"; r.EntryDate = "1999/12/31" }, "date_window", recorderr.CodeInvalidDate, recorderr.SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := good
+			tt.mutate(&r)
+			v, ok := violationRules(set.EvaluateAt(r, now))[tt.rule]
+			if !ok {
+				t.Fatalf("Expected %s violation", tt.rule)
+			}
+			if v.Code != tt.code || v.Severity != tt.severity {
+				t.Errorf("Got %s/%s, want %s/%s", v.Code, v.Severity, tt.code, tt.severity)
+			}
+		})
+	}
+}
+
+func TestRules_Formats(t *testing.T) {
+	reg, _ := rules.Parse([]byte(testRulesYAML))
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	r := dedupBaseRecord()
+	r.VehicleClass = 2
+	r.ExitIC = ""
+
+	r.Format = parser.FormatPositional
+	if _, ok := violationRules(reg.Default.EvaluateAt(r, now))["exit_ic_header_only"]; ok {
+		t.Error("Rule limited to header format must not apply to positional records")
+	}
+
+	r.Format = parser.FormatHeader
+	if _, ok := violationRules(reg.Default.EvaluateAt(r, now))["exit_ic_header_only"]; !ok {
+		t.Error("Expected header-format rule to apply")
+	}
+}
+
+func TestRules_PerAccount(t *testing.T) {
+	reg, _ := rules.Parse([]byte(testRulesYAML))
+
+	if reg.For("relaxed-account").Name != "relaxed" {
+		t.Errorf("Expected relaxed rule set, got %s", reg.For("relaxed-account").Name)
+	}
+	if reg.For("other").Name != "strict" {
+		t.Errorf("Expected default rule set, got %s", reg.For("other").Name)
+	}
+}
+
+func TestRules_InvalidDefinitions(t *testing.T) {
+	tests := map[string]string{
+		"unknown type":      "default: a\nrule_sets:\n  a:\n    - {type: bogus, field: card_number}\n",
+		"unknown field":     "default: a\nrule_sets:\n  a:\n    - {type: required, field: bogus}\n",
+		"range no bounds":   "default: a\nrule_sets:\n  a:\n    - {type: range, field: etc_amount}\n",
+		"bad severity":      "default: a\nrule_sets:\n  a:\n    - {type: required, field: card_number, severity: fatal}\n",
+		"missing default":   "default: b\nrule_sets:\n  a: []\n",
+		"unknown account":   "default: a\naccounts: {x: b}\nrule_sets:\n  a: []\n",
+		"bad date bound":    "default: a\nrule_sets:\n  a:\n    - {type: date_range, field: exit_date, min: yesterday}\n",
+		"after needs other": "default: a\nrule_sets:\n  a:\n    - {type: after, field: exit_date}\n",
+	}
+
+	for name, yaml := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := rules.Parse([]byte(yaml)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestRules_DefaultAndLoadFile(t *testing.T) {
+	if rules.Default().Default.Len() == 0 {
+		t.Error("Expected built-in rules")
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(path, []byte(testRulesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := rules.LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if reg.Default.Name != "strict" {
+		t.Errorf("Expected strict default, got %s", reg.Default.Name)
+	}
+
+	if _, err := rules.LoadFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+const rulesCSV = `利用年月日（自）,時分（自）,利用年月日（至）,時分（至）,利用ＩＣ（自）,利用ＩＣ（至）,割引前料金,ＥＴＣ割引額,通行料金,車種,車両番号,ＥＴＣカード番号,備考
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,2,1234,********12345678,
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,-1200,2,1234,********12345679,
+25/09/01,08:00,25/09/01,09:00,東京,横浜,1500,-300,1200,7,1234,********12345670,`
+
+func TestValidateCSVData_Rules(t *testing.T) {
+	reg, _ := rules.Parse([]byte(testRulesYAML))
+	service := handler.NewDataProcessorServiceWithOptions(nil, handler.WithRules(reg))
+
+	resp, err := service.ValidateCSVData(context.Background(), &pb.ValidateCSVDataRequest{
+		CsvData:   rulesCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.IsValid {
+		t.Error("Expected negative amount to make data invalid")
+	}
+	if resp.RuleSet != "strict" {
+		t.Errorf("Expected strict rule set, got %q", resp.RuleSet)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Field != "etc_amount" || resp.Errors[0].LineNumber != 3 {
+		t.Errorf("Expected one etc_amount error at line 3, got %v", resp.Errors)
+	}
+
+	warnings := 0
+	for _, e := range resp.RecordErrors {
+		if e.Severity == pb.Severity_SEVERITY_WARNING && e.Rule == "class_known" {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("Expected one class_known warning, got %v", resp.RecordErrors)
+	}
+}
+
+func TestProcessCSVData_RulesRejectRecords(t *testing.T) {
+	reg, _ := rules.Parse([]byte(testRulesYAML))
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithRules(reg), handler.WithDeadLetterStore(deadletter.NewMemoryStore()))
+
+	resp, err := service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   rulesCSV,
+		AccountId: "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if resp.Stats.SavedRecords != 2 || resp.Stats.ErrorRecords != 1 {
+		t.Errorf("Expected 2 saved and 1 rejected, got %d/%d", resp.Stats.SavedRecords, resp.Stats.ErrorRecords)
+	}
+	if len(resp.Errors) != 1 {
+		t.Errorf("Expected one legacy error, got %v", resp.Errors)
+	}
+
+	failed, err := service.ListFailedRecords(context.Background(), &pb.ListFailedRecordsRequest{AccountId: "test-account"})
+	if err != nil {
+		t.Fatalf("ListFailedRecords() error = %v", err)
+	}
+	if len(failed.Records) != 1 || failed.Records[0].Stage != "validation" {
+		t.Errorf("Expected one dead-lettered validation failure, got %v", failed.Records)
+	}
+
+	// The relaxed rule set accepts every record
+	resp, _ = service.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{
+		CsvData:   rulesCSV,
+		AccountId: "relaxed-account",
+	})
+	if resp.Stats.SavedRecords != 3 || resp.RuleSet != "relaxed" {
+		t.Errorf("Expected relaxed rule set to save all records, got %d (%s)", resp.Stats.SavedRecords, resp.RuleSet)
+	}
+}
\ No newline at end of file
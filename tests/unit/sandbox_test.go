@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/sandbox"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sandboxFixture creates an allowed root with a CSV file and a directory outside it
+func sandboxFixture(t *testing.T) (root, outside string) {
+	t.Helper()
+	base := t.TempDir()
+	root = filepath.Join(base, "allowed")
+	outside = filepath.Join(base, "outside")
+	for _, dir := range []string{root, outside} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(root, "data.csv"), retryTestCSV)
+	writeFile(t, filepath.Join(root, "notes.txt"), "not a csv")
+	writeFile(t, filepath.Join(outside, "secret.csv"), "root:x:0:0")
+	return root, outside
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSandbox_Resolve(t *testing.T) {
+	root, outside := sandboxFixture(t)
+	if err := os.Symlink(filepath.Join(outside, "secret.csv"), filepath.Join(root, "link.csv")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := os.Mkdir(filepath.Join(root, "dir.csv"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := sandbox.New([]string{root}, 1024, []string{"csv"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := sb.Resolve(filepath.Join(root, "data.csv")); err != nil {
+		t.Errorf("Expected file inside root to be allowed, got %v", err)
+	}
+
+	violations := map[string]string{
+		"outside root":    filepath.Join(outside, "secret.csv"),
+		"dot-dot escape":  filepath.Join(root, "..", "outside", "secret.csv"),
+		"symlink escape":  filepath.Join(root, "link.csv"),
+		"wrong extension": filepath.Join(root, "notes.txt"),
+		"system file":     "/etc/shadow",
+		"directory":       filepath.Join(root, "dir.csv"),
+	}
+	for name, path := range violations {
+		t.Run(name, func(t *testing.T) {
+			_, err := sb.Resolve(path)
+			var v *sandbox.Violation
+			if !errors.As(err, &v) {
+				t.Errorf("Expected violation for %s, got %v", path, err)
+			}
+		})
+	}
+
+	if _, err := sb.Resolve(filepath.Join(root, "missing.csv")); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+}
+
+func TestSandbox_MaxSize(t *testing.T) {
+	root, _ := sandboxFixture(t)
+
+	sb, _ := sandbox.New([]string{root}, 10, nil)
+	_, err := sb.Resolve(filepath.Join(root, "data.csv"))
+	var v *sandbox.Violation
+	if !errors.As(err, &v) || !strings.Contains(v.Reason, "larger") {
+		t.Errorf("Expected size violation, got %v", err)
+	}
+
+	// No extension list allows any extension
+	if _, err := sb.Resolve(filepath.Join(root, "notes.txt")); err != nil {
+		t.Errorf("Expected small .txt file to be allowed, got %v", err)
+	}
+}
+
+func TestSandbox_New(t *testing.T) {
+	if _, err := sandbox.New(nil, 0, nil); err == nil {
+		t.Error("Expected error without roots")
+	}
+	if _, err := sandbox.New([]string{filepath.Join(t.TempDir(), "missing")}, 0, nil); err == nil {
+		t.Error("Expected error for missing root")
+	}
+	if _, err := sandbox.New([]string{t.TempDir()}, -1, nil); err == nil {
+		t.Error("Expected error for negative size")
+	}
+}
+
+func TestProcessCSVFile_Sandbox(t *testing.T) {
+	root, outside := sandboxFixture(t)
+	sb, err := sandbox.New([]string{root}, 0, []string{".csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockDB := &mockDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(mockDB, handler.WithSandbox(sb))
+
+	resp, err := service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: filepath.Join(root, "data.csv"),
+		AccountId:   "test-account",
+	})
+	if err != nil {
+		t.Fatalf("Expected allowed file to be processed, got %v", err)
+	}
+	if resp.Stats.TotalRecords == 0 {
+		t.Error("Expected records to be parsed")
+	}
+
+	_, err = service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: filepath.Join(outside, "secret.csv"),
+		AccountId:   "test-account",
+	})
+	st := status.Convert(err)
+	if st.Code() != codes.PermissionDenied {
+		t.Fatalf("Expected PermissionDenied, got %v", err)
+	}
+	if strings.Contains(st.Message(), "root:x") {
+		t.Error("Rejection must not echo file contents")
+	}
+
+	_, err = service.ProcessCSVFile(context.Background(), &pb.ProcessCSVFileRequest{
+		CsvFilePath: filepath.Join(root, "missing.csv"),
+		AccountId:   "test-account",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound inside the sandbox, got %v", err)
+	}
+}
\ No newline at end of file
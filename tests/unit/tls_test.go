@@ -0,0 +1,260 @@
+package unit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/auth"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/certs"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// writeSelfSigned writes a self-signed certificate and key to dir and returns
+// their paths and the certificate PEM
+func writeSelfSigned(t *testing.T, dir, name string, hosts ...string) (certFile, keyFile string, certPEM []byte) {
+	t.Helper()
+	certPEM, keyPEM, err := certs.SelfSigned(hosts...)
+	if err != nil {
+		t.Fatalf("SelfSigned() error = %v", err)
+	}
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, certPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, certPEM
+}
+
+// serveTLS runs the service over TLS and returns its address
+func serveTLS(t *testing.T, r *certs.Reloader, opts ...grpc.ServerOption) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(append(opts, grpc.Creds(credentials.NewTLS(r.ServerConfig())))...)
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&concurrentDBClient{}))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// tlsClient dials addr with the client TLS config
+func tlsClient(t *testing.T, addr string, config *tls.Config) pb.DataProcessorServiceClient {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(config)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewDataProcessorServiceClient(conn)
+}
+
+func TestCerts_TLSServer(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, certPEM := writeSelfSigned(t, dir, "server", "localhost", "127.0.0.1")
+	r, err := certs.NewReloader(certs.Options{CertFile: certFile, KeyFile: keyFile, MinVersion: tls.VersionTLS13})
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	addr := serveTLS(t, r)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(certPEM)
+	if _, err := tlsClient(t, addr, &tls.Config{RootCAs: roots}).HealthCheck(ctx, &pb.HealthCheckRequest{}); err != nil {
+		t.Errorf("HealthCheck() over TLS error = %v", err)
+	}
+	if _, err := pb.NewDataProcessorServiceClient(mustDial(t, addr)).HealthCheck(ctx, &pb.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected a plaintext client to be refused, got %v", err)
+	}
+	if _, err := tlsClient(t, addr, &tls.Config{}).HealthCheck(ctx, &pb.HealthCheckRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("Expected an untrusted certificate to be refused, got %v", err)
+	}
+	if conn, err := tls.Dial("tcp", addr, &tls.Config{RootCAs: roots, ServerName: "localhost", MaxVersion: tls.VersionTLS12}); err == nil {
+		conn.Close()
+		t.Error("Expected TLS 1.2 to be refused with min version 1.3")
+	}
+
+	// Bad options
+	if _, err := certs.NewReloader(certs.Options{CertFile: certFile}); err == nil {
+		t.Error("Expected an error without a key file")
+	}
+	if _, err := certs.NewReloader(certs.Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile}); err == nil {
+		t.Error("Expected an error for a client CA file without certificates")
+	}
+	if _, err := certs.ParseVersion("1.1"); err == nil {
+		t.Error("Expected TLS 1.1 to be unsupported")
+	}
+}
+
+func TestCerts_MutualTLSIdentity(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, serverPEM := writeSelfSigned(t, dir, "server", "localhost", "127.0.0.1")
+	billingCert, billingKey, billingPEM := writeSelfSigned(t, dir, "billing", "billing.internal")
+	otherCert, otherKey, otherPEM := writeSelfSigned(t, dir, "other", "other.internal")
+	caFile := filepath.Join(dir, "clients.pem")
+	os.WriteFile(caFile, append(billingPEM, otherPEM...), 0o644)
+
+	r, err := certs.NewReloader(certs.Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	store, err := auth.NewStore([]auth.Key{
+		{Name: "billing", ClientCert: "billing.internal", Accounts: []string{"acct-1"}},
+		{Name: "one", Key: "key-one", Accounts: []string{"acct-2"}},
+	})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	addr := serveTLS(t, r, grpc.ChainUnaryInterceptor(auth.NewAuthenticator(store).UnaryServerInterceptor()))
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(serverPEM)
+	clientConfig := func(certFile, keyFile string) *tls.Config {
+		config := &tls.Config{RootCAs: roots}
+		if certFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config.Certificates = []tls.Certificate{cert}
+		}
+		return config
+	}
+	process := func(client pb.DataProcessorServiceClient, accountID string) codes.Code {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := client.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: accountID})
+		return status.Code(err)
+	}
+
+	if got := process(tlsClient(t, addr, clientConfig("", "")), "acct-1"); got != codes.Unavailable {
+		t.Errorf("Expected a client without a certificate to be refused, got %v", got)
+	}
+	billing := tlsClient(t, addr, clientConfig(billingCert, billingKey))
+	if got := process(billing, "acct-1"); got != codes.OK {
+		t.Errorf("Expected the certificate's account to be allowed, got %v", got)
+	}
+	if got := process(billing, "acct-2"); got != codes.PermissionDenied {
+		t.Errorf("Expected another account to be denied, got %v", got)
+	}
+	if got := process(tlsClient(t, addr, clientConfig(otherCert, otherKey)), "acct-1"); got != codes.Unauthenticated {
+		t.Errorf("Expected an unconfigured certificate identity to be unauthenticated, got %v", got)
+	}
+
+	// An API key takes precedence over the certificate
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, auth.APIKeyHeader, "key-one")
+	if _, err := billing.ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-2"}); err != nil {
+		t.Errorf("Expected the API key's account to be allowed, got %v", err)
+	}
+}
+
+func TestCerts_ReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeSelfSigned(t, dir, "server", "localhost")
+	r, err := certs.NewReloader(certs.Options{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", r.ServerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+	served := func() string {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), &tls.Config{InsecureSkipVerify: true})
+		if err != nil {
+			t.Fatalf("Dial() error = %v", err)
+		}
+		defer conn.Close()
+		return conn.ConnectionState().PeerCertificates[0].SerialNumber.String()
+	}
+	touch := func(files ...string) {
+		future := time.Now().Add(time.Minute)
+		for _, f := range files {
+			os.Chtimes(f, future, future)
+		}
+	}
+
+	first := served()
+	writeSelfSigned(t, dir, "server", "localhost")
+	touch(certFile, keyFile)
+	second := served()
+	if second == first {
+		t.Error("Expected the renewed certificate to be served without a restart")
+	}
+
+	// A broken certificate file keeps the previous certificate
+	os.WriteFile(certFile, []byte("not a certificate"), 0o644)
+	if got := served(); got != second {
+		t.Errorf("Expected the previous certificate after a failed reload, got serial %s", got)
+	}
+}
+
+func TestCerts_ProxyGatewayOverMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	// The self-signed server certificate is also the client CA, so the
+	// gateway can present it
+	certFile, keyFile, _ := writeSelfSigned(t, dir, "server", "localhost", "127.0.0.1")
+	r, err := certs.NewReloader(certs.Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile})
+	if err != nil {
+		t.Fatalf("NewReloader() error = %v", err)
+	}
+	addr := serveTLS(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw, err := gateway.New(ctx, gateway.Options{
+		Mode:        gateway.ModeProxy,
+		Endpoint:    addr,
+		DialOptions: []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(r.LoopbackClientConfig()))},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	srv := httptest.NewServer(gw.Handler())
+	defer srv.Close()
+
+	body := `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "acct-1"}`
+	resp, err := http.Post(srv.URL+"/v1/process/data", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the proxy gateway to reach the mutual-TLS server, got %d", resp.StatusCode)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,342 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/tracing"
+	pb "github.com/yhonda-ohishi/etc_data_processor/src/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// tracedDBClient is a ContextSaver that records the traceparent it would send
+// to db_service
+type tracedDBClient struct {
+	mu           sync.Mutex
+	traceparents []string
+}
+
+func (c *tracedDBClient) SaveETCData(data interface{}) error {
+	return errors.New("SaveETCData must not be called on a ContextSaver")
+}
+
+func (c *tracedDBClient) SaveETCDataContext(ctx context.Context, data interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tracing.Inject(ctx, func(key, value string) {
+		if key == tracing.TraceparentHeader {
+			c.traceparents = append(c.traceparents, value)
+		}
+	})
+	return nil
+}
+
+// processCSVDataSpan is the name of ProcessCSVData's RPC spans
+var processCSVDataSpan = strings.TrimPrefix(pb.DataProcessorService_ProcessCSVData_FullMethodName, "/")
+
+// exportedSpan is the part of an exported line the tests look at
+type exportedSpan struct {
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id"`
+	Status       map[string]string      `json:"status"`
+	Attributes   map[string]interface{} `json:"attributes"`
+}
+
+// spans decodes the spans exported to a log buffer
+func spans(t *testing.T, out *logBuffer) []exportedSpan {
+	t.Helper()
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	var result []exportedSpan
+	for _, line := range strings.Split(strings.TrimSpace(out.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var span exportedSpan
+		if err := json.Unmarshal([]byte(line), &span); err != nil {
+			t.Fatalf("Invalid span line %q: %v", line, err)
+		}
+		result = append(result, span)
+	}
+	return result
+}
+
+// spanNamed returns the spans with the given name
+func spanNamed(all []exportedSpan, name string) []exportedSpan {
+	var result []exportedSpan
+	for _, span := range all {
+		if span.Name == name {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+func TestTraceparent(t *testing.T) {
+	const value = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, ok := tracing.ParseTraceparent(value)
+	if !ok || !sc.Sampled || !sc.Remote || sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Fatalf("ParseTraceparent(%q) = %+v, %v", value, sc, ok)
+	}
+	if sc.Traceparent() != value {
+		t.Errorf("Traceparent() = %q, want %q", sc.Traceparent(), value)
+	}
+	if sc, ok := tracing.ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"); !ok || sc.Sampled {
+		t.Errorf("Expected a later version with extra fields to parse unsampled, got %+v, %v", sc, ok)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, ok := tracing.ParseTraceparent(invalid); ok {
+			t.Errorf("Expected ParseTraceparent(%q) to fail", invalid)
+		}
+	}
+}
+
+func TestTracing_ImportSpans(t *testing.T) {
+	out := &logBuffer{}
+	tracer := tracing.NewTracer("etc_data_processor", tracing.NewWriterExporter(out), 1)
+	db := &tracedDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db,
+		handler.WithTracer(tracer),
+		handler.WithBatchLimits(handler.BatchLimits{SaveBatchSize: 1}),
+	)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(tracer.UnaryServerInterceptor()))
+	pb.RegisterDataProcessorServiceServer(server, service)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tracing.TraceparentHeader, "00-"+traceID+"-00f067aa0ba902b7-01")
+	if _, err := pb.NewDataProcessorServiceClient(conn).ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"}); err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+
+	all := spans(t, out)
+	byID := map[string]exportedSpan{}
+	for _, span := range all {
+		if span.TraceID != traceID {
+			t.Errorf("Expected span %s to continue the incoming trace, got %s", span.Name, span.TraceID)
+		}
+		byID[span.SpanID] = span
+	}
+
+	rpc := spanNamed(all, processCSVDataSpan)
+	if len(rpc) != 1 || rpc[0].Kind != "server" || rpc[0].ParentSpanID != "00f067aa0ba902b7" || rpc[0].Attributes["rpc.method"] != "ProcessCSVData" {
+		t.Fatalf("Unexpected server span: %+v", rpc)
+	}
+	imports := spanNamed(all, "etc.import")
+	if len(imports) != 1 || imports[0].ParentSpanID != rpc[0].SpanID || imports[0].Attributes["etc.account_id"] != "acct-1" || imports[0].Attributes["etc.records.saved"] != float64(2) {
+		t.Fatalf("Unexpected import span: %+v", imports)
+	}
+	for name, count := range map[string]int{"etc.parse": 1, "etc.dedup": 1, "etc.validate": 2, "etc.convert": 2, "etc.db.save": 2} {
+		found := spanNamed(all, name)
+		if len(found) != count {
+			t.Errorf("Expected %d %s spans, got %d", count, name, len(found))
+		}
+		for _, span := range found {
+			if span.ParentSpanID != imports[0].SpanID {
+				t.Errorf("Expected %s to be a child of the import span, got parent %s", name, span.ParentSpanID)
+			}
+		}
+	}
+
+	// Each save carries the trace context of its DB batch
+	if len(db.traceparents) != 2 {
+		t.Fatalf("Expected 2 traced saves, got %v", db.traceparents)
+	}
+	for _, value := range db.traceparents {
+		sc, ok := tracing.ParseTraceparent(value)
+		if !ok || byID[sc.SpanID.String()].Name != "etc.db.save" {
+			t.Errorf("Expected the DB client to get a db.save span's traceparent, got %q", value)
+		}
+	}
+}
+
+func TestTracing_SamplingAndClientPropagation(t *testing.T) {
+	out := &logBuffer{}
+	tracer := tracing.NewTracer("etc_data_processor", tracing.NewWriterExporter(out), 1)
+	db := &tracedDBClient{}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(tracer.UnaryServerInterceptor()))
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(db, handler.WithTracer(tracer)))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	// A client span is the parent of the server span
+	conn, err := grpc.NewClient(lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tracer.UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewDataProcessorServiceClient(conn)
+	if _, err := client.ProcessCSVData(context.Background(), &pb.ProcessCSVDataRequest{CsvData: "x"}); err == nil {
+		t.Fatal("Expected invalid CSV to fail")
+	}
+
+	all := spans(t, out)
+	var clientSpan, serverSpan exportedSpan
+	for _, span := range all {
+		if span.Name == processCSVDataSpan {
+			if span.Kind == "client" {
+				clientSpan = span
+			} else {
+				serverSpan = span
+			}
+		}
+	}
+	if clientSpan.SpanID == "" || serverSpan.ParentSpanID != clientSpan.SpanID || serverSpan.TraceID != clientSpan.TraceID {
+		t.Fatalf("Expected the server span to continue the client span, got client %+v server %+v", clientSpan, serverSpan)
+	}
+	if clientSpan.Status["code"] != "error" || serverSpan.Status["code"] != "unset" {
+		t.Errorf("Expected InvalidArgument to fail only the client span, got %v and %v", clientSpan.Status, serverSpan.Status)
+	}
+
+	// An unsampled caller is followed: nothing is exported, but the trace
+	// context still reaches the DB client
+	out.mu.Lock()
+	out.buf.Reset()
+	out.mu.Unlock()
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if _, err := pb.NewDataProcessorServiceClient(mustDial(t, lis.Addr().String())).ProcessCSVData(ctx, &pb.ProcessCSVDataRequest{CsvData: septCSV, AccountId: "acct-1"}); err != nil {
+		t.Fatalf("ProcessCSVData() error = %v", err)
+	}
+	if got := spans(t, out); len(got) != 0 {
+		t.Errorf("Expected no spans for an unsampled trace, got %d", len(got))
+	}
+	if len(db.traceparents) != 2 || !strings.HasPrefix(db.traceparents[0], "00-4bf92f3577b34da6a3ce929d0e0e4736-") || !strings.HasSuffix(db.traceparents[0], "-00") {
+		t.Errorf("Expected the unsampled trace to be propagated, got %v", db.traceparents)
+	}
+
+	// A ratio near zero exports no new traces; a nil tracer is a no-op
+	rare := tracing.NewTracer("etc_data_processor", tracing.NewWriterExporter(out), 1e-12)
+	_, span := rare.Start(context.Background(), "rare")
+	span.End()
+	if got := spans(t, out); len(got) != 0 {
+		t.Errorf("Expected the sampler to drop the span, got %+v", got)
+	}
+	var none *tracing.Tracer
+	if _, span := none.Start(context.Background(), "none"); span != nil {
+		t.Error("Expected a nil tracer to start no span")
+	}
+}
+
+func TestTracing_GatewayAndFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.jsonl")
+	exporter, err := tracing.NewFileExporter(path)
+	if err != nil {
+		t.Fatalf("NewFileExporter() error = %v", err)
+	}
+	tracer := tracing.NewTracer("etc_data_processor", exporter, 1)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(tracer.UnaryServerInterceptor()))
+	pb.RegisterDataProcessorServiceServer(server, handler.NewDataProcessorServiceWithOptions(&mockDBClient{}, handler.WithTracer(tracer)))
+	go server.Serve(lis)
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gw, err := gateway.New(ctx, gateway.Options{Mode: gateway.ModeProxy, Endpoint: lis.Addr().String(), Tracer: tracer})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go gw.Serve(httpLis)
+	defer gw.Shutdown(context.Background())
+
+	body := `{"csv_data": "` + strings.ReplaceAll(septCSV, "\n", `\n`) + `", "account_id": "acct-http"}`
+	req, _ := http.NewRequest(http.MethodPost, "http://"+httpLis.Addr().String()+"/v1/process/data", strings.NewReader(body))
+	req.Header.Set("traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Spans with an error mentioning a card number are redacted on export
+	_, span := tracer.Start(context.Background(), "redacted")
+	span.RecordError(errors.New("duplicate key for 1234567812345678"))
+	span.End()
+	if err := exporter.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &logBuffer{}
+	out.Write(data)
+	all := spans(t, out)
+
+	httpSpans := spanNamed(all, "POST /v1/process/data")
+	rpc := spanNamed(all, processCSVDataSpan)
+	if len(httpSpans) != 1 || httpSpans[0].ParentSpanID != "b7ad6b7169203331" || httpSpans[0].Attributes["http.response.status_code"] != float64(200) {
+		t.Fatalf("Unexpected HTTP span: %+v", httpSpans)
+	}
+	if len(rpc) != 1 || rpc[0].ParentSpanID != httpSpans[0].SpanID || rpc[0].TraceID != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("Expected the gRPC span to continue the HTTP span, got %+v", rpc)
+	}
+	redacted := spanNamed(all, "redacted")
+	if len(redacted) != 1 || strings.Contains(redacted[0].Status["message"], "1234567812345678") || !strings.Contains(redacted[0].Status["message"], "[REDACTED]") {
+		t.Errorf("Expected the status message to be redacted, got %+v", redacted)
+	}
+}
+
+// mustDial opens a plain client connection closed with the test
+func mustDial(t *testing.T, addr string) *grpc.ClientConn {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
\ No newline at end of file
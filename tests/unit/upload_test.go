@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/gateway"
+	"github.com/yhonda-ohishi/etc_data_processor/src/pkg/handler"
+)
+
+// formPart is one multipart field; parts with a fileName are file parts
+type formPart struct {
+	name     string
+	fileName string
+	value    string
+}
+
+func multipartBody(t *testing.T, parts ...formPart) (*bytes.Buffer, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for _, p := range parts {
+		var err error
+		if p.fileName != "" {
+			w, createErr := mw.CreateFormFile(p.name, p.fileName)
+			if createErr != nil {
+				t.Fatal(createErr)
+			}
+			_, err = w.Write([]byte(p.value))
+		} else {
+			err = mw.WriteField(p.name, p.value)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf, mw.FormDataContentType()
+}
+
+// uploadServer starts a gateway with uploads spooled to a temporary directory
+func uploadServer(t *testing.T, maxBytes int64) (*httptest.Server, *concurrentDBClient, string) {
+	t.Helper()
+	db := &concurrentDBClient{}
+	service := handler.NewDataProcessorServiceWithOptions(db)
+	dir := t.TempDir()
+
+	gw, err := gateway.New(context.Background(), gateway.Options{
+		Server:         service,
+		Uploader:       service,
+		MaxUploadBytes: maxBytes,
+		UploadDir:      dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gw.Handler())
+	t.Cleanup(srv.Close)
+	return srv, db, dir
+}
+
+func postUpload(t *testing.T, url string, parts ...formPart) (int, map[string]interface{}) {
+	t.Helper()
+	body, contentType := multipartBody(t, parts...)
+	resp, err := http.Post(url+"/v1/upload", contentType, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestUpload_ShiftJISFile(t *testing.T) {
+	srv, db, dir := uploadServer(t, 0)
+
+	// The file part comes before the other fields, as with curl -F file=@... first
+	code, resp := postUpload(t, srv.URL,
+		formPart{name: "file", fileName: "明細.csv", value: shiftJIS(t, retryTestCSV)},
+		formPart{name: "account_id", value: "test-account"},
+		formPart{name: "skip_duplicates", value: "true"},
+	)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", code, resp)
+	}
+
+	stats, _ := resp["stats"].(map[string]interface{})
+	if resp["success"] != true || stats["savedRecords"] != float64(2) || db.saved != 2 {
+		t.Errorf("Expected both records saved, got %v", resp)
+	}
+	if resp["importId"] == "" {
+		t.Error("Expected an import ID")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected spooled upload to be removed, found %d files", len(entries))
+	}
+}
+
+func TestUpload_Zip(t *testing.T) {
+	srv, db, _ := uploadServer(t, 0)
+	data := buildZip(t, []zipEntry{{name: "2025年09月.csv", data: shiftJIS(t, retryTestCSV), cp932: true}})
+
+	code, resp := postUpload(t, srv.URL,
+		formPart{name: "account_id", value: "test-account"},
+		formPart{name: "file", fileName: "statements.ZIP", value: string(data)},
+	)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %v", code, resp)
+	}
+	entries, _ := resp["entries"].([]interface{})
+	if len(entries) != 1 || db.saved != 2 {
+		t.Errorf("Expected one archive entry imported, got %v", resp)
+	}
+}
+
+func TestUpload_Errors(t *testing.T) {
+	srv, db, _ := uploadServer(t, 1024)
+
+	tests := []struct {
+		name  string
+		parts []formPart
+		code  int
+	}{
+		{"no file", []formPart{{name: "account_id", value: "test-account"}}, http.StatusBadRequest},
+		{"no account", []formPart{{name: "file", fileName: "a.csv", value: "x"}}, http.StatusBadRequest},
+		{"wrong extension", []formPart{{name: "account_id", value: "test-account"}, {name: "file", fileName: "a.xlsx", value: "x"}}, http.StatusBadRequest},
+		{"bad flag", []formPart{{name: "skip_duplicates", value: "maybe"}, {name: "file", fileName: "a.csv", value: "x"}}, http.StatusBadRequest},
+		{"too large", []formPart{{name: "account_id", value: "test-account"}, {name: "file", fileName: "a.csv", value: strings.Repeat("x", 2048)}}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := postUpload(t, srv.URL, tt.parts...)
+			if code != tt.code {
+				t.Errorf("Expected %d, got %d: %v", tt.code, code, resp)
+			}
+			if resp["message"] == nil {
+				t.Errorf("Expected a JSON error body, got %v", resp)
+			}
+		})
+	}
+	if db.saved != 0 {
+		t.Errorf("Expected nothing saved, got %d", db.saved)
+	}
+
+	resp, err := http.Post(srv.URL+"/v1/upload", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for non-multipart body, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(srv.URL + "/v1/upload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
\ No newline at end of file